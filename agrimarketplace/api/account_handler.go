@@ -0,0 +1,286 @@
+package api
+
+import (
+	"agrimarketplace/auth"
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// AccountHandler handles HTTP requests related to registration and account verification.
+type AccountHandler struct {
+	accountService service.AccountService
+	otpService     service.OTPService
+	sessionService service.SessionService
+}
+
+// NewAccountHandler creates a new instance of AccountHandler.
+func NewAccountHandler(accountService service.AccountService, otpService service.OTPService, sessionService service.SessionService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+		otpService:     otpService,
+		sessionService: sessionService,
+	}
+}
+
+// RegisterHandler handles the registration of a new user, who must verify their email address
+// before placing orders or owning shops.
+func (h *AccountHandler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	var user models.User
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.accountService.Register(&user); err != nil {
+		respondWithAccountError(w, err)
+		return
+	}
+
+	user.Password = ""
+	respondWithJSON(w, user, http.StatusCreated)
+}
+
+// ConfirmEmailHandler handles the verification link sent to a new user.
+func (h *AccountHandler) ConfirmEmailHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := h.accountService.ConfirmEmail(r.URL.Query().Get("token"))
+	if err != nil {
+		respondWithAccountError(w, err)
+		return
+	}
+
+	user.Password = ""
+	respondWithJSON(w, user, http.StatusOK)
+}
+
+// resendVerificationRequest is the body of a request for a new verification link.
+type resendVerificationRequest struct {
+	Username string
+}
+
+// ResendVerificationHandler handles a request for a new verification link.
+func (h *AccountHandler) ResendVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	var request resendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.accountService.ResendVerification(request.Username); err != nil {
+		respondWithAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte("Verification email sent"))
+}
+
+// signInRequest is the body of a sign-in with a password.
+// Login is a username, email address or phone number.
+type signInRequest struct {
+	Login    string
+	Password string
+	Device   models.DeviceInfo
+}
+
+// SignInHandler handles a sign-in with a password, starting a session for the device.
+func (h *AccountHandler) SignInHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request signInRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	login, err := h.accountService.SignIn(request.Login, request.Password, requestDevice(r, request.Device))
+	if err != nil {
+		respondWithAccountError(w, err)
+		return
+	}
+
+	respondWithJSON(w, login, http.StatusOK)
+}
+
+// changePasswordRequest is the body of a password change.
+type changePasswordRequest struct {
+	CurrentPassword string
+	NewPassword     string
+}
+
+// ChangePasswordHandler handles a password change by a user who knows their current password.
+func (h *AccountHandler) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeUser(w, r, h.sessionService, vars["id"]); !ok {
+		return
+	}
+
+	var request changePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.accountService.ChangePassword(vars["id"], request.CurrentPassword, request.NewPassword); err != nil {
+		respondWithAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Password changed"))
+}
+
+// forgotPasswordRequest is the body of a password reset request.
+// Login is a username, email address or phone number; Channel is "email", "sms" or empty.
+type forgotPasswordRequest struct {
+	Login   string
+	Channel string
+}
+
+// ForgotPasswordHandler handles a request for a password reset link.
+// It answers the same way whether or not the account exists.
+func (h *AccountHandler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var request forgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(request.Login, request.Channel); err != nil {
+		respondWithAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte("If the account exists, a password reset link has been sent"))
+}
+
+// resetPasswordRequest is the body of a password reset.
+type resetPasswordRequest struct {
+	Token       string
+	NewPassword string
+}
+
+// ResetPasswordHandler handles a new password set from a password reset link.
+func (h *AccountHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var request resetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.accountService.ResetPassword(request.Token, request.NewPassword); err != nil {
+		respondWithAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Password reset"))
+}
+
+// requestOTPRequest is the body of a request for a one-time password.
+type requestOTPRequest struct {
+	Phone string
+}
+
+// RequestOTPHandler handles a request for a one-time password sent by SMS, to sign in or register.
+func (h *AccountHandler) RequestOTPHandler(w http.ResponseWriter, r *http.Request) {
+	var request requestOTPRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.otpService.RequestOTP(request.Phone); err != nil {
+		respondWithAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte("Code sent"))
+}
+
+// verifyOTPRequest is the body of a sign-in with a one-time password.
+// The profile is only used when the phone number does not belong to a user yet.
+type verifyOTPRequest struct {
+	Phone   string
+	Code    string
+	Profile *models.User
+	Device  models.DeviceInfo
+}
+
+// VerifyOTPHandler handles a sign-in with a one-time password, registering the user if needed.
+func (h *AccountHandler) VerifyOTPHandler(w http.ResponseWriter, r *http.Request) {
+	var request verifyOTPRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	login, err := h.otpService.VerifyOTP(request.Phone, request.Code, request.Profile, requestDevice(r, request.Device))
+	if err != nil {
+		respondWithAccountError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if login.Registered {
+		status = http.StatusCreated
+	}
+	respondWithJSON(w, login, status)
+}
+
+// bearerToken returns the access token of a request's Authorization header, or an empty string.
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > len("Bearer ") && strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(header[len("Bearer "):])
+	}
+	return ""
+}
+
+// respondWithAccountError maps account service errors to HTTP responses.
+func respondWithAccountError(w http.ResponseWriter, err error) {
+	if respondWithValidationError(w, err) {
+		return
+	}
+
+	var conflict *service.ConflictError
+	switch {
+	case errors.As(err, &conflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, auth.ErrWeakPassword), errors.Is(err, service.ErrInvalidCrop),
+		errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrTokenExpired), errors.Is(err, service.ErrInvalidPhone),
+		errors.Is(err, service.ErrInvalidResetChannel), errors.Is(err, service.ErrInvalidResetToken), errors.Is(err, service.ErrResetTokenExpired):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrInvalidOTP), errors.Is(err, service.ErrOTPExpired), errors.Is(err, service.ErrIncorrectPassword),
+		errors.Is(err, service.ErrInvalidCredentials):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, service.ErrUserNotVerified):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrAccountClosed):
+		http.Error(w, err.Error(), http.StatusGone)
+	case errors.Is(err, service.ErrOTPAttemptsExceeded), errors.Is(err, service.ErrOTPThrottled), errors.Is(err, service.ErrTooManyAttempts):
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, service.ErrUserAlreadyVerified):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Error processing account", http.StatusInternalServerError)
+	}
+}
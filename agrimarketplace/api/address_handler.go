@@ -0,0 +1,177 @@
+package api
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AddressHandler handles HTTP requests related to users' address books.
+// Users may manage their own addresses, and admins those of any user.
+type AddressHandler struct {
+	addressService service.AddressService
+	sessionService service.SessionService
+}
+
+// NewAddressHandler creates a new instance of AddressHandler.
+func NewAddressHandler(addressService service.AddressService, sessionService service.SessionService) *AddressHandler {
+	return &AddressHandler{
+		addressService: addressService,
+		sessionService: sessionService,
+	}
+}
+
+// AddAddressHandler handles the addition of an address to a user's address book.
+func (h *AddressHandler) AddAddressHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeUser(w, r, h.sessionService, vars["id"]); !ok {
+		return
+	}
+
+	var address models.Address
+	if err := json.NewDecoder(r.Body).Decode(&address); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.addressService.AddAddress(vars["id"], &address); err != nil {
+		respondWithAddressError(w, err)
+		return
+	}
+
+	respondWithJSON(w, address, http.StatusCreated)
+}
+
+// GetUserAddressesHandler handles the retrieval of a user's address book.
+func (h *AddressHandler) GetUserAddressesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeUser(w, r, h.sessionService, vars["id"]); !ok {
+		return
+	}
+
+	addresses, err := h.addressService.GetUserAddresses(vars["id"])
+	if err != nil {
+		respondWithAddressError(w, err)
+		return
+	}
+
+	respondWithJSON(w, addresses, http.StatusOK)
+}
+
+// GetAddressByIDHandler handles the retrieval of an address by its ID.
+func (h *AddressHandler) GetAddressByIDHandler(w http.ResponseWriter, r *http.Request) {
+	address, ok := h.authorizeAddress(w, r)
+	if !ok {
+		return
+	}
+
+	respondWithJSON(w, address, http.StatusOK)
+}
+
+// UpdateAddressHandler handles the update of an address.
+func (h *AddressHandler) UpdateAddressHandler(w http.ResponseWriter, r *http.Request) {
+	existingAddress, ok := h.authorizeAddress(w, r)
+	if !ok {
+		return
+	}
+
+	var address models.Address
+	if err := json.NewDecoder(r.Body).Decode(&address); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	address.ID = existingAddress.ID
+
+	if err := h.addressService.UpdateAddress(&address); err != nil {
+		respondWithAddressError(w, err)
+		return
+	}
+
+	respondWithJSON(w, address, http.StatusOK)
+}
+
+// SetDefaultAddressHandler handles making an address the default one of its user.
+func (h *AddressHandler) SetDefaultAddressHandler(w http.ResponseWriter, r *http.Request) {
+	existingAddress, ok := h.authorizeAddress(w, r)
+	if !ok {
+		return
+	}
+
+	address, err := h.addressService.SetDefaultAddress(existingAddress.ID.Hex())
+	if err != nil {
+		respondWithAddressError(w, err)
+		return
+	}
+
+	respondWithJSON(w, address, http.StatusOK)
+}
+
+// DeleteAddressHandler handles the deletion of an address.
+func (h *AddressHandler) DeleteAddressHandler(w http.ResponseWriter, r *http.Request) {
+	address, ok := h.authorizeAddress(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.addressService.DeleteAddress(address.ID.Hex()); err != nil {
+		respondWithAddressError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Address deleted successfully"))
+}
+
+// authorizeAddress retrieves the address with the ID in the request path, checking that the request
+// is made by its user or by an admin, and responds with an error otherwise.
+func (h *AddressHandler) authorizeAddress(w http.ResponseWriter, r *http.Request) (*models.Address, bool) {
+	vars := mux.Vars(r)
+
+	if _, err := primitive.ObjectIDFromHex(vars["id"]); err != nil {
+		http.Error(w, "Invalid address ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	requester, ok := authenticate(w, r, h.sessionService)
+	if !ok {
+		return nil, false
+	}
+
+	address, err := h.addressService.GetAddressByID(vars["id"])
+	if err != nil {
+		respondWithAddressError(w, err)
+		return nil, false
+	}
+	if !actsFor(requester, address.UserID.Hex()) {
+		respondWithSessionError(w, service.ErrNotAuthorized)
+		return nil, false
+	}
+
+	return address, true
+}
+
+// respondWithAddressError maps address service errors to HTTP responses.
+func respondWithAddressError(w http.ResponseWriter, err error) {
+	if respondWithValidationError(w, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrAddressNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrTooManyAddresses):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrAccountClosed):
+		http.Error(w, err.Error(), http.StatusGone)
+	default:
+		http.Error(w, "Error processing addresses", http.StatusInternalServerError)
+	}
+}
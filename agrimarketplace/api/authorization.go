@@ -0,0 +1,75 @@
+package api
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"net/http"
+)
+
+// authenticate returns the user the request's access token was issued to,
+// and responds with an error when the request is not signed in.
+func authenticate(w http.ResponseWriter, r *http.Request, sessionService service.SessionService) (*models.User, bool) {
+	requester, err := sessionService.Authenticate(bearerToken(r))
+	if err != nil {
+		respondWithSessionError(w, err)
+		return nil, false
+	}
+	return requester, true
+}
+
+// authorizeAdmin checks that the request is made by an admin, and responds with an error otherwise.
+func authorizeAdmin(w http.ResponseWriter, r *http.Request, sessionService service.SessionService) (*models.User, bool) {
+	requester, ok := authenticate(w, r, sessionService)
+	if !ok {
+		return nil, false
+	}
+	if requester.Role != models.RoleAdmin {
+		respondWithSessionError(w, service.ErrNotAuthorized)
+		return nil, false
+	}
+	return requester, true
+}
+
+// authorizeUser checks that the request is made by the user with the given ID or by an admin,
+// and responds with an error otherwise.
+func authorizeUser(w http.ResponseWriter, r *http.Request, sessionService service.SessionService, userID string) (*models.User, bool) {
+	requester, ok := authenticate(w, r, sessionService)
+	if !ok {
+		return nil, false
+	}
+	if !actsFor(requester, userID) {
+		respondWithSessionError(w, service.ErrNotAuthorized)
+		return nil, false
+	}
+	return requester, true
+}
+
+// authorizeShopOwner checks that the request is made by the owner of the shop with the given ID
+// or by an admin, and responds with an error otherwise.
+func authorizeShopOwner(w http.ResponseWriter, r *http.Request, sessionService service.SessionService, shopService service.ShopService, shopID string) (*models.User, bool) {
+	requester, ok := authenticate(w, r, sessionService)
+	if !ok {
+		return nil, false
+	}
+
+	shop, err := shopService.FindShopByID(shopID)
+	if err != nil {
+		http.Error(w, "Error retrieving shop", http.StatusInternalServerError)
+		return nil, false
+	}
+	if shop == nil {
+		http.Error(w, "Shop not found", http.StatusNotFound)
+		return nil, false
+	}
+	if !actsFor(requester, shop.OwnerID.Hex()) {
+		respondWithSessionError(w, service.ErrNotAuthorized)
+		return nil, false
+	}
+	return requester, true
+}
+
+// actsFor reports whether a user may act on the records of the user with the given ID,
+// which users may do for themselves and admins for anyone.
+func actsFor(requester *models.User, userID string) bool {
+	return requester.ID.Hex() == userID || requester.Role == models.RoleAdmin
+}
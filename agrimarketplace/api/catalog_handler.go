@@ -0,0 +1,97 @@
+package api
+
+import (
+	"agrimarketplace/service"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// CatalogHandler handles HTTP requests for bulk catalog import and export.
+type CatalogHandler struct {
+	catalogService service.CatalogService
+	sessionService service.SessionService
+	maxImportBytes int64
+}
+
+// NewCatalogHandler creates a new instance of CatalogHandler.
+func NewCatalogHandler(catalogService service.CatalogService, sessionService service.SessionService, maxImportBytes int64) *CatalogHandler {
+	return &CatalogHandler{
+		catalogService: catalogService,
+		sessionService: sessionService,
+		maxImportBytes: maxImportBytes,
+	}
+}
+
+// ImportCatalogHandler handles the upsert of products from a CSV or NDJSON body, which only admins may do.
+// With dry_run=true the body is only validated and the report describes what would change.
+func (h *CatalogHandler) ImportCatalogHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	format := catalogFormat(r)
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "Invalid dry_run", http.StatusBadRequest)
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(w, r.Body, h.maxImportBytes)
+	report, err := h.catalogService.ImportCatalog(body, format, dryRun)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			http.Error(w, "Catalog file too large", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, service.ErrUnsupportedCatalogFormat), errors.Is(err, service.ErrInvalidCatalogFile):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Error importing catalog", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if report.Failed > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	respondWithJSON(w, report, status)
+}
+
+// ExportCatalogHandler handles the download of the full catalog as CSV or NDJSON.
+func (h *CatalogHandler) ExportCatalogHandler(w http.ResponseWriter, r *http.Request) {
+	format := catalogFormat(r)
+	switch format {
+	case service.CatalogFormatCSV:
+		w.Header().Set("Content-Type", "text/csv")
+	case service.CatalogFormatNDJSON:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	default:
+		http.Error(w, service.ErrUnsupportedCatalogFormat.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="catalog.`+format+`"`)
+
+	// The export is streamed, so an error after the first row can only be logged
+	if err := h.catalogService.ExportCatalog(w, format); err != nil {
+		log.Printf("Error exporting catalog: %v", err)
+	}
+}
+
+// catalogFormat returns the format requested by the format parameter, defaulting to CSV.
+func catalogFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+	return service.CatalogFormatCSV
+}
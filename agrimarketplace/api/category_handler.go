@@ -0,0 +1,119 @@
+package api
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"agrimarketplace/validation"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CategoryHandler handles HTTP requests related to product categories.
+// Attribute schemas decide how every product of a category is validated, so only admins may change them.
+type CategoryHandler struct {
+	categoryService service.CategoryService
+	sessionService  service.SessionService
+}
+
+// NewCategoryHandler creates a new instance of CategoryHandler.
+func NewCategoryHandler(categoryService service.CategoryService, sessionService service.SessionService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+		sessionService:  sessionService,
+	}
+}
+
+// GetCategoriesHandler handles the retrieval of every category and its attribute schema.
+func (h *CategoryHandler) GetCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.categoryService.GetCategories()
+	if err != nil {
+		http.Error(w, "Error fetching categories", http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJSON(w, categories, http.StatusOK)
+}
+
+// GetCategoryByIDHandler handles the retrieval of a category by ID.
+func (h *CategoryHandler) GetCategoryByIDHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	categoryID := vars["id"]
+
+	category, err := h.categoryService.GetCategoryByID(categoryID)
+	if err != nil {
+		http.Error(w, "Error fetching category", http.StatusInternalServerError)
+		return
+	}
+
+	if category == nil {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	}
+
+	respondWithJSON(w, category, http.StatusOK)
+}
+
+// CreateCategoryHandler handles the creation of a new category.
+func (h *CategoryHandler) CreateCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	var category models.Category
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.categoryService.CreateCategory(&category); err != nil {
+		respondWithCategoryError(w, err)
+		return
+	}
+
+	respondWithJSON(w, category, http.StatusCreated)
+}
+
+// UpdateCategoryHandler handles the update of a category and its attribute schema.
+func (h *CategoryHandler) UpdateCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	var category models.Category
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	category.ID, _ = primitive.ObjectIDFromHex(vars["id"])
+
+	if err := h.categoryService.UpdateCategory(&category); err != nil {
+		respondWithCategoryError(w, err)
+		return
+	}
+
+	respondWithJSON(w, category, http.StatusOK)
+}
+
+// respondWithCategoryError maps category service errors to HTTP responses.
+func respondWithCategoryError(w http.ResponseWriter, err error) {
+	switch {
+	case respondWithValidationError(w, err):
+	case errors.Is(err, service.ErrCategoryNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrCategoryAlreadyExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidLicenceType):
+		respondWithValidationError(w, &validation.Error{Fields: map[string]string{"required_licence": "is not a known licence type"}})
+	case errors.Is(err, service.ErrInvalidCategory):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Error saving category", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CropHandler handles HTTP requests related to farmers' crops and crop-based recommendations.
+// Farmers may manage their own crops, and admins those of any farmer and the crop rules.
+type CropHandler struct {
+	cropService    service.CropService
+	sessionService service.SessionService
+}
+
+// NewCropHandler creates a new instance of CropHandler.
+func NewCropHandler(cropService service.CropService, sessionService service.SessionService) *CropHandler {
+	return &CropHandler{
+		cropService:    cropService,
+		sessionService: sessionService,
+	}
+}
+
+// SetUserCropsHandler handles the replacement of the crops grown by a user.
+func (h *CropHandler) SetUserCropsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeUser(w, r, h.sessionService, vars["id"]); !ok {
+		return
+	}
+
+	var crops []models.CropPlanting
+	if err := json.NewDecoder(r.Body).Decode(&crops); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.cropService.SetUserCrops(vars["id"], crops)
+	if err != nil {
+		respondWithCropError(w, err)
+		return
+	}
+
+	respondWithJSON(w, user, http.StatusOK)
+}
+
+// GetRecommendationsHandler handles the retrieval of the products recommended for a user's crops,
+// from shops near the user, in the order of the crops' growth stages.
+func (h *CropHandler) GetRecommendationsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	params := r.URL.Query()
+
+	if _, ok := authorizeUser(w, r, h.sessionService, vars["id"]); !ok {
+		return
+	}
+
+	radius := 0.0
+	if radiusStr := params.Get("radius"); radiusStr != "" {
+		var err error
+		radius, err = strconv.ParseFloat(radiusStr, 64)
+		if err != nil || radius < 0 {
+			http.Error(w, "Invalid radius", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := 0
+	if limitStr := params.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	recommendations, err := h.cropService.RecommendProducts(vars["id"], radius, limit)
+	if err != nil {
+		respondWithCropError(w, err)
+		return
+	}
+
+	respondWithJSON(w, recommendations, http.StatusOK)
+}
+
+// AddCropRuleHandler handles the addition of a rule mapping a crop's growth stage to products.
+func (h *CropHandler) AddCropRuleHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	var rule models.CropRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.cropService.AddCropRule(&rule); err != nil {
+		respondWithCropError(w, err)
+		return
+	}
+
+	respondWithJSON(w, rule, http.StatusCreated)
+}
+
+// GetCropRulesHandler handles the retrieval of every crop rule.
+func (h *CropHandler) GetCropRulesHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	rules, err := h.cropService.GetCropRules()
+	if err != nil {
+		respondWithCropError(w, err)
+		return
+	}
+
+	respondWithJSON(w, rules, http.StatusOK)
+}
+
+// DeleteCropRuleHandler handles the deletion of a crop rule.
+func (h *CropHandler) DeleteCropRuleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	if err := h.cropService.DeleteCropRule(vars["id"]); err != nil {
+		respondWithCropError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Crop rule deleted successfully"))
+}
+
+// respondWithCropError maps crop service errors to HTTP responses.
+func respondWithCropError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrCropRuleNotFound), errors.Is(err, service.ErrCategoryNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidCrop), errors.Is(err, service.ErrInvalidCropRule):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrUserLocationRequired):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, "Error processing crops", http.StatusInternalServerError)
+	}
+}
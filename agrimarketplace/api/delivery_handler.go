@@ -0,0 +1,103 @@
+package api
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeliveryQuoteHandler handles the estimation of delivery cost and arrival time from a shop.
+func (h *ShopHandler) DeliveryQuoteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shopID := vars["id"]
+
+	// Extract latitude, longitude, and order amount from request query parameters
+	latitude, err := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	if err != nil {
+		http.Error(w, "Invalid latitude", http.StatusBadRequest)
+		return
+	}
+
+	longitude, err := strconv.ParseFloat(r.URL.Query().Get("lon"), 64)
+	if err != nil {
+		http.Error(w, "Invalid longitude", http.StatusBadRequest)
+		return
+	}
+
+	amount := 0.0
+	if amountStr := r.URL.Query().Get("amount"); amountStr != "" {
+		amount, err = strconv.ParseFloat(amountStr, 64)
+		if err != nil {
+			http.Error(w, "Invalid amount", http.StatusBadRequest)
+			return
+		}
+	}
+
+	quote, err := h.DeliveryService.QuoteDelivery(shopID, latitude, longitude, amount)
+	if err != nil {
+		if errors.Is(err, service.ErrShopNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJSON(w, quote, http.StatusOK)
+}
+
+// GetDeliveryFeesHandler handles the retrieval of a shop's delivery fee schedule.
+func (h *ShopHandler) GetDeliveryFeesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shopID := vars["id"]
+
+	schedule, err := h.DeliveryService.GetFeeSchedule(shopID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJSON(w, schedule, http.StatusOK)
+}
+
+// SetDeliveryFeesHandler handles the configuration of a shop's delivery fee schedule by its owner or an admin.
+func (h *ShopHandler) SetDeliveryFeesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeShopOwner(w, r, h.SessionService, h.ShopService, vars["id"]); !ok {
+		return
+	}
+
+	var schedule models.DeliveryFeeSchedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	shopID, err := primitive.ObjectIDFromHex(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid shop ID", http.StatusBadRequest)
+		return
+	}
+	schedule.ShopID = shopID
+
+	if err := h.DeliveryService.SetFeeSchedule(&schedule); err != nil {
+		switch {
+		case errors.Is(err, service.ErrShopNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidFeeSchedule):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	respondWithJSON(w, schedule, http.StatusOK)
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultNearExpiryDays is the period covered by the near-expiry report when none is requested.
+const defaultNearExpiryDays = 30
+
+// InventoryHandler handles HTTP requests related to the stock lots held by shops.
+// Stock is received and sold by the shop's owner or an admin.
+type InventoryHandler struct {
+	inventoryService service.InventoryService
+	shopService      service.ShopService
+	sessionService   service.SessionService
+}
+
+// NewInventoryHandler creates a new instance of InventoryHandler.
+func NewInventoryHandler(inventoryService service.InventoryService, shopService service.ShopService, sessionService service.SessionService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+		shopService:      shopService,
+		sessionService:   sessionService,
+	}
+}
+
+// ReceiveLotHandler handles the receipt of a lot of a product by a shop.
+func (h *InventoryHandler) ReceiveLotHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeShopOwner(w, r, h.sessionService, h.shopService, vars["id"]); !ok {
+		return
+	}
+
+	var lot models.StockLot
+	if err := json.NewDecoder(r.Body).Decode(&lot); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.inventoryService.ReceiveLot(vars["id"], vars["pid"], &lot); err != nil {
+		respondWithInventoryError(w, err)
+		return
+	}
+
+	respondWithJSON(w, lot, http.StatusCreated)
+}
+
+// GetLotsHandler handles the retrieval of the lots of a product held by a shop.
+func (h *InventoryHandler) GetLotsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	lots, err := h.inventoryService.GetLots(vars["id"], vars["pid"])
+	if err != nil {
+		respondWithInventoryError(w, err)
+		return
+	}
+
+	respondWithJSON(w, lots, http.StatusOK)
+}
+
+// recordSaleRequest is the body of a sale recorded by a shop.
+type recordSaleRequest struct {
+	Quantity int
+}
+
+// RecordSaleHandler handles a sale made by a shop, returning the lots it was taken from.
+func (h *InventoryHandler) RecordSaleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeShopOwner(w, r, h.sessionService, h.shopService, vars["id"]); !ok {
+		return
+	}
+
+	var request recordSaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	allocations, err := h.inventoryService.RecordSale(vars["id"], vars["pid"], request.Quantity)
+	if err != nil {
+		respondWithInventoryError(w, err)
+		return
+	}
+
+	respondWithJSON(w, allocations, http.StatusOK)
+}
+
+// FindNearExpiryLotsHandler handles the report of a shop's lots expiring within the next days.
+func (h *InventoryHandler) FindNearExpiryLotsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	days := defaultNearExpiryDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		var err error
+		days, err = strconv.Atoi(daysStr)
+		if err != nil || days < 0 {
+			http.Error(w, "Invalid days", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.inventoryService.FindNearExpiryLots(vars["id"], time.Duration(days)*24*time.Hour)
+	if err != nil {
+		respondWithInventoryError(w, err)
+		return
+	}
+
+	respondWithJSON(w, report, http.StatusOK)
+}
+
+// respondWithInventoryError maps inventory service errors to HTTP responses.
+func respondWithInventoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrShopNotFound), errors.Is(err, service.ErrProductNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidLot), errors.Is(err, service.ErrLotExpired), errors.Is(err, service.ErrInvalidStockQuantity):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrLicenceRequired), errors.Is(err, service.ErrLicenceExpired):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrNotServiceable), errors.Is(err, service.ErrInsufficientStock):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Error processing stock", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultExpiryWindowDays is the period covered by the expiring-licences report when none is requested.
+const defaultExpiryWindowDays = 30
+
+// LicenceHandler handles HTTP requests related to shop licences.
+// Licences let shops sell regulated products, so only admins, having checked them, may register or remove them.
+type LicenceHandler struct {
+	licenceService service.LicenceService
+	sessionService service.SessionService
+}
+
+// NewLicenceHandler creates a new instance of LicenceHandler.
+func NewLicenceHandler(licenceService service.LicenceService, sessionService service.SessionService) *LicenceHandler {
+	return &LicenceHandler{
+		licenceService: licenceService,
+		sessionService: sessionService,
+	}
+}
+
+// RegisterLicenceHandler handles the registration of a licence held by a shop.
+func (h *LicenceHandler) RegisterLicenceHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	var licence models.ShopLicence
+	if err := json.NewDecoder(r.Body).Decode(&licence); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.licenceService.RegisterLicence(vars["id"], &licence); err != nil {
+		respondWithLicenceError(w, err)
+		return
+	}
+
+	respondWithJSON(w, licence, http.StatusCreated)
+}
+
+// GetShopLicencesHandler handles the retrieval of a shop's licences.
+func (h *LicenceHandler) GetShopLicencesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	licences, err := h.licenceService.GetShopLicences(vars["id"])
+	if err != nil {
+		respondWithLicenceError(w, err)
+		return
+	}
+
+	respondWithJSON(w, licences, http.StatusOK)
+}
+
+// DeleteLicenceHandler handles the removal of a shop's licence.
+func (h *LicenceHandler) DeleteLicenceHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	if err := h.licenceService.DeleteLicence(vars["id"], vars["lid"]); err != nil {
+		respondWithLicenceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FindExpiringLicencesHandler handles the report of licences expiring within the next days.
+func (h *LicenceHandler) FindExpiringLicencesHandler(w http.ResponseWriter, r *http.Request) {
+	days := defaultExpiryWindowDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		var err error
+		days, err = strconv.Atoi(daysStr)
+		if err != nil || days <= 0 {
+			http.Error(w, "Invalid days", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.licenceService.FindExpiringLicences(time.Duration(days) * 24 * time.Hour)
+	if err != nil {
+		http.Error(w, "Error fetching expiring licences", http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJSON(w, report, http.StatusOK)
+}
+
+// respondWithLicenceError maps licence service errors to HTTP responses.
+func respondWithLicenceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrShopNotFound), errors.Is(err, service.ErrLicenceNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidLicence), errors.Is(err, service.ErrInvalidLicenceType):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Error processing licence", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"agrimarketplace/storage"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// MediaHandler handles HTTP requests related to product and shop images.
+// Shop images are managed by the shop's owner or an admin, and product images, which belong to the
+// shared catalog, by admins.
+type MediaHandler struct {
+	mediaService   service.MediaService
+	shopService    service.ShopService
+	sessionService service.SessionService
+	maxUploadBytes int64
+}
+
+// NewMediaHandler creates a new instance of MediaHandler.
+func NewMediaHandler(mediaService service.MediaService, shopService service.ShopService, sessionService service.SessionService, maxUploadBytes int64) *MediaHandler {
+	return &MediaHandler{
+		mediaService:   mediaService,
+		shopService:    shopService,
+		sessionService: sessionService,
+		maxUploadBytes: maxUploadBytes,
+	}
+}
+
+// UploadProductImageHandler handles multipart image uploads for a product.
+func (h *MediaHandler) UploadProductImageHandler(w http.ResponseWriter, r *http.Request) {
+	h.uploadImage(w, r, models.ImageOwnerProduct)
+}
+
+// UploadShopImageHandler handles multipart image uploads for a shop.
+func (h *MediaHandler) UploadShopImageHandler(w http.ResponseWriter, r *http.Request) {
+	h.uploadImage(w, r, models.ImageOwnerShop)
+}
+
+// GetProductImagesHandler handles the listing of a product's images.
+func (h *MediaHandler) GetProductImagesHandler(w http.ResponseWriter, r *http.Request) {
+	h.getImages(w, r, models.ImageOwnerProduct)
+}
+
+// GetShopImagesHandler handles the listing of a shop's images.
+func (h *MediaHandler) GetShopImagesHandler(w http.ResponseWriter, r *http.Request) {
+	h.getImages(w, r, models.ImageOwnerShop)
+}
+
+// ServeImageHandler serves an image, or its thumbnail when the thumb query parameter is set.
+// Images are immutable once uploaded, so they are cached for a year and revalidated by ID.
+func (h *MediaHandler) ServeImageHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	imageID := vars["id"]
+
+	image, err := h.mediaService.GetImage(imageID)
+	if err != nil {
+		http.Error(w, "Error fetching image", http.StatusInternalServerError)
+		return
+	}
+	if image == nil {
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+
+	thumb, _ := strconv.ParseBool(r.URL.Query().Get("thumb"))
+	etag := `"` + image.ID.Hex() + `"`
+	contentType := image.ContentType
+	if thumb {
+		etag = `"` + image.ID.Hex() + `-thumb"`
+		contentType = image.ThumbnailType
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", image.CreatedAt.UTC().Format(http.TimeFormat))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	content, err := h.mediaService.OpenImage(image, thumb)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			http.Error(w, "Image not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error reading image", http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("Error writing image %s: %v", imageID, err)
+	}
+}
+
+// DeleteImageHandler handles the deletion of an image.
+func (h *MediaHandler) DeleteImageHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	imageID := vars["id"]
+
+	image, err := h.mediaService.GetImage(imageID)
+	if err != nil {
+		http.Error(w, "Error fetching image", http.StatusInternalServerError)
+		return
+	}
+	if image == nil {
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+	if !h.authorizeOwner(w, r, image.OwnerType, image.OwnerID.Hex()) {
+		return
+	}
+
+	if err := h.mediaService.DeleteImage(imageID); err != nil {
+		if errors.Is(err, service.ErrImageNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error deleting image", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// uploadImage stores the "image" part of a multipart request for the owner in the route.
+func (h *MediaHandler) uploadImage(w http.ResponseWriter, r *http.Request, ownerType string) {
+	vars := mux.Vars(r)
+	ownerID := vars["id"]
+
+	if !h.authorizeOwner(w, r, ownerType, ownerID) {
+		return
+	}
+
+	// Leave room for the multipart framing around the file itself
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadBytes+1<<20)
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, service.ErrImageTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Missing image file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	image, err := h.mediaService.UploadImage(ownerType, ownerID, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImageOwnerNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrImageTooLarge):
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, service.ErrUnsupportedImageType):
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		default:
+			http.Error(w, "Error uploading image", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	respondWithJSON(w, image, http.StatusCreated)
+}
+
+// getImages lists the images of the owner in the route.
+func (h *MediaHandler) getImages(w http.ResponseWriter, r *http.Request, ownerType string) {
+	vars := mux.Vars(r)
+	ownerID := vars["id"]
+
+	images, err := h.mediaService.GetImages(ownerType, ownerID)
+	if err != nil {
+		if errors.Is(err, service.ErrImageOwnerNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error fetching images", http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJSON(w, images, http.StatusOK)
+}
+
+// authorizeOwner checks that the request may manage the images of a product or shop,
+// and responds with an error otherwise.
+func (h *MediaHandler) authorizeOwner(w http.ResponseWriter, r *http.Request, ownerType, ownerID string) bool {
+	if ownerType == models.ImageOwnerShop {
+		_, ok := authorizeShopOwner(w, r, h.sessionService, h.shopService, ownerID)
+		return ok
+	}
+	_, ok := authorizeAdmin(w, r, h.sessionService)
+	return ok
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PlotHandler handles HTTP requests related to the plots of land held by farmers.
+// Farmers may manage their own plots, and admins those of any farmer.
+type PlotHandler struct {
+	plotService    service.PlotService
+	sessionService service.SessionService
+}
+
+// NewPlotHandler creates a new instance of PlotHandler.
+func NewPlotHandler(plotService service.PlotService, sessionService service.SessionService) *PlotHandler {
+	return &PlotHandler{
+		plotService:    plotService,
+		sessionService: sessionService,
+	}
+}
+
+// AddPlotHandler handles the addition of a plot to the land held by a user.
+func (h *PlotHandler) AddPlotHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeUser(w, r, h.sessionService, vars["id"]); !ok {
+		return
+	}
+
+	var plot models.Plot
+	if err := json.NewDecoder(r.Body).Decode(&plot); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.plotService.AddPlot(vars["id"], &plot); err != nil {
+		respondWithPlotError(w, err)
+		return
+	}
+
+	respondWithJSON(w, plot, http.StatusCreated)
+}
+
+// GetUserPlotsHandler handles the retrieval of the plots of a user.
+func (h *PlotHandler) GetUserPlotsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeUser(w, r, h.sessionService, vars["id"]); !ok {
+		return
+	}
+
+	plots, err := h.plotService.GetUserPlots(vars["id"])
+	if err != nil {
+		respondWithPlotError(w, err)
+		return
+	}
+
+	respondWithJSON(w, plots, http.StatusOK)
+}
+
+// GetCropAreasHandler handles the retrieval of the area a user cultivates with each crop.
+func (h *PlotHandler) GetCropAreasHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeUser(w, r, h.sessionService, vars["id"]); !ok {
+		return
+	}
+
+	areas, err := h.plotService.GetCropAreas(vars["id"])
+	if err != nil {
+		respondWithPlotError(w, err)
+		return
+	}
+
+	respondWithJSON(w, areas, http.StatusOK)
+}
+
+// GetPlotByIDHandler handles the retrieval of a plot by its ID.
+func (h *PlotHandler) GetPlotByIDHandler(w http.ResponseWriter, r *http.Request) {
+	plot, ok := h.authorizePlot(w, r)
+	if !ok {
+		return
+	}
+
+	respondWithJSON(w, plot, http.StatusOK)
+}
+
+// UpdatePlotHandler handles the update of a plot.
+func (h *PlotHandler) UpdatePlotHandler(w http.ResponseWriter, r *http.Request) {
+	existingPlot, ok := h.authorizePlot(w, r)
+	if !ok {
+		return
+	}
+
+	var plot models.Plot
+	if err := json.NewDecoder(r.Body).Decode(&plot); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	plot.ID = existingPlot.ID
+
+	if err := h.plotService.UpdatePlot(&plot); err != nil {
+		respondWithPlotError(w, err)
+		return
+	}
+
+	respondWithJSON(w, plot, http.StatusOK)
+}
+
+// DeletePlotHandler handles the deletion of a plot.
+func (h *PlotHandler) DeletePlotHandler(w http.ResponseWriter, r *http.Request) {
+	plot, ok := h.authorizePlot(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.plotService.DeletePlot(plot.ID.Hex()); err != nil {
+		respondWithPlotError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Plot deleted successfully"))
+}
+
+// authorizePlot retrieves the plot with the ID in the request path, checking that the request
+// is made by its farmer or by an admin, and responds with an error otherwise.
+func (h *PlotHandler) authorizePlot(w http.ResponseWriter, r *http.Request) (*models.Plot, bool) {
+	vars := mux.Vars(r)
+
+	if _, err := primitive.ObjectIDFromHex(vars["id"]); err != nil {
+		http.Error(w, "Invalid plot ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	requester, ok := authenticate(w, r, h.sessionService)
+	if !ok {
+		return nil, false
+	}
+
+	plot, err := h.plotService.GetPlotByID(vars["id"])
+	if err != nil {
+		respondWithPlotError(w, err)
+		return nil, false
+	}
+	if !actsFor(requester, plot.UserID.Hex()) {
+		respondWithSessionError(w, service.ErrNotAuthorized)
+		return nil, false
+	}
+
+	return plot, true
+}
+
+// respondWithPlotError maps plot service errors to HTTP responses.
+func respondWithPlotError(w http.ResponseWriter, err error) {
+	if respondWithValidationError(w, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrPlotNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrAccountClosed):
+		http.Error(w, err.Error(), http.StatusGone)
+	default:
+		http.Error(w, "Error processing plots", http.StatusInternalServerError)
+	}
+}
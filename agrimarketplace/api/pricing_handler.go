@@ -0,0 +1,83 @@
+package api
+
+import (
+	"agrimarketplace/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// PricingHandler handles HTTP requests related to shop selling prices.
+type PricingHandler struct {
+	pricingService service.PricingService
+	shopService    service.ShopService
+	sessionService service.SessionService
+}
+
+// NewPricingHandler creates a new instance of PricingHandler.
+func NewPricingHandler(pricingService service.PricingService, shopService service.ShopService, sessionService service.SessionService) *PricingHandler {
+	return &PricingHandler{
+		pricingService: pricingService,
+		shopService:    shopService,
+		sessionService: sessionService,
+	}
+}
+
+// setShopPriceRequest is the body of a shop price update.
+type setShopPriceRequest struct {
+	Price float64
+}
+
+// SetShopPriceHandler handles the update of a shop's selling price for a product by the shop's
+// owner or an admin, who is recorded as having made the change.
+func (h *PricingHandler) SetShopPriceHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	requester, ok := authorizeShopOwner(w, r, h.sessionService, h.shopService, vars["id"])
+	if !ok {
+		return
+	}
+
+	var request setShopPriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	change, err := h.pricingService.SetShopPrice(vars["id"], vars["pid"], request.Price, requester.ID)
+	if err != nil {
+		respondWithPricingError(w, err)
+		return
+	}
+
+	respondWithJSON(w, change, http.StatusOK)
+}
+
+// GetPriceHistoryHandler handles the retrieval of a shop's price changes for a product.
+func (h *PricingHandler) GetPriceHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	history, err := h.pricingService.GetPriceHistory(vars["id"], vars["pid"])
+	if err != nil {
+		respondWithPricingError(w, err)
+		return
+	}
+
+	respondWithJSON(w, history, http.StatusOK)
+}
+
+// respondWithPricingError maps pricing service errors to HTTP responses.
+func respondWithPricingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrShopNotFound), errors.Is(err, service.ErrProductNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidPrice), errors.Is(err, service.ErrPriceAboveMRP):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrLicenceRequired), errors.Is(err, service.ErrLicenceExpired):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, "Error processing price", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"agrimarketplace/service"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// PrivacyHandler handles HTTP requests related to users' requests to access and erase their personal data.
+type PrivacyHandler struct {
+	privacyService service.PrivacyService
+	sessionService service.SessionService
+}
+
+// NewPrivacyHandler creates a new instance of PrivacyHandler.
+func NewPrivacyHandler(privacyService service.PrivacyService, sessionService service.SessionService) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+		sessionService: sessionService,
+	}
+}
+
+// ExportUserDataHandler handles the download of a ZIP archive of a user's personal data.
+// Users may export their own data, and admins that of any user.
+func (h *PrivacyHandler) ExportUserDataHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	requester, ok := authorizeUser(w, r, h.sessionService, vars["id"])
+	if !ok {
+		return
+	}
+
+	export, err := h.privacyService.ExportUserData(vars["id"], requester, r.RemoteAddr)
+	if err != nil {
+		respondWithPrivacyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="user-`+export.User.ID.Hex()+`.zip"`)
+
+	// The archive is streamed, so an error after the first file can only be logged
+	if err := h.privacyService.WriteUserDataArchive(w, export); err != nil {
+		log.Printf("Error writing data export of user %s: %v", export.User.ID.Hex(), err)
+	}
+}
+
+// CloseAccountHandler handles the closure of a user's account, which anonymizes their personal data.
+func (h *PrivacyHandler) CloseAccountHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	requester, ok := authorizeUser(w, r, h.sessionService, vars["id"])
+	if !ok {
+		return
+	}
+
+	if err := h.privacyService.CloseAccount(vars["id"], requester, r.RemoteAddr); err != nil {
+		respondWithPrivacyError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Account closed"))
+}
+
+// GetAuditLogHandler handles the retrieval of the actions taken on a user's personal data.
+func (h *PrivacyHandler) GetAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	entries, err := h.privacyService.GetAuditLog(vars["id"])
+	if err != nil {
+		respondWithPrivacyError(w, err)
+		return
+	}
+
+	respondWithJSON(w, entries, http.StatusOK)
+}
+
+// respondWithPrivacyError maps privacy service errors to HTTP responses.
+func respondWithPrivacyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrAccountClosed):
+		http.Error(w, err.Error(), http.StatusGone)
+	default:
+		http.Error(w, "Error processing personal data", http.StatusInternalServerError)
+	}
+}
@@ -3,8 +3,12 @@ package api
 import (
 	"agrimarketplace/models"
 	"agrimarketplace/service"
+	"agrimarketplace/validation"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -12,11 +16,13 @@ import (
 
 type ProductHandler struct {
 	productService service.ProductService
+	sessionService service.SessionService
 }
 
-func NewProductHandler(productService service.ProductService) *ProductHandler {
+func NewProductHandler(productService service.ProductService, sessionService service.SessionService) *ProductHandler {
 	return &ProductHandler{
 		productService: productService,
+		sessionService: sessionService,
 	}
 }
 
@@ -51,6 +57,151 @@ func (h *ProductHandler) GetProductsByCategoryHandler(w http.ResponseWriter, r *
 	respondWithJSON(w, products, http.StatusOK)
 }
 
+func (h *ProductHandler) ListProductsHandler(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := models.ProductQuery{
+		Brands:   params["brand"],
+		Statuses: params["status"],
+		Sort:     params.Get("sort"),
+		Cursor:   params.Get("cursor"),
+	}
+
+	for _, categoryID := range params["category"] {
+		id, err := primitive.ObjectIDFromHex(categoryID)
+		if err != nil {
+			http.Error(w, "Invalid category", http.StatusBadRequest)
+			return
+		}
+		query.CategoryIDs = append(query.CategoryIDs, id)
+	}
+
+	var err error
+	if query.MinPrice, err = parseOptionalFloat(params.Get("min_price")); err != nil {
+		http.Error(w, "Invalid min_price", http.StatusBadRequest)
+		return
+	}
+	if query.MaxPrice, err = parseOptionalFloat(params.Get("max_price")); err != nil {
+		http.Error(w, "Invalid max_price", http.StatusBadRequest)
+		return
+	}
+	if query.MinRating, err = parseOptionalFloat(params.Get("min_rating")); err != nil {
+		http.Error(w, "Invalid min_rating", http.StatusBadRequest)
+		return
+	}
+
+	// In-stock-near-me applies when a location is given
+	if params.Get("lat") != "" || params.Get("lon") != "" {
+		var area models.GeoFilter
+		if area.Latitude, err = strconv.ParseFloat(params.Get("lat"), 64); err != nil {
+			http.Error(w, "Invalid latitude", http.StatusBadRequest)
+			return
+		}
+		if area.Longitude, err = strconv.ParseFloat(params.Get("lon"), 64); err != nil {
+			http.Error(w, "Invalid longitude", http.StatusBadRequest)
+			return
+		}
+		if area.RadiusInMeters, err = strconv.ParseFloat(params.Get("radius"), 64); err != nil {
+			http.Error(w, "Invalid radius", http.StatusBadRequest)
+			return
+		}
+		query.InStockNear = &area
+	}
+
+	// Category attributes are filtered with attr.<name>, attr_min.<name> and attr_max.<name>
+	for key, values := range params {
+		switch {
+		case strings.HasPrefix(key, "attr."):
+			if query.Attributes == nil {
+				query.Attributes = map[string]string{}
+			}
+			query.Attributes[strings.TrimPrefix(key, "attr.")] = values[0]
+		case strings.HasPrefix(key, "attr_min."), strings.HasPrefix(key, "attr_max."):
+			bound, err := strconv.ParseFloat(values[0], 64)
+			if err != nil {
+				http.Error(w, "Invalid "+key, http.StatusBadRequest)
+				return
+			}
+			name := key[len("attr_min."):]
+			if strings.HasPrefix(key, "attr_min.") {
+				if query.AttributeMin == nil {
+					query.AttributeMin = map[string]float64{}
+				}
+				query.AttributeMin[name] = bound
+			} else {
+				if query.AttributeMax == nil {
+					query.AttributeMax = map[string]float64{}
+				}
+				query.AttributeMax[name] = bound
+			}
+		}
+	}
+
+	if limitStr := params.Get("limit"); limitStr != "" {
+		if query.Limit, err = strconv.Atoi(limitStr); err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.productService.ListProducts(query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSortKey) || errors.Is(err, service.ErrInvalidCursor) || errors.Is(err, service.ErrInvalidProductQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Error fetching products", http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJSON(w, page, http.StatusOK)
+}
+
+func (h *ProductHandler) SearchProductsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	results, err := h.productService.SearchProducts(query, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptySearchQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Error searching products", http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJSON(w, results, http.StatusOK)
+}
+
+func (h *ProductHandler) GetVariantUnitPricesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+
+	prices, err := h.productService.GetVariantUnitPrices(productID, r.URL.Query().Get("unit"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProductNotFound):
+			http.Error(w, "Product not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidUnit):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Error fetching unit prices", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	respondWithJSON(w, prices, http.StatusOK)
+}
+
 func (h *ProductHandler) CreateProductHandler(w http.ResponseWriter, r *http.Request) {
 	var product models.Product
 	err := json.NewDecoder(r.Body).Decode(&product)
@@ -61,6 +212,9 @@ func (h *ProductHandler) CreateProductHandler(w http.ResponseWriter, r *http.Req
 
 	err = h.productService.CreateProduct(&product)
 	if err != nil {
+		if respondWithValidationError(w, err) || respondWithAttributeError(w, err) || respondWithStatusError(w, err) {
+			return
+		}
 		http.Error(w, "Error creating product", http.StatusInternalServerError)
 		return
 	}
@@ -83,6 +237,13 @@ func (h *ProductHandler) UpdateProductHandler(w http.ResponseWriter, r *http.Req
 
 	err = h.productService.UpdateProduct(&updatedProduct)
 	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			http.Error(w, "Product not found", http.StatusNotFound)
+			return
+		}
+		if respondWithValidationError(w, err) || respondWithAttributeError(w, err) || respondWithStatusError(w, err) {
+			return
+		}
 		http.Error(w, "Error updating product", http.StatusInternalServerError)
 		return
 	}
@@ -90,12 +251,22 @@ func (h *ProductHandler) UpdateProductHandler(w http.ResponseWriter, r *http.Req
 	respondWithJSON(w, updatedProduct, http.StatusOK)
 }
 
+// DeleteProductHandler handles the deletion of a product. Products belong to the shared catalog
+// rather than to a shop, so only admins may delete them.
 func (h *ProductHandler) DeleteProductHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	productID := vars["id"]
 
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
 	err := h.productService.DeleteProduct(productID)
 	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			http.Error(w, "Product not found", http.StatusNotFound)
+			return
+		}
 		http.Error(w, "Error deleting product", http.StatusInternalServerError)
 		return
 	}
@@ -103,6 +274,62 @@ func (h *ProductHandler) DeleteProductHandler(w http.ResponseWriter, r *http.Req
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// setProductStatusRequest is the body of a product lifecycle change.
+type setProductStatusRequest struct {
+	Status string
+}
+
+// SetProductStatusHandler handles moving a product to another lifecycle state, which only admins may do.
+func (h *ProductHandler) SetProductStatusHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	var request setProductStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.productService.SetProductStatus(vars["id"], request.Status)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			http.Error(w, "Product not found", http.StatusNotFound)
+			return
+		}
+		if respondWithStatusError(w, err) {
+			return
+		}
+		http.Error(w, "Error updating product status", http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJSON(w, product, http.StatusOK)
+}
+
+// RestoreProductHandler handles the restoration of a soft-deleted product.
+func (h *ProductHandler) RestoreProductHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	product, err := h.productService.RestoreProduct(vars["id"])
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			http.Error(w, "Deleted product not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error restoring product", http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJSON(w, product, http.StatusOK)
+}
+
 func respondWithJSON(w http.ResponseWriter, data interface{}, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -111,3 +338,58 @@ func respondWithJSON(w http.ResponseWriter, data interface{}, statusCode int) {
 		http.Error(w, "Error encoding response data", http.StatusInternalServerError)
 	}
 }
+
+// respondWithValidationError writes the response listing every invalid field of a write and reports whether it did.
+// Invalid attributes, variants and attribute schemas are listed in the same shape as other fields.
+func respondWithValidationError(w http.ResponseWriter, err error) bool {
+	var fieldErr *validation.Error
+	var attributeErr *service.AttributeValidationError
+	switch {
+	case errors.As(err, &fieldErr):
+	case errors.As(err, &attributeErr):
+		fieldErr = &validation.Error{Fields: attributeErr.Fields}
+	default:
+		return false
+	}
+
+	respondWithJSON(w, fieldErr, http.StatusUnprocessableEntity)
+	return true
+}
+
+// respondWithAttributeError writes the response for products naming an unknown category or licence type
+// and reports whether it did.
+func respondWithAttributeError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		return respondWithValidationError(w, &validation.Error{Fields: map[string]string{"category_id": "does not exist"}})
+	case errors.Is(err, service.ErrInvalidLicenceType):
+		return respondWithValidationError(w, &validation.Error{Fields: map[string]string{"required_licence": "is not a known licence type"}})
+	}
+	return false
+}
+
+// respondWithStatusError writes the response for product lifecycle errors and reports whether it did.
+func respondWithStatusError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidProductStatus):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrInvalidStatusTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		return false
+	}
+	return true
+}
+
+// parseOptionalFloat parses a query parameter that may be omitted.
+func parseOptionalFloat(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
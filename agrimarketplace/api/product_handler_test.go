@@ -0,0 +1,75 @@
+package api
+
+import (
+	"agrimarketplace/service"
+	"agrimarketplace/validation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRespondWithValidationError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want map[string]string
+	}{
+		{
+			name: "field errors",
+			err:  &validation.Error{Fields: map[string]string{"product_name": "is required", "price": "must be at least 0"}},
+			want: map[string]string{"product_name": "is required", "price": "must be at least 0"},
+		},
+		{
+			name: "wrapped field errors",
+			err:  fmt.Errorf("row 3: %w", &validation.Error{Fields: map[string]string{"sku": "must be at most 64 characters"}}),
+			want: map[string]string{"sku": "must be at most 64 characters"},
+		},
+		// Invalid attributes and variants are listed in the same shape as other fields
+		{
+			name: "attribute errors",
+			err:  &service.AttributeValidationError{Fields: map[string]string{"attributes.npk_ratio": "is required", "variants[0]": "sku is required"}},
+			want: map[string]string{"attributes.npk_ratio": "is required", "variants[0]": "sku is required"},
+		},
+		{
+			name: "unknown category",
+			err:  service.ErrCategoryNotFound,
+			want: map[string]string{"category_id": "does not exist"},
+		},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		if !respondWithValidationError(w, tt.err) && !respondWithAttributeError(w, tt.err) {
+			t.Errorf("%s: no response written", tt.name)
+			continue
+		}
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, http.StatusUnprocessableEntity)
+		}
+
+		var body struct {
+			Fields map[string]string
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Errorf("%s: decoding body: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(body.Fields, tt.want) {
+			t.Errorf("%s: fields = %v, want %v", tt.name, body.Fields, tt.want)
+		}
+	}
+}
+
+func TestRespondWithValidationErrorIgnoresOtherErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	if respondWithValidationError(w, errors.New("connection reset")) {
+		t.Error("respondWithValidationError() = true for an error other than a validation error")
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("respondWithValidationError() wrote %q, want nothing", w.Body.String())
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PurchaseHandler handles HTTP requests related to purchases.
+type PurchaseHandler struct {
+	purchaseService service.PurchaseService
+	sessionService  service.SessionService
+}
+
+// NewPurchaseHandler creates a new instance of PurchaseHandler.
+func NewPurchaseHandler(purchaseService service.PurchaseService, sessionService service.SessionService) *PurchaseHandler {
+	return &PurchaseHandler{
+		purchaseService: purchaseService,
+		sessionService:  sessionService,
+	}
+}
+
+// placePurchaseRequest is the body of a purchase. The purchase is placed for the signed-in user.
+type placePurchaseRequest struct {
+	ShopID    primitive.ObjectID
+	AddressID primitive.ObjectID
+	Lines     []models.PurchaseLine
+}
+
+// PlacePurchaseHandler handles a purchase placed by the signed-in user with a shop.
+func (h *PurchaseHandler) PlacePurchaseHandler(w http.ResponseWriter, r *http.Request) {
+	requester, ok := authenticate(w, r, h.sessionService)
+	if !ok {
+		return
+	}
+
+	var request placePurchaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	purchase := models.Purchase{
+		UserID:    requester.ID,
+		ShopID:    request.ShopID,
+		AddressID: request.AddressID,
+		Lines:     request.Lines,
+	}
+	if err := h.purchaseService.PlacePurchase(&purchase); err != nil {
+		respondWithPurchaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, purchase, http.StatusCreated)
+}
+
+// GetPurchaseByIDHandler handles the retrieval of a purchase by its ID,
+// which its buyer and admins may see.
+func (h *PurchaseHandler) GetPurchaseByIDHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	requester, ok := authenticate(w, r, h.sessionService)
+	if !ok {
+		return
+	}
+
+	purchase, err := h.purchaseService.GetPurchaseByID(vars["id"])
+	if err != nil {
+		respondWithPurchaseError(w, err)
+		return
+	}
+	if !actsFor(requester, purchase.UserID.Hex()) {
+		respondWithSessionError(w, service.ErrNotAuthorized)
+		return
+	}
+
+	respondWithJSON(w, purchase, http.StatusOK)
+}
+
+// GetUserPurchasesHandler handles the retrieval of a user's purchases,
+// which the user and admins may see.
+func (h *PurchaseHandler) GetUserPurchasesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeUser(w, r, h.sessionService, vars["id"]); !ok {
+		return
+	}
+
+	purchases, err := h.purchaseService.GetUserPurchases(vars["id"])
+	if err != nil {
+		respondWithPurchaseError(w, err)
+		return
+	}
+
+	respondWithJSON(w, purchases, http.StatusOK)
+}
+
+// respondWithPurchaseError maps purchase service errors to HTTP responses.
+func respondWithPurchaseError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrPurchaseNotFound), errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, service.ErrShopNotFound), errors.Is(err, service.ErrProductNotFound), errors.Is(err, service.ErrAddressNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidPurchase), errors.Is(err, service.ErrInvalidStockQuantity):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrLicenceRequired), errors.Is(err, service.ErrLicenceExpired), errors.Is(err, service.ErrUserNotVerified):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrNotServiceable), errors.Is(err, service.ErrInsufficientStock):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrAccountClosed):
+		http.Error(w, err.Error(), http.StatusGone)
+	default:
+		http.Error(w, "Error processing purchase", http.StatusInternalServerError)
+	}
+}
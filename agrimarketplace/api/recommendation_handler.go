@@ -0,0 +1,66 @@
+package api
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// RecommendationHandler handles HTTP requests for product recommendations.
+type RecommendationHandler struct {
+	recommendationService service.RecommendationService
+}
+
+// NewRecommendationHandler creates a new instance of RecommendationHandler.
+func NewRecommendationHandler(recommendationService service.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{
+		recommendationService: recommendationService,
+	}
+}
+
+// FindRelatedProductsHandler handles the retrieval of the products frequently bought together with a
+// product, among those available from shops near the caller.
+func (h *RecommendationHandler) FindRelatedProductsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	params := r.URL.Query()
+
+	var area models.GeoFilter
+	var err error
+	if area.Latitude, err = strconv.ParseFloat(params.Get("lat"), 64); err != nil {
+		http.Error(w, "Invalid latitude", http.StatusBadRequest)
+		return
+	}
+	if area.Longitude, err = strconv.ParseFloat(params.Get("lon"), 64); err != nil {
+		http.Error(w, "Invalid longitude", http.StatusBadRequest)
+		return
+	}
+	if area.RadiusInMeters, err = strconv.ParseFloat(params.Get("radius"), 64); err != nil {
+		http.Error(w, "Invalid radius", http.StatusBadRequest)
+		return
+	}
+
+	limit := 0
+	if limitStr := params.Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	related, err := h.recommendationService.FindRelatedProducts(vars["id"], area, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error fetching related products", http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJSON(w, related, http.StatusOK)
+}
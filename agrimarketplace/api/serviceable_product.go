@@ -3,18 +3,26 @@ package api
 import (
 	"agrimarketplace/service"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
 )
 
 // ServiceableProductHandler handles HTTP requests related to serviceable products.
 type ServiceableProductHandler struct {
-	service service.ServiceableProductService
+	service        service.ServiceableProductService
+	shopService    service.ShopService
+	sessionService service.SessionService
 }
 
 // NewServiceableProductHandler creates a new instance of the ServiceableProductHandler.
-func NewServiceableProductHandler(service service.ServiceableProductService) *ServiceableProductHandler {
+func NewServiceableProductHandler(service service.ServiceableProductService, shopService service.ShopService, sessionService service.SessionService) *ServiceableProductHandler {
 	return &ServiceableProductHandler{
-		service: service,
+		service:        service,
+		shopService:    shopService,
+		sessionService: sessionService,
 	}
 }
 
@@ -44,3 +52,82 @@ func (h *ServiceableProductHandler) FindServiceableProductsHandler(w http.Respon
 		http.Error(w, "Failed to write response", http.StatusInternalServerError)
 	}
 }
+
+// FindShopsSellingProductHandler handles GET requests for the nearby shops that have a product in stock.
+func (h *ServiceableProductHandler) FindShopsSellingProductHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	productID := vars["id"]
+
+	// Extract latitude, longitude, radius and sort key from request query parameters
+	latitude, err := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	if err != nil {
+		http.Error(w, "Invalid latitude", http.StatusBadRequest)
+		return
+	}
+
+	longitude, err := strconv.ParseFloat(r.URL.Query().Get("lon"), 64)
+	if err != nil {
+		http.Error(w, "Invalid longitude", http.StatusBadRequest)
+		return
+	}
+
+	radius, err := strconv.ParseFloat(r.URL.Query().Get("radius"), 64)
+	if err != nil {
+		http.Error(w, "Invalid radius", http.StatusBadRequest)
+		return
+	}
+
+	availability, err := h.service.FindShopsSellingProduct(productID, latitude, longitude, radius, r.URL.Query().Get("sort"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProductNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidSortKey):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Failed to retrieve shops selling the product", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	respondWithJSON(w, availability, http.StatusOK)
+}
+
+// setShopListingRequest is the body of a shop listing update.
+type setShopListingRequest struct {
+	IsServiceable bool
+	StockQuantity int
+}
+
+// SetShopListingHandler handles the update of whether a shop serves a product and how much it holds,
+// by the shop's owner or an admin.
+func (h *ServiceableProductHandler) SetShopListingHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeShopOwner(w, r, h.sessionService, h.shopService, vars["id"]); !ok {
+		return
+	}
+
+	var request setShopListingRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	listing, err := h.service.SetShopListing(vars["id"], vars["pid"], request.IsServiceable, request.StockQuantity)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrShopNotFound), errors.Is(err, service.ErrProductNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidStockQuantity):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrLicenceRequired), errors.Is(err, service.ErrLicenceExpired):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, "Failed to update listing", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	respondWithJSON(w, listing, http.StatusOK)
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"agrimarketplace/auth"
+	"agrimarketplace/models"
+	"agrimarketplace/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// SessionHandler handles HTTP requests related to sign-in sessions and their tokens.
+type SessionHandler struct {
+	sessionService service.SessionService
+}
+
+// NewSessionHandler creates a new instance of SessionHandler.
+func NewSessionHandler(sessionService service.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// refreshRequest is the body of a session refresh. The device is optional and updates what is known of it.
+type refreshRequest struct {
+	RefreshToken string
+	Device       models.DeviceInfo
+}
+
+// RefreshHandler handles the exchange of a refresh token for new tokens.
+func (h *SessionHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	var request refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	login, err := h.sessionService.Refresh(request.RefreshToken, requestDevice(r, request.Device))
+	if err != nil {
+		respondWithSessionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, login, http.StatusOK)
+}
+
+// SignOutHandler handles the sign-out of the session the request's access token was issued in.
+func (h *SessionHandler) SignOutHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.sessionService.SignOut(bearerToken(r)); err != nil {
+		respondWithSessionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Signed out"))
+}
+
+// GetUserSessionsHandler handles the retrieval of the sessions a user is signed in with.
+// Users may list their own sessions, and admins those of any user.
+func (h *SessionHandler) GetUserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeUser(w, r, h.sessionService, vars["id"]); !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.GetUserSessions(vars["id"])
+	if err != nil {
+		respondWithSessionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, sessions, http.StatusOK)
+}
+
+// RevokeSessionHandler handles signing a user out of one of their sessions.
+func (h *SessionHandler) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeUser(w, r, h.sessionService, vars["id"]); !ok {
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(vars["id"], vars["sid"]); err != nil {
+		respondWithSessionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Session revoked"))
+}
+
+// SignOutEverywhereHandler handles an admin signing a user out of every session.
+func (h *SessionHandler) SignOutEverywhereHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	revoked, err := h.sessionService.RevokeAllSessions(vars["id"], models.SessionRevokedByAdmin)
+	if err != nil {
+		respondWithSessionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, map[string]int64{"RevokedSessions": revoked}, http.StatusOK)
+}
+
+// requestDevice completes the device described by a client with what the request tells about it.
+func requestDevice(r *http.Request, device models.DeviceInfo) models.DeviceInfo {
+	device.UserAgent = r.UserAgent()
+	device.IPAddress = r.RemoteAddr
+	return device
+}
+
+// respondWithSessionError maps session service errors to HTTP responses.
+func respondWithSessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrTokenExpired),
+		errors.Is(err, service.ErrInvalidRefreshToken), errors.Is(err, service.ErrRefreshTokenReused):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, service.ErrNotAuthorized):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrSessionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, "Error processing sessions", http.StatusInternalServerError)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"agrimarketplace/models"
 	"agrimarketplace/service"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -12,13 +13,17 @@ import (
 
 // ShopHandler handles HTTP requests related to shops.
 type ShopHandler struct {
-	ShopService service.ShopService
+	ShopService     service.ShopService
+	DeliveryService service.DeliveryService
+	SessionService  service.SessionService
 }
 
 // NewShopHandler creates a new instance of ShopHandler.
-func NewShopHandler(shopService service.ShopService) *ShopHandler {
+func NewShopHandler(shopService service.ShopService, deliveryService service.DeliveryService, sessionService service.SessionService) *ShopHandler {
 	return &ShopHandler{
-		ShopService: shopService,
+		ShopService:     shopService,
+		DeliveryService: deliveryService,
+		SessionService:  sessionService,
 	}
 }
 
@@ -33,7 +38,7 @@ func (h *ShopHandler) CreateShopHandler(w http.ResponseWriter, r *http.Request)
 
 	createdShop, err := h.ShopService.CreateShop(&shop)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondWithShopWriteError(w, err)
 		return
 	}
 
@@ -67,19 +72,27 @@ func (h *ShopHandler) UpdateShopHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := h.ShopService.UpdateShop(&shop); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondWithShopWriteError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// DeleteShopHandler handles the deletion of a shop by ID.
+// DeleteShopHandler handles the deletion of a shop by ID, by its owner or an admin.
 func (h *ShopHandler) DeleteShopHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	shopID := vars["id"]
 
+	if _, ok := authorizeShopOwner(w, r, h.SessionService, h.ShopService, shopID); !ok {
+		return
+	}
+
 	if err := h.ShopService.DeleteShop(shopID); err != nil {
+		if errors.Is(err, service.ErrShopNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -87,6 +100,28 @@ func (h *ShopHandler) DeleteShopHandler(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// RestoreShopHandler handles the restoration of a soft-deleted shop.
+func (h *ShopHandler) RestoreShopHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shopID := vars["id"]
+
+	if _, ok := authorizeAdmin(w, r, h.SessionService); !ok {
+		return
+	}
+
+	shop, err := h.ShopService.RestoreShop(shopID)
+	if err != nil {
+		if errors.Is(err, service.ErrShopNotFound) {
+			http.Error(w, "Deleted shop not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondWithJSON(w, shop, http.StatusOK)
+}
+
 // FindNearbyShopsHandler handles the retrieval of nearby shops.
 func (h *ShopHandler) FindNearbyShopsHandler(w http.ResponseWriter, r *http.Request) {
 	// Extract latitude, longitude, and radius from request query parameters
@@ -113,8 +148,25 @@ func (h *ShopHandler) FindNearbyShopsHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// The order amount is optional and only affects free-delivery eligibility
+	amount := 0.0
+	if amountStr := r.URL.Query().Get("amount"); amountStr != "" {
+		amount, err = strconv.ParseFloat(amountStr, 64)
+		if err != nil {
+			http.Error(w, "Invalid amount", http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Call the ShopService to find nearby shops
-	nearbyShops, err := h.ShopService.FindNearbyShops(latitude, longitude, radius)
+	shops, err := h.ShopService.FindNearbyShops(latitude, longitude, radius)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Attach a delivery quote to every shop
+	nearbyShops, err := h.DeliveryService.QuoteNearbyShops(shops, latitude, longitude, amount)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -123,3 +175,63 @@ func (h *ShopHandler) FindNearbyShopsHandler(w http.ResponseWriter, r *http.Requ
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(nearbyShops)
 }
+
+// FindShopsInViewportHandler handles the retrieval of shops inside a map viewport.
+func (h *ShopHandler) FindShopsInViewportHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract the bounding box corners from request query parameters
+	var box models.BoundingBox
+	params := []struct {
+		name  string
+		value *float64
+	}{
+		{"min_lat", &box.MinLatitude},
+		{"min_lon", &box.MinLongitude},
+		{"max_lat", &box.MaxLatitude},
+		{"max_lon", &box.MaxLongitude},
+	}
+	for _, param := range params {
+		value, err := strconv.ParseFloat(r.URL.Query().Get(param.name), 64)
+		if err != nil {
+			http.Error(w, "Invalid "+param.name, http.StatusBadRequest)
+			return
+		}
+		*param.value = value
+	}
+
+	zoom, err := strconv.Atoi(r.URL.Query().Get("zoom"))
+	if err != nil {
+		http.Error(w, "Invalid zoom", http.StatusBadRequest)
+		return
+	}
+
+	viewport, err := h.ShopService.FindShopsInViewport(box, zoom)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBoundingBox) || errors.Is(err, service.ErrInvalidZoom) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(viewport)
+}
+
+// respondWithShopWriteError maps errors from creating or updating a shop to HTTP responses.
+func respondWithShopWriteError(w http.ResponseWriter, err error) {
+	if respondWithValidationError(w, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, service.ErrShopNotFound), errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrShopAlreadyExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrUserNotVerified):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
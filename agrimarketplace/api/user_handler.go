@@ -4,6 +4,7 @@ import (
 	"agrimarketplace/models"
 	"agrimarketplace/service"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -12,15 +13,31 @@ import (
 )
 
 type UserHandler struct {
-	userService service.UserService
+	userService    service.UserService
+	accountService service.AccountService
+	sessionService service.SessionService
 }
 
-func NewUserHandler(userService service.UserService) *UserHandler {
+func NewUserHandler(userService service.UserService, accountService service.AccountService, sessionService service.SessionService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:    userService,
+		accountService: accountService,
+		sessionService: sessionService,
 	}
 }
 
+// respondWithUser responds with the full record of a user to the user and admins,
+// and with the user's public profile to anyone else.
+func (h *UserHandler) respondWithUser(w http.ResponseWriter, r *http.Request, user *models.User) {
+	if requester, err := h.sessionService.Authenticate(bearerToken(r)); err == nil && actsFor(requester, user.ID.Hex()) {
+		user.Password = ""
+		respondWithJSON(w, user, http.StatusOK)
+		return
+	}
+
+	respondWithJSON(w, user.PublicProfile(), http.StatusOK)
+}
+
 func (h *UserHandler) FindUserByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["id"]
@@ -36,8 +53,7 @@ func (h *UserHandler) FindUserByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(user)
+	h.respondWithUser(w, r, user)
 }
 
 func (h *UserHandler) FindUserByUsername(w http.ResponseWriter, r *http.Request) {
@@ -55,11 +71,17 @@ func (h *UserHandler) FindUserByUsername(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(user)
+	h.respondWithUser(w, r, user)
 }
 
-func (h *UserHandler) InsertUser(w http.ResponseWriter, r *http.Request) {
+func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if _, ok := authorizeUser(w, r, h.sessionService, userID); !ok {
+		return
+	}
+
 	var user models.User
 	err := json.NewDecoder(r.Body).Decode(&user)
 	if err != nil {
@@ -67,54 +89,91 @@ func (h *UserHandler) InsertUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.userService.InsertUser(&user)
+	user.ID, _ = primitive.ObjectIDFromHex(userID)
+
+	err = h.accountService.UpdateUser(&user)
 	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrAccountClosed) {
+			http.Error(w, err.Error(), http.StatusGone)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCrop) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if respondWithValidationError(w, err) {
+			return
+		}
+		var conflict *service.ConflictError
+		if errors.As(err, &conflict) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte("User created successfully"))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("User updated successfully"))
 }
 
-func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
+func (h *UserHandler) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["id"]
 
-	var user models.User
-	err := json.NewDecoder(r.Body).Decode(&user)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if _, ok := authorizeUser(w, r, h.sessionService, userID); !ok {
 		return
 	}
 
-	user.ID, _ = primitive.ObjectIDFromHex(userID)
-
-	err = h.userService.UpdateUser(&user)
+	err := h.userService.DeleteUser(userID)
 	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("User updated successfully"))
+	w.Write([]byte("User deleted successfully"))
 }
 
-func (h *UserHandler) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+func (h *UserHandler) RestoreUserHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["id"]
 
-	err := h.userService.DeleteUser(userID)
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	user, err := h.userService.RestoreUser(userID)
 	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			http.Error(w, "Deleted user not found", http.StatusNotFound)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("User deleted successfully"))
+	user.Password = ""
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
 }
 
 func (h *UserHandler) FindNearbyUsersHandler(w http.ResponseWriter, r *http.Request) {
+	// Only signed-in users with an allowed role may search
+	requester, err := h.sessionService.Authenticate(bearerToken(r))
+	if err != nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
 	// Extract latitude, longitude, and radius from request query parameters
 	latStr := r.URL.Query().Get("latitude")
 	lonStr := r.URL.Query().Get("longitude")
@@ -138,12 +197,54 @@ func (h *UserHandler) FindNearbyUsersHandler(w http.ResponseWriter, r *http.Requ
 		http.Error(w, "Invalid radius", http.StatusBadRequest)
 		return
 	}
-	nearbyUsers, err := h.userService.FindNearbyUsers(latitude, longitude, radius)
+	nearbyUsers, err := h.userService.FindNearbyUsers(requester, latitude, longitude, radius)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, service.ErrNotAuthorized):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, service.ErrInvalidSearchArea):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(nearbyUsers)
 }
+
+// setUserRoleRequest is the body of a role change; an empty role removes the user's role.
+type setUserRoleRequest struct {
+	Role string
+}
+
+func (h *UserHandler) SetUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if _, ok := authorizeAdmin(w, r, h.sessionService); !ok {
+		return
+	}
+
+	var request setUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.SetUserRole(vars["id"], request.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			http.Error(w, "User not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidRole):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	user.Password = ""
+	respondWithJSON(w, user, http.StatusOK)
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"strings"
+)
+
+// GenerateOTP returns a random numeric one-time password of the given number of digits.
+func GenerateOTP(digits int) (string, error) {
+	var code strings.Builder
+	for i := 0; i < digits; i++ {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code.WriteByte(byte('0' + digit.Int64()))
+	}
+
+	return code.String(), nil
+}
+
+// GenerateToken returns a random URL-safe token carrying the given number of random bytes.
+func GenerateToken(size int) (string, error) {
+	token := make([]byte, size)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// Digest returns a keyed hash of a secret such as a one-time password, for storing it at rest.
+// Unlike a plain hash, short secrets cannot be recovered from the digest without the signing key.
+func (s *Signer) Digest(secret string) string {
+	return hex.EncodeToString(s.mac(secret))
+}
+
+// DigestEqual reports whether a secret matches a digest produced by Digest, in constant time.
+func (s *Signer) DigestEqual(digest, secret string) bool {
+	return hmac.Equal([]byte(digest), []byte(s.Digest(secret)))
+}
@@ -0,0 +1,105 @@
+// Command catalog imports and exports the product catalog in bulk.
+//
+// Usage:
+//
+//	catalog import -format csv -file products.csv [-dry-run]
+//	catalog export -format ndjson -file products.ndjson
+//
+// Without -file, import reads from standard input and export writes to standard output.
+package main
+
+import (
+	"agrimarketplace/repository"
+	"agrimarketplace/service"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] != "import" && os.Args[1] != "export") {
+		fmt.Fprintln(os.Stderr, "usage: catalog import|export [-format csv|ndjson] [-file path] [-dry-run]")
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	format := flags.String("format", service.CatalogFormatCSV, "catalog file format: csv or ndjson")
+	path := flags.String("file", "", "catalog file; standard input or output when empty")
+	dryRun := flags.Bool("dry-run", false, "validate an import without writing anything")
+	flags.Parse(os.Args[2:])
+
+	// Initialize MongoDB connection
+	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017") // Update with your MongoDB URI
+	client, err := mongo.NewClient(clientOptions)
+	if err != nil {
+		log.Fatalf("Error creating MongoDB client: %v", err)
+	}
+
+	err = client.Connect(nil)
+	if err != nil {
+		log.Fatalf("Error connecting to MongoDB: %v", err)
+	}
+	defer client.Disconnect(nil)
+
+	database := client.Database("your_database_name") // Replace with your database name
+	if err := repository.EnsureIndexes(database); err != nil {
+		log.Fatalf("Error creating MongoDB indexes: %v", err)
+	}
+
+	productRepository := repository.NewProductRepository(database)
+	categoryRepository := repository.NewCategoryRepository(database)
+	shopRepository := repository.NewShopRepository(database)
+	serviceableProductRepository := repository.NewServiceableProductRepository(database)
+
+	// Catalog rows never touch images, shop listings or searches, so media, licences and synonyms are not needed
+	productService := service.NewProductService(productRepository, categoryRepository, shopRepository, serviceableProductRepository, nil, nil, nil)
+	catalogService := service.NewCatalogService(productService, productRepository, categoryRepository)
+
+	if command == "export" {
+		var out io.Writer = os.Stdout
+		if *path != "" {
+			file, err := os.Create(*path)
+			if err != nil {
+				log.Fatalf("Error creating catalog file: %v", err)
+			}
+			defer file.Close()
+			out = file
+		}
+
+		if err := catalogService.ExportCatalog(out, *format); err != nil {
+			log.Fatalf("Error exporting catalog: %v", err)
+		}
+		return
+	}
+
+	var in io.Reader = os.Stdin
+	if *path != "" {
+		file, err := os.Open(*path)
+		if err != nil {
+			log.Fatalf("Error opening catalog file: %v", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	report, err := catalogService.ImportCatalog(in, *format, *dryRun)
+	if err != nil {
+		log.Fatalf("Error importing catalog: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	log.Printf("%d rows: %d created, %d updated, %d failed", report.Total, report.Created, report.Updated, report.Failed)
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
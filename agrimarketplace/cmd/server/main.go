@@ -2,10 +2,19 @@ package main
 
 import (
 	"agrimarketplace/api"
+	"agrimarketplace/auth"
+	"agrimarketplace/mail"
+	"agrimarketplace/models"
 	"agrimarketplace/repository"
+	"agrimarketplace/search"
 	"agrimarketplace/service"
+	"agrimarketplace/sms"
+	"agrimarketplace/storage"
+	"crypto/rand"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -28,45 +37,258 @@ func main() {
 	// Create a new MongoDB database instance
 	database := client.Database("your_database_name") // Replace with your database name
 
+	// Create the indexes the repositories rely on
+	if err := repository.EnsureIndexes(database); err != nil {
+		log.Fatalf("Error creating MongoDB indexes: %v", err)
+	}
+
+	// Vernacular names matched by product search; set SYNONYMS_FILE to a JSON array of groups to override
+	synonyms := search.NewSynonyms([][]string{
+		{"khaad", "khad", "fertilizer", "fertiliser"},
+		{"beej", "bij", "seed", "seeds"},
+		{"dawai", "dawa", "pesticide", "insecticide"},
+		{"dhan", "paddy", "rice"},
+		{"gehun", "gehu", "wheat"},
+		{"kapas", "cotton"},
+		{"makka", "maize", "corn"},
+		{"sarson", "mustard"},
+	})
+	if path := os.Getenv("SYNONYMS_FILE"); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Error opening synonyms file: %v", err)
+		}
+		synonyms, err = search.LoadSynonyms(file)
+		file.Close()
+		if err != nil {
+			log.Fatalf("Error reading synonyms file: %v", err)
+		}
+	}
+
 	// Initialize repositories
 	userRepository := repository.NewUserRepository(database)
+	otpRepository := repository.NewOTPRepository(database)
+	passwordResetRepository := repository.NewPasswordResetRepository(database)
+	rateLimitRepository := repository.NewRateLimitRepository(database)
+	sessionRepository := repository.NewSessionRepository(database)
 	shopRepository := repository.NewShopRepository(database)
 	productRepository := repository.NewProductRepository(database)
+	categoryRepository := repository.NewCategoryRepository(database)
 	serviceableProductRepository := repository.NewServiceableProductRepository(database)
+	deliveryFeeRepository := repository.NewDeliveryFeeRepository(database)
+	imageRepository := repository.NewImageRepository(database)
+	priceHistoryRepository := repository.NewPriceHistoryRepository(database)
+	licenceRepository := repository.NewLicenceRepository(database)
+	stockLotRepository := repository.NewStockLotRepository(database)
+	purchaseRepository := repository.NewPurchaseRepository(database)
+	cooccurrenceRepository := repository.NewCooccurrenceRepository(database)
+	cropRuleRepository := repository.NewCropRuleRepository(database)
+	plotRepository := repository.NewPlotRepository(database)
+	addressRepository := repository.NewAddressRepository(database)
+	privacyAuditRepository := repository.NewPrivacyAuditRepository(database)
+
+	// Uploaded images are kept on the local filesystem
+	blobStore, err := storage.NewLocalBlobStore("./media") // Update with your media directory
+	if err != nil {
+		log.Fatalf("Error creating media store: %v", err)
+	}
+	mediaConfig := service.MediaConfig{
+		MaxUploadBytes: 5 << 20,
+		ThumbnailSize:  256,
+	}
+
+	// Emails are written to a local outbox; replace with a real Mailer to deliver them
+	mailer, err := mail.NewOutboxMailer("./outbox", "no-reply@agrimarketplace.local") // Update with your outbox directory and sender
+	if err != nil {
+		log.Fatalf("Error creating mail outbox: %v", err)
+	}
+
+	// Tokens are signed with TOKEN_SIGNING_KEY; without it, tokens issued before a restart stop working
+	signingKey := []byte(os.Getenv("TOKEN_SIGNING_KEY"))
+	if len(signingKey) == 0 {
+		log.Println("TOKEN_SIGNING_KEY is not set; using a random signing key")
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			log.Fatalf("Error generating signing key: %v", err)
+		}
+	}
+	signer := auth.NewSigner(signingKey)
+
+	// Text messages are written to the server log; replace with a real SMSSender to deliver them
+	smsSender := sms.NewLogSender(log.Default())
 
 	// Initialize services
-	userService := service.NewUserService(userRepository)
-	shopService := service.NewShopService(shopRepository)
-	productService := service.NewProductService(productRepository)
-	serviceableProductService := service.NewServiceableProductService(serviceableProductRepository)
+	mediaService := service.NewMediaService(imageRepository, productRepository, shopRepository, blobStore, mediaConfig)
+	licenceService := service.NewLicenceService(licenceRepository, shopRepository, productRepository, categoryRepository)
+	userService := service.NewUserService(userRepository, service.NearbyUsersConfig{
+		AllowedRoles:     []string{models.RoleAdmin, models.RoleFieldAgent},
+		GeohashPrecision: 5, // Cells of about 5 km
+		MaxRadius:        50000,
+		MaxResults:       100,
+	})
+
+	// Admin endpoints need an admin; ADMIN_USER_ID names the user granted the role to set up the first one
+	if adminID := os.Getenv("ADMIN_USER_ID"); adminID != "" {
+		if _, err := userService.SetUserRole(adminID, models.RoleAdmin); err != nil {
+			log.Fatalf("Error granting the admin role: %v", err)
+		}
+	}
+	sessionService := service.NewSessionService(sessionRepository, userRepository, signer, service.SessionConfig{
+		AccessTokenTTL:   15 * time.Minute,
+		RefreshTokenTTL:  90 * 24 * time.Hour, // Farmers stay signed in as long as they open the app every three months
+		MaxRotatedHashes: 50,
+	})
+	accountService := service.NewAccountService(userRepository, passwordResetRepository, rateLimitRepository, userService, sessionService, mailer, smsSender, signer, service.AccountConfig{
+		VerificationTTL:       24 * time.Hour,
+		VerificationURL:       "http://localhost:8080/users/verify", // Update with your public URL
+		PasswordResetTTL:      30 * time.Minute,
+		PasswordResetURL:      "http://localhost:8080/reset-password", // Update with the page of your frontend that sets the new password
+		MaxPasswordAttempts:   5,
+		PasswordAttemptWindow: 15 * time.Minute,
+	})
+	otpService := service.NewOTPService(otpRepository, userRepository, userService, sessionService, smsSender, signer, service.OTPConfig{
+		Digits:         6,
+		TTL:            5 * time.Minute,
+		MaxAttempts:    5,
+		ResendInterval: 30 * time.Second,
+		MaxSends:       5,
+		SendWindow:     time.Hour,
+	})
+	shopService := service.NewShopService(shopRepository, userRepository, mediaService)
+	productService := service.NewProductService(productRepository, categoryRepository, shopRepository, serviceableProductRepository, mediaService, licenceService, synonyms)
+	categoryService := service.NewCategoryService(categoryRepository)
+	inventoryService := service.NewInventoryService(stockLotRepository, serviceableProductRepository, shopRepository, productRepository, licenceService)
+	recommendationService := service.NewRecommendationService(cooccurrenceRepository, purchaseRepository, productRepository, shopRepository, serviceableProductRepository, licenceService)
+	purchaseService := service.NewPurchaseService(purchaseRepository, userRepository, addressRepository, shopRepository, productRepository, serviceableProductRepository, licenceService, inventoryService, recommendationService)
+	cropService := service.NewCropService(cropRuleRepository, userRepository, categoryRepository, productService)
+	plotService := service.NewPlotService(plotRepository, userRepository)
+	addressService := service.NewAddressService(addressRepository, userRepository)
+	privacyService := service.NewPrivacyService(userRepository, shopRepository, purchaseRepository, addressRepository, plotRepository, otpRepository, privacyAuditRepository)
+	catalogService := service.NewCatalogService(productService, productRepository, categoryRepository)
+	serviceableProductService := service.NewServiceableProductService(serviceableProductRepository, shopRepository, productRepository, licenceService)
+	pricingService := service.NewPricingService(serviceableProductRepository, priceHistoryRepository, shopRepository, productRepository, licenceService, service.PricingConfig{
+		EnforceMRP: true, // Catalog prices are the printed MRP, which shops may not exceed
+	})
+	deliveryService := service.NewDeliveryService(shopRepository, deliveryFeeRepository, service.DeliveryConfig{
+		// Applied to shops that have not configured their own fee schedule
+		DefaultSchedule: models.DeliveryFeeSchedule{
+			BaseFee: 20,
+			Slabs: []models.DeliveryFeeSlab{
+				{UpToKm: 5, FeePerKm: 5},
+				{UpToKm: 15, FeePerKm: 8},
+				{UpToKm: 30, FeePerKm: 12},
+			},
+			FreeDeliveryThreshold: 2000,
+			MaxDistanceKm:         30,
+			AverageSpeedKmph:      25,
+			PreparationMinutes:    30,
+		},
+		RoadFactor: 1.3,
+		Location:   time.FixedZone("IST", 5*60*60+30*60), // Shop operating hours are in Indian Standard Time
+	})
+
+	// Permanently remove records soft-deleted for longer than the retention window
+	const deletedRetention = 90 * 24 * time.Hour
+	go func() {
+		for ; ; time.Sleep(24 * time.Hour) {
+			deletedBefore := time.Now().Add(-deletedRetention)
+			purges := []struct {
+				name  string
+				purge func(time.Time) (int, error)
+			}{
+				{"products", productService.PurgeDeletedProducts},
+				{"shops", shopService.PurgeDeletedShops},
+				{"users", userService.PurgeDeletedUsers},
+			}
+			for _, p := range purges {
+				purged, err := p.purge(deletedBefore)
+				if err != nil {
+					log.Printf("Error purging deleted %s: %v", p.name, err)
+				}
+				if purged > 0 {
+					log.Printf("Purged %d deleted %s", purged, p.name)
+				}
+			}
+		}
+	}()
+
+	// Take lots out of the available stock of their listings as they expire
+	go func() {
+		since := time.Time{}
+		for ; ; time.Sleep(time.Hour) {
+			now := time.Now()
+			if _, err := inventoryService.RefreshExpiredStock(since); err != nil {
+				log.Printf("Error refreshing expired stock: %v", err)
+				continue
+			}
+			since = now
+		}
+	}()
+
+	// Recompute frequently-bought-together counts from all purchases, on top of the per-purchase updates
+	go func() {
+		for ; ; time.Sleep(24 * time.Hour) {
+			if _, err := recommendationService.RebuildCooccurrences(); err != nil {
+				log.Printf("Error rebuilding product co-occurrences: %v", err)
+			}
+		}
+	}()
+
+	// Remove images whose product or shop was deleted without cleaning them up
+	go func() {
+		for ; ; time.Sleep(24 * time.Hour) {
+			deleted, err := mediaService.DeleteOrphanedImages()
+			if err != nil {
+				log.Printf("Error deleting orphaned images: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("Deleted %d orphaned images", deleted)
+			}
+		}
+	}()
 
 	// Initialize handlers
-	userHandler := api.NewUserHandler(userService)
-	shopHandler := api.NewShopHandler(shopService)
-	productHandler := api.NewProductHandler(productService)
-	serviceableProductHandler := api.NewServiceableProductHandler(serviceableProductService)
+	userHandler := api.NewUserHandler(userService, accountService, sessionService)
+	accountHandler := api.NewAccountHandler(accountService, otpService, sessionService)
+	sessionHandler := api.NewSessionHandler(sessionService)
+	shopHandler := api.NewShopHandler(shopService, deliveryService, sessionService)
+	productHandler := api.NewProductHandler(productService, sessionService)
+	categoryHandler := api.NewCategoryHandler(categoryService, sessionService)
+	serviceableProductHandler := api.NewServiceableProductHandler(serviceableProductService, shopService, sessionService)
+	mediaHandler := api.NewMediaHandler(mediaService, shopService, sessionService, mediaConfig.MaxUploadBytes)
+	pricingHandler := api.NewPricingHandler(pricingService, shopService, sessionService)
+	catalogHandler := api.NewCatalogHandler(catalogService, sessionService, 50<<20)
+	licenceHandler := api.NewLicenceHandler(licenceService, sessionService)
+	inventoryHandler := api.NewInventoryHandler(inventoryService, shopService, sessionService)
+	purchaseHandler := api.NewPurchaseHandler(purchaseService, sessionService)
+	recommendationHandler := api.NewRecommendationHandler(recommendationService)
+	cropHandler := api.NewCropHandler(cropService, sessionService)
+	plotHandler := api.NewPlotHandler(plotService, sessionService)
+	addressHandler := api.NewAddressHandler(addressService, sessionService)
+	privacyHandler := api.NewPrivacyHandler(privacyService, sessionService)
 
 	// Create a router and define routes
-	router := http.NewServeMux()
-
-	// Define routes for user-related endpoints
-	router.HandleFunc("/users/{username}", userHandler.FindUserByUsername)
-	router.HandleFunc("/users/{id}", userHandler.FindUserByID)
-	router.HandleFunc("/users/add", userHandler.InsertUser)
-	router.HandleFunc("/users/update/{id}", userHandler.UpdateUserHandler)
-	// Add routes for other user-related endpoints as needed
-
-	// Define routes for shop-related endpoints
-	router.HandleFunc("/shops/{id}", shopHandler.FindShopByIDHandler)
-	// Add routes for other shop-related endpoints as needed
-
-	// Define routes for product-related endpoints
-	router.HandleFunc("/products/{id}", productHandler.GetProductByIDHandler)
-	// Add routes for other product-related endpoints as needed
-
-	// Define routes for serviceable product-related endpoints
-	router.HandleFunc("/serviceable-products", serviceableProductHandler.FindServiceableProductsHandler)
-	// Add routes for other serviceable product-related endpoints as needed
+	router := newRouter(handlers{
+		user:               userHandler,
+		account:            accountHandler,
+		session:            sessionHandler,
+		shop:               shopHandler,
+		product:            productHandler,
+		category:           categoryHandler,
+		serviceableProduct: serviceableProductHandler,
+		media:              mediaHandler,
+		pricing:            pricingHandler,
+		catalog:            catalogHandler,
+		licence:            licenceHandler,
+		inventory:          inventoryHandler,
+		purchase:           purchaseHandler,
+		recommendation:     recommendationHandler,
+		crop:               cropHandler,
+		plot:               plotHandler,
+		address:            addressHandler,
+		privacy:            privacyHandler,
+	})
 
 	// Start the HTTP server
 	log.Println("Server started on :8080")
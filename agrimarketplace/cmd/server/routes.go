@@ -0,0 +1,152 @@
+package main
+
+import (
+	"agrimarketplace/api"
+
+	"github.com/gorilla/mux"
+)
+
+// objectIDPattern matches the hex form of a MongoDB ObjectID in a route variable.
+const objectIDPattern = "[0-9a-fA-F]{24}"
+
+// handlers holds the HTTP handlers the server routes requests to.
+type handlers struct {
+	user               *api.UserHandler
+	account            *api.AccountHandler
+	session            *api.SessionHandler
+	shop               *api.ShopHandler
+	product            *api.ProductHandler
+	category           *api.CategoryHandler
+	serviceableProduct *api.ServiceableProductHandler
+	media              *api.MediaHandler
+	pricing            *api.PricingHandler
+	catalog            *api.CatalogHandler
+	licence            *api.LicenceHandler
+	inventory          *api.InventoryHandler
+	purchase           *api.PurchaseHandler
+	recommendation     *api.RecommendationHandler
+	crop               *api.CropHandler
+	plot               *api.PlotHandler
+	address            *api.AddressHandler
+	privacy            *api.PrivacyHandler
+}
+
+// newRouter defines the routes of the server. Routes are matched in the order they are added,
+// so fixed paths come before the variable paths they would otherwise be taken for.
+func newRouter(h handlers) *mux.Router {
+	router := mux.NewRouter()
+
+	// Define routes for user-related endpoints
+	router.HandleFunc("/users/nearby", h.user.FindNearbyUsersHandler)
+	router.HandleFunc("/users/register", h.account.RegisterHandler)
+	// Deprecated: /users/add is kept for existing clients and registers like /users/register
+	router.HandleFunc("/users/add", h.account.RegisterHandler)
+	router.HandleFunc("/users/verify", h.account.ConfirmEmailHandler)
+	router.HandleFunc("/users/verify/resend", h.account.ResendVerificationHandler)
+	router.HandleFunc("/users/password/forgot", h.account.ForgotPasswordHandler)
+	router.HandleFunc("/users/password/reset", h.account.ResetPasswordHandler)
+	router.HandleFunc("/users/update/{id}", h.user.UpdateUserHandler)
+	router.HandleFunc("/users/delete/{id}", h.user.DeleteUserHandler)
+	// Users are looked up by ID when the path holds one, and by username otherwise
+	router.HandleFunc("/users/{id:"+objectIDPattern+"}", h.user.FindUserByID)
+	router.HandleFunc("/users/{username}", h.user.FindUserByUsername)
+	router.HandleFunc("/users/{id}/password", h.account.ChangePasswordHandler)
+	router.HandleFunc("/users/{id}/sessions", h.session.GetUserSessionsHandler)
+	router.HandleFunc("/users/{id}/sessions/{sid}/revoke", h.session.RevokeSessionHandler)
+	router.HandleFunc("/users/{id}/export", h.privacy.ExportUserDataHandler)
+	router.HandleFunc("/users/{id}/close", h.privacy.CloseAccountHandler)
+	router.HandleFunc("/users/{id}/purchases", h.purchase.GetUserPurchasesHandler)
+	router.HandleFunc("/users/{id}/crops", h.crop.SetUserCropsHandler)
+	router.HandleFunc("/users/{id}/recommendations", h.crop.GetRecommendationsHandler)
+	router.HandleFunc("/users/{id}/plots", h.plot.GetUserPlotsHandler)
+	router.HandleFunc("/users/{id}/plots/add", h.plot.AddPlotHandler)
+	router.HandleFunc("/users/{id}/crop-areas", h.plot.GetCropAreasHandler)
+	router.HandleFunc("/users/{id}/addresses", h.address.GetUserAddressesHandler)
+	router.HandleFunc("/users/{id}/addresses/add", h.address.AddAddressHandler)
+	router.HandleFunc("/addresses/update/{id}", h.address.UpdateAddressHandler)
+	router.HandleFunc("/addresses/delete/{id}", h.address.DeleteAddressHandler)
+	router.HandleFunc("/addresses/{id}", h.address.GetAddressByIDHandler)
+	router.HandleFunc("/addresses/{id}/default", h.address.SetDefaultAddressHandler)
+	router.HandleFunc("/plots/update/{id}", h.plot.UpdatePlotHandler)
+	router.HandleFunc("/plots/delete/{id}", h.plot.DeletePlotHandler)
+	router.HandleFunc("/plots/{id}", h.plot.GetPlotByIDHandler)
+	// Add routes for other user-related endpoints as needed
+
+	// Define routes for shop-related endpoints
+	router.HandleFunc("/shops/nearby", h.shop.FindNearbyShopsHandler)
+	router.HandleFunc("/shops/viewport", h.shop.FindShopsInViewportHandler)
+	router.HandleFunc("/shops/delete/{id}", h.shop.DeleteShopHandler)
+	router.HandleFunc("/shops/{id}", h.shop.FindShopByIDHandler)
+	router.HandleFunc("/shops/{id}/delivery-quote", h.shop.DeliveryQuoteHandler)
+	router.HandleFunc("/shops/{id}/delivery-fees", h.shop.GetDeliveryFeesHandler)
+	router.HandleFunc("/shops/{id}/delivery-fees/update", h.shop.SetDeliveryFeesHandler)
+	router.HandleFunc("/shops/{id}/images", h.media.GetShopImagesHandler)
+	router.HandleFunc("/shops/{id}/images/upload", h.media.UploadShopImageHandler)
+	router.HandleFunc("/shops/{id}/products/{pid}/price", h.pricing.SetShopPriceHandler)
+	router.HandleFunc("/shops/{id}/products/{pid}/price-history", h.pricing.GetPriceHistoryHandler)
+	router.HandleFunc("/shops/{id}/products/{pid}/listing", h.serviceableProduct.SetShopListingHandler)
+	router.HandleFunc("/shops/{id}/products/{pid}/lots", h.inventory.GetLotsHandler)
+	router.HandleFunc("/shops/{id}/products/{pid}/lots/add", h.inventory.ReceiveLotHandler)
+	router.HandleFunc("/shops/{id}/products/{pid}/sales", h.inventory.RecordSaleHandler)
+	router.HandleFunc("/shops/{id}/near-expiry", h.inventory.FindNearExpiryLotsHandler)
+	router.HandleFunc("/shops/{id}/licences", h.licence.GetShopLicencesHandler)
+	router.HandleFunc("/shops/{id}/licences/add", h.licence.RegisterLicenceHandler)
+	router.HandleFunc("/shops/{id}/licences/delete/{lid}", h.licence.DeleteLicenceHandler)
+	// Add routes for other shop-related endpoints as needed
+
+	// Define routes for product-related endpoints
+	router.HandleFunc("/products", h.product.ListProductsHandler)
+	router.HandleFunc("/products/search", h.product.SearchProductsHandler)
+	router.HandleFunc("/products/import", h.catalog.ImportCatalogHandler)
+	router.HandleFunc("/products/export", h.catalog.ExportCatalogHandler)
+	router.HandleFunc("/products/delete/{id}", h.product.DeleteProductHandler)
+	router.HandleFunc("/products/{id}", h.product.GetProductByIDHandler)
+	router.HandleFunc("/products/{id}/status", h.product.SetProductStatusHandler)
+	router.HandleFunc("/products/{id}/unit-prices", h.product.GetVariantUnitPricesHandler)
+	router.HandleFunc("/products/{id}/nearby-shops", h.serviceableProduct.FindShopsSellingProductHandler)
+	router.HandleFunc("/products/{id}/related", h.recommendation.FindRelatedProductsHandler)
+	router.HandleFunc("/products/{id}/images", h.media.GetProductImagesHandler)
+	router.HandleFunc("/products/{id}/images/upload", h.media.UploadProductImageHandler)
+	// Add routes for other product-related endpoints as needed
+
+	// Define routes for image-related endpoints
+	router.HandleFunc("/images/delete/{id}", h.media.DeleteImageHandler)
+	router.HandleFunc("/images/{id}", h.media.ServeImageHandler)
+
+	// Define routes for admin endpoints
+	router.HandleFunc("/admin/products/restore/{id}", h.product.RestoreProductHandler)
+	router.HandleFunc("/admin/shops/restore/{id}", h.shop.RestoreShopHandler)
+	router.HandleFunc("/admin/users/restore/{id}", h.user.RestoreUserHandler)
+	router.HandleFunc("/admin/users/{id}/privacy-log", h.privacy.GetAuditLogHandler)
+	router.HandleFunc("/admin/users/{id}/role", h.user.SetUserRoleHandler)
+	router.HandleFunc("/admin/users/{id}/sign-out", h.session.SignOutEverywhereHandler)
+	router.HandleFunc("/admin/crop-rules", h.crop.GetCropRulesHandler)
+	router.HandleFunc("/admin/crop-rules/add", h.crop.AddCropRuleHandler)
+	router.HandleFunc("/admin/crop-rules/delete/{id}", h.crop.DeleteCropRuleHandler)
+
+	// Define routes for sign-in endpoints
+	router.HandleFunc("/auth/login", h.account.SignInHandler)
+	router.HandleFunc("/auth/otp/request", h.account.RequestOTPHandler)
+	router.HandleFunc("/auth/otp/verify", h.account.VerifyOTPHandler)
+	router.HandleFunc("/auth/refresh", h.session.RefreshHandler)
+	router.HandleFunc("/auth/sign-out", h.session.SignOutHandler)
+
+	// Define routes for purchase-related endpoints
+	router.HandleFunc("/purchases/add", h.purchase.PlacePurchaseHandler)
+	router.HandleFunc("/purchases/{id}", h.purchase.GetPurchaseByIDHandler)
+
+	// Define routes for licence-related endpoints
+	router.HandleFunc("/licences/expiring", h.licence.FindExpiringLicencesHandler)
+
+	// Define routes for category-related endpoints
+	router.HandleFunc("/categories", h.category.GetCategoriesHandler)
+	router.HandleFunc("/categories/add", h.category.CreateCategoryHandler)
+	router.HandleFunc("/categories/update/{id}", h.category.UpdateCategoryHandler)
+	router.HandleFunc("/categories/{id}", h.category.GetCategoryByIDHandler)
+
+	// Define routes for serviceable product-related endpoints
+	router.HandleFunc("/serviceable-products", h.serviceableProduct.FindServiceableProductsHandler)
+	// Add routes for other serviceable product-related endpoints as needed
+
+	return router
+}
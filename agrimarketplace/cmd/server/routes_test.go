@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+const sampleID = "650c1f3e9a1b2c3d4e5f6a7b"
+
+func TestRoutesMatch(t *testing.T) {
+	router := newRouter(handlers{})
+
+	tests := []struct {
+		path     string
+		template string
+		vars     map[string]string
+	}{
+		{"/users/nearby", "/users/nearby", map[string]string{}},
+		{"/users/register", "/users/register", map[string]string{}},
+		{"/users/add", "/users/add", map[string]string{}},
+		{"/users/" + sampleID, "/users/{id:" + objectIDPattern + "}", map[string]string{"id": sampleID}},
+		{"/users/ravi", "/users/{username}", map[string]string{"username": "ravi"}},
+		{"/users/update/" + sampleID, "/users/update/{id}", map[string]string{"id": sampleID}},
+		{"/users/" + sampleID + "/sessions/s1/revoke", "/users/{id}/sessions/{sid}/revoke", map[string]string{"id": sampleID, "sid": "s1"}},
+		{"/addresses/update/" + sampleID, "/addresses/update/{id}", map[string]string{"id": sampleID}},
+		{"/addresses/" + sampleID, "/addresses/{id}", map[string]string{"id": sampleID}},
+		{"/shops/nearby", "/shops/nearby", map[string]string{}},
+		{"/shops/" + sampleID, "/shops/{id}", map[string]string{"id": sampleID}},
+		{"/shops/" + sampleID + "/products/p1/price", "/shops/{id}/products/{pid}/price", map[string]string{"id": sampleID, "pid": "p1"}},
+		{"/products", "/products", map[string]string{}},
+		{"/products/search", "/products/search", map[string]string{}},
+		{"/products/export", "/products/export", map[string]string{}},
+		{"/products/" + sampleID, "/products/{id}", map[string]string{"id": sampleID}},
+		{"/auth/login", "/auth/login", map[string]string{}},
+		{"/purchases/add", "/purchases/add", map[string]string{}},
+		{"/purchases/" + sampleID, "/purchases/{id}", map[string]string{"id": sampleID}},
+		{"/categories/add", "/categories/add", map[string]string{}},
+		{"/categories/" + sampleID, "/categories/{id}", map[string]string{"id": sampleID}},
+		{"/admin/users/" + sampleID + "/role", "/admin/users/{id}/role", map[string]string{"id": sampleID}},
+	}
+
+	for _, tt := range tests {
+		var match mux.RouteMatch
+		if !router.Match(httptest.NewRequest("GET", tt.path, nil), &match) {
+			t.Errorf("%s: no route matched", tt.path)
+			continue
+		}
+		template, _ := match.Route.GetPathTemplate()
+		if template != tt.template {
+			t.Errorf("%s: matched %s, want %s", tt.path, template, tt.template)
+		}
+		if !reflect.DeepEqual(match.Vars, tt.vars) {
+			t.Errorf("%s: vars %v, want %v", tt.path, match.Vars, tt.vars)
+		}
+	}
+}
+
+// TestRoutesReachable checks that no route is shadowed by one added before it.
+func TestRoutesReachable(t *testing.T) {
+	router := newRouter(handlers{})
+
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		path := samplePath(template)
+
+		var match mux.RouteMatch
+		if !router.Match(httptest.NewRequest("GET", path, nil), &match) {
+			t.Errorf("%s: no route matched %s", template, path)
+			return nil
+		}
+		if matched, _ := match.Route.GetPathTemplate(); matched != template {
+			t.Errorf("%s: %s is routed to %s", template, path, matched)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// samplePath fills the variables of a route template with values the route accepts.
+func samplePath(template string) string {
+	var path strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			path.WriteString(template)
+			return path.String()
+		}
+		path.WriteString(template[:start])
+
+		// Variable patterns may contain braces of their own
+		depth, end := 0, start
+		for ; end < len(template); end++ {
+			if template[end] == '{' {
+				depth++
+			} else if template[end] == '}' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+		name := strings.SplitN(template[start+1:end], ":", 2)[0]
+		if name == "username" {
+			path.WriteString("ravi")
+		} else {
+			path.WriteString(sampleID)
+		}
+		template = template[end+1:]
+	}
+}
@@ -0,0 +1,14 @@
+// Package mail provides the delivery of email messages to users.
+package mail
+
+// Message represents an email message.
+type Message struct {
+	To      string
+	Subject string
+	Body    string // Plain text
+}
+
+// Mailer defines the interface for sending email messages.
+type Mailer interface {
+	Send(message Message) error
+}
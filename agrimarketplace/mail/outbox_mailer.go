@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// outboxMailer is an implementation of the Mailer interface that writes messages to a directory
+// instead of sending them, for local runs.
+type outboxMailer struct {
+	dir  string
+	from string
+}
+
+// NewOutboxMailer creates a Mailer that writes each message as an .eml file in the directory.
+func NewOutboxMailer(dir, from string) (Mailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &outboxMailer{
+		dir:  dir,
+		from: from,
+	}, nil
+}
+
+// Send writes a message to the outbox.
+func (m *outboxMailer) Send(message Message) error {
+	now := time.Now()
+
+	file, err := os.CreateTemp(m.dir, now.Format("20060102-150405")+"-*.eml")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(file, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		m.from, message.To, message.Subject, now.Format(time.RFC1123Z), message.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return nil
+}
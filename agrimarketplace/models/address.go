@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostalAddress represents a place deliveries can be made to.
+type PostalAddress struct {
+	Line1      string  `bson:"line1"`
+	Line2      string  `bson:"line2,omitempty"`
+	Locality   string  `bson:"locality,omitempty"` // Village, town or city
+	District   string  `bson:"district,omitempty"`
+	State      string  `bson:"state,omitempty"`
+	PostalCode string  `bson:"postal_code,omitempty"` // Six-digit PIN code
+	Landmark   string  `bson:"landmark,omitempty"`    // Helps drivers find places without street addresses
+	Latitude   float64 `bson:"latitude"`
+	Longitude  float64 `bson:"longitude"`
+}
+
+// Address represents an address saved in a user's address book, such as their house,
+// their field or a cooperative godown.
+type Address struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	UserID        primitive.ObjectID `bson:"user_id"`
+	Label         string             `bson:"label"`
+	IsDefault     bool               `bson:"is_default"` // At most one address per user is the default
+	CreatedAt     time.Time          `bson:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at"`
+	PostalAddress `bson:",inline"`
+}
+
+// AddressSnapshot represents an address as it was when a purchase was placed.
+// It is copied into the purchase, so later edits to the address book leave the purchase unchanged.
+type AddressSnapshot struct {
+	AddressID     primitive.ObjectID `bson:"address_id"`
+	Label         string             `bson:"label"`
+	PostalAddress `bson:",inline"`
+}
@@ -0,0 +1,27 @@
+package models
+
+// Actions taken for a row of a catalog import.
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionError  = "error"
+)
+
+// ImportReport represents the outcome of a bulk catalog import.
+// In a dry run the actions describe what would have happened and nothing is written.
+type ImportReport struct {
+	DryRun  bool              `bson:"dry_run"`
+	Total   int               `bson:"total"`
+	Created int               `bson:"created"`
+	Updated int               `bson:"updated"`
+	Failed  int               `bson:"failed"`
+	Rows    []ImportRowResult `bson:"rows"`
+}
+
+// ImportRowResult represents the outcome of a single row of a catalog import.
+type ImportRowResult struct {
+	Row    int      `bson:"row"` // 1-based line number of the record, counting the CSV header
+	SKU    string   `bson:"sku"`
+	Action string   `bson:"action"`
+	Errors []string `bson:"errors,omitempty"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Attribute types supported in category attribute schemas.
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeInteger = "integer"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeEnum    = "enum"
+)
+
+// Category represents a product category in the MongoDB database.
+type Category struct {
+	ID         primitive.ObjectID    `bson:"_id,omitempty"`
+	Name       string                `bson:"name"`
+	Attributes []AttributeDefinition `bson:"attributes"` // Schema of the attributes of products in this category
+	// RequiredLicence is the licence type shops need to sell products of this category, if any.
+	RequiredLicence string `bson:"required_licence,omitempty"`
+}
+
+// AttributeDefinition describes a typed attribute that products of a category carry,
+// such as the germination percentage of seeds or the NPK ratio of fertilizers.
+type AttributeDefinition struct {
+	Name     string   `bson:"name"`
+	Label    string   `bson:"label"`
+	Type     string   `bson:"type"`
+	Unit     string   `bson:"unit,omitempty"`
+	Required bool     `bson:"required"`
+	Enum     []string `bson:"enum,omitempty"`    // Allowed values of enum attributes
+	Min      *float64 `bson:"min,omitempty"`     // Lower bound of number and integer attributes
+	Max      *float64 `bson:"max,omitempty"`     // Upper bound of number and integer attributes
+	Pattern  string   `bson:"pattern,omitempty"` // Regular expression that string attributes must match
+}
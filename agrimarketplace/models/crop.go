@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Indian cropping seasons.
+const (
+	SeasonKharif = "kharif" // Monsoon crops, sown June to September
+	SeasonRabi   = "rabi"   // Winter crops, sown October to February
+	SeasonZaid   = "zaid"   // Summer crops, sown March to May
+)
+
+// CropPlanting represents a crop grown by a farmer in a season.
+type CropPlanting struct {
+	Crop       string    `bson:"crop"`
+	Season     string    `bson:"season"`
+	SownArea   float64   `bson:"sown_area"` // In acres
+	SowingDate time.Time `bson:"sowing_date"`
+}
+
+// SeasonOf returns the cropping season in which a crop sown on the given date is grown.
+func SeasonOf(sowingDate time.Time) string {
+	switch month := sowingDate.Month(); {
+	case month >= time.June && month <= time.September:
+		return SeasonKharif
+	case month >= time.March && month <= time.May:
+		return SeasonZaid
+	default:
+		return SeasonRabi
+	}
+}
+
+// CropRule maps a growth stage of a crop to the products it needs.
+// The stage spans a range of days counted from sowing; negative days fall before sowing,
+// such as land preparation. Products match when they belong to one of the categories
+// and have all the attribute values.
+type CropRule struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty"`
+	Crop        string               `bson:"crop"`
+	Season      string               `bson:"season,omitempty"` // Empty when the rule applies in every season
+	Stage       string               `bson:"stage"`
+	StartDay    int                  `bson:"start_day"`
+	EndDay      int                  `bson:"end_day"`
+	CategoryIDs []primitive.ObjectID `bson:"category_ids,omitempty"`
+	Attributes  map[string]string    `bson:"attributes,omitempty"`
+}
+
+// CropRecommendation represents the products recommended for an upcoming or current growth stage of a farmer's crop.
+type CropRecommendation struct {
+	Crop     string    `bson:"crop"`
+	Season   string    `bson:"season"`
+	Stage    string    `bson:"stage"`
+	StartsOn time.Time `bson:"starts_on"`
+	EndsOn   time.Time `bson:"ends_on"`
+	Current  bool      `bson:"current"`
+	Products []Product `bson:"products"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeliveryFeeSlab charges FeePerKm for every kilometre travelled up to UpToKm.
+type DeliveryFeeSlab struct {
+	UpToKm   float64 `bson:"up_to_km"`
+	FeePerKm float64 `bson:"fee_per_km"`
+}
+
+// DeliveryFeeSchedule represents the delivery pricing configured for a shop.
+type DeliveryFeeSchedule struct {
+	ID                    primitive.ObjectID `bson:"_id,omitempty"`
+	ShopID                primitive.ObjectID `bson:"shop_id"`
+	BaseFee               float64            `bson:"base_fee"`
+	Slabs                 []DeliveryFeeSlab  `bson:"slabs"`
+	FreeDeliveryThreshold float64            `bson:"free_delivery_threshold"` // Order amount from which delivery is free, 0 disables it
+	MaxDistanceKm         float64            `bson:"max_distance_km"`         // 0 means no limit
+	AverageSpeedKmph      float64            `bson:"average_speed_kmph"`
+	PreparationMinutes    int                `bson:"preparation_minutes"`
+}
+
+// DeliveryQuote represents the estimated cost and arrival time of a delivery from a shop.
+type DeliveryQuote struct {
+	ShopID           primitive.ObjectID `bson:"shop_id"`
+	Deliverable      bool               `bson:"deliverable"`
+	DistanceKm       float64            `bson:"distance_km"`
+	Fee              float64            `bson:"fee"`
+	FreeDelivery     bool               `bson:"free_delivery"`
+	DispatchAt       time.Time          `bson:"dispatch_at"`
+	EstimatedArrival time.Time          `bson:"estimated_arrival"`
+	DurationMinutes  int                `bson:"duration_minutes"`
+	HoursUnknown     bool               `bson:"hours_unknown"` // The shop's operating hours could not be read, so they were not taken into account
+}
+
+// NearbyShop represents a shop returned by a nearby search together with its delivery quote.
+type NearbyShop struct {
+	Shop
+	DeliveryQuote *DeliveryQuote `bson:"delivery_quote,omitempty"`
+}
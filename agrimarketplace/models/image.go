@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Owners that images can be attached to.
+const (
+	ImageOwnerProduct = "product"
+	ImageOwnerShop    = "shop"
+)
+
+// Image represents an uploaded image and its thumbnail in the MongoDB database.
+// The image content itself is kept in a blob store.
+type Image struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerType       string             `bson:"owner_type"`
+	OwnerID         primitive.ObjectID `bson:"owner_id"`
+	ContentType     string             `bson:"content_type"`
+	Size            int64              `bson:"size"`
+	Width           int                `bson:"width"`
+	Height          int                `bson:"height"`
+	Key             string             `bson:"key"`
+	ThumbnailKey    string             `bson:"thumbnail_key"`
+	ThumbnailType   string             `bson:"thumbnail_type"` // Content type of the thumbnail
+	ThumbnailWidth  int                `bson:"thumbnail_width"`
+	ThumbnailHeight int                `bson:"thumbnail_height"`
+	CreatedAt       time.Time          `bson:"created_at"`
+}
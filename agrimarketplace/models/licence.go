@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Licence types issued by state agriculture departments to dealers of regulated agri inputs.
+const (
+	LicenceTypeSeed       = "seed"       // Seed dealer licence under the Seeds (Control) Order
+	LicenceTypeFertilizer = "fertilizer" // Fertilizer dealer registration under the Fertiliser (Control) Order
+	LicenceTypePesticide  = "pesticide"  // Licence to sell insecticides under the Insecticides Rules
+)
+
+// ShopLicence represents a licence held by a shop to sell a type of regulated product.
+type ShopLicence struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	ShopID        primitive.ObjectID `bson:"shop_id"`
+	LicenceType   string             `bson:"licence_type"`
+	LicenceNumber string             `bson:"licence_number"`
+	IssuingState  string             `bson:"issuing_state"`
+	ValidFrom     time.Time          `bson:"valid_from"`
+	ValidUntil    time.Time          `bson:"valid_until"` // Last moment the licence is valid
+	CreatedAt     time.Time          `bson:"created_at"`
+}
+
+// ValidAt reports whether the licence is in force at the given time.
+func (l ShopLicence) ValidAt(t time.Time) bool {
+	return !t.Before(l.ValidFrom) && !t.After(l.ValidUntil)
+}
+
+// ExpiringLicence represents a licence in the expiring-licences report.
+type ExpiringLicence struct {
+	ShopLicence   `bson:",inline"`
+	ShopName      string `bson:"shop_name"`
+	DaysRemaining int    `bson:"days_remaining"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// Lifecycle states of a catalog product.
+// Products saved before states were introduced have no status and are treated as active.
+const (
+	ProductStatusDraft        = "draft"        // Being prepared; hidden from the catalog
+	ProductStatusActive       = "active"       // Listed and sold
+	ProductStatusDiscontinued = "discontinued" // Still listed, but no longer restocked by the manufacturer
+	ProductStatusArchived     = "archived"     // Hidden from the catalog; kept for past orders and reviews
+)
+
+// IsListed reports whether the product is shown in catalog listings and searches.
+func (p *Product) IsListed() bool {
+	return p.Status != ProductStatusDraft && p.Status != ProductStatusArchived
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OTPChallenge represents the one-time password last sent to a phone number, with the
+// counters used to limit guesses and resends.
+type OTPChallenge struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	Phone           string             `bson:"phone"`
+	CodeHash        string             `bson:"code_hash"` // Keyed hash of the code; the code itself is never stored
+	ExpiresAt       time.Time          `bson:"expires_at"`
+	Attempts        int                `bson:"attempts"` // Verification attempts made with the current code
+	SentAt          time.Time          `bson:"sent_at"`
+	WindowStartedAt time.Time          `bson:"window_started_at"` // Start of the period in which sends are counted
+	SendCount       int                `bson:"send_count"`
+	PurgeAt         time.Time          `bson:"purge_at"` // When the challenge and its counters are no longer needed
+}
+
+// Login represents a successful sign-in or session refresh, with the short-lived token authenticating
+// the user's requests and the refresh token exchanged for new tokens when it expires.
+type Login struct {
+	User             *User
+	SessionID        primitive.ObjectID
+	AccessToken      string
+	ExpiresAt        time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+	Registered       bool // Whether the user was created by this sign-in
+}
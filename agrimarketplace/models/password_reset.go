@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Channels through which password reset tokens are delivered.
+const (
+	ResetChannelEmail = "email"
+	ResetChannelSMS   = "sms"
+)
+
+// PasswordReset represents a password reset token sent to a user.
+// Tokens are single-use and only valid while the user's token version is unchanged.
+type PasswordReset struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	UserID       primitive.ObjectID `bson:"user_id"`
+	TokenHash    string             `bson:"token_hash"` // Keyed hash of the token; the token itself is never stored
+	TokenVersion int                `bson:"token_version"`
+	Channel      string             `bson:"channel"`
+	CreatedAt    time.Time          `bson:"created_at"`
+	ExpiresAt    time.Time          `bson:"expires_at"`
+	UsedAt       *time.Time         `bson:"used_at,omitempty"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Soil types of Indian farmland.
+const (
+	SoilAlluvial = "alluvial"
+	SoilBlack    = "black" // Regur, suited to cotton
+	SoilRed      = "red"
+	SoilLaterite = "laterite"
+	SoilArid     = "arid"
+	SoilSaline   = "saline"
+	SoilPeaty    = "peaty"
+	SoilForest   = "forest"
+)
+
+// Sources of irrigation of a plot.
+const (
+	IrrigationRainfed   = "rainfed"
+	IrrigationCanal     = "canal"
+	IrrigationTubewell  = "tubewell"
+	IrrigationWell      = "well"
+	IrrigationTank      = "tank"
+	IrrigationDrip      = "drip"
+	IrrigationSprinkler = "sprinkler"
+)
+
+// GeoPolygon represents a GeoJSON polygon. The first ring is the boundary and any further
+// rings are holes; each ring is a closed list of [longitude, latitude] positions.
+type GeoPolygon struct {
+	Type        string        `bson:"type"` // Always "Polygon"
+	Coordinates [][][]float64 `bson:"coordinates"`
+}
+
+// Plot represents a plot of land held by a farmer.
+type Plot struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty"`
+	UserID           primitive.ObjectID `bson:"user_id"`
+	Name             string             `bson:"name"`
+	Boundary         GeoPolygon         `bson:"boundary"`
+	AreaHectares     float64            `bson:"area_hectares"` // Computed from the boundary
+	AreaAcres        float64            `bson:"area_acres"`    // Computed from the boundary
+	SoilType         string             `bson:"soil_type,omitempty"`
+	IrrigationSource string             `bson:"irrigation_source,omitempty"`
+	CurrentCrop      string             `bson:"current_crop,omitempty"` // Empty when the plot is fallow
+	CreatedAt        time.Time          `bson:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at"`
+}
+
+// CropArea represents the area a farmer cultivates with a crop, over all their plots.
+type CropArea struct {
+	Crop         string  `bson:"_id"`
+	Plots        int     `bson:"plots"`
+	AreaHectares float64 `bson:"area_hectares"`
+	AreaAcres    float64 `bson:"area_acres"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PriceChange represents a change of a shop's selling price for a product.
+type PriceChange struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	ShopID    primitive.ObjectID `bson:"shop_id"`
+	ProductID primitive.ObjectID `bson:"product_id"`
+	OldPrice  float64            `bson:"old_price"` // 0 when the catalog price applied before the change
+	NewPrice  float64            `bson:"new_price"`
+	ChangedBy primitive.ObjectID `bson:"changed_by"`
+	ChangedAt time.Time          `bson:"changed_at"`
+}
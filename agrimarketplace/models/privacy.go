@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions taken on a user's personal data.
+const (
+	PrivacyActionExport  = "export"
+	PrivacyActionClosure = "closure"
+)
+
+// PrivacyAuditEntry records an action taken on a user's personal data, whether or not it succeeded.
+type PrivacyAuditEntry struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `bson:"user_id"`
+	Action      string             `bson:"action"`
+	RequesterID primitive.ObjectID `bson:"requester_id"` // The user or admin who made the request
+	Requester   string             `bson:"requester"`    // Network address the request came from
+	Succeeded   bool               `bson:"succeeded"`
+	Detail      string             `bson:"detail,omitempty"` // Why the action failed, or what it changed
+	At          time.Time          `bson:"at"`
+}
+
+// UserDataExport holds the personal data kept about a user and the records related to them.
+type UserDataExport struct {
+	User        User
+	Addresses   []Address
+	Plots       []Plot
+	Purchases   []Purchase
+	Shops       []Shop
+	GeneratedAt time.Time
+}
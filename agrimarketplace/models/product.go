@@ -1,15 +1,52 @@
 package models
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Product represents a product in the MongoDB database.
 type Product struct {
 	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	SKU           string             `bson:"sku,omitempty"` // Stable catalog key used by bulk imports
 	ProductName   string             `bson:"product_name"`
 	Description   string             `bson:"description"`
 	CategoryID    primitive.ObjectID `bson:"category_id"`
+	Brand         string             `bson:"brand"`
 	Price         float64            `bson:"price"`
 	StockQuantity int                `bson:"stock_quantity"`
+	Rating        float64            `bson:"rating"`     // Average customer rating out of 5
+	Popularity    int                `bson:"popularity"` // Number of units sold
+	CreatedAt     time.Time          `bson:"created_at"`
+	Status        string             `bson:"status"`               // Lifecycle state; one of the ProductStatus values
+	DeletedAt     *time.Time         `bson:"deleted_at,omitempty"` // Set when soft-deleted; purged after the retention window
+	// Attributes holds the category-specific attribute values, validated against the category schema.
+	Attributes map[string]interface{} `bson:"attributes,omitempty"`
+	Variants   []ProductVariant       `bson:"variants,omitempty"`
+	// RequiredLicence is the licence type shops need to sell this product, overriding the category's.
+	RequiredLicence string `bson:"required_licence,omitempty"`
+}
+
+// ProductVariant represents a sellable pack of a product, such as a 45 kg bag or a 250 ml bottle.
+type ProductVariant struct {
+	SKU      string  `bson:"sku"`
+	PackSize float64 `bson:"pack_size"`
+	Unit     string  `bson:"unit"` // One of g, kg, quintal, ml, L or piece
+	Price    float64 `bson:"price"`
+	Barcode  string  `bson:"barcode,omitempty"` // EAN-8, UPC-A or EAN-13
+}
+
+// VariantUnitPrice represents the price of a product variant normalized to a common unit,
+// so that packs of different sizes can be compared.
+type VariantUnitPrice struct {
+	ProductVariant `bson:",inline"`
+	UnitPrice      float64 `bson:"unit_price"`
+	PerUnit        string  `bson:"per_unit"`
+}
+
+// ProductSearchResult represents a product matched by a text search and its relevance score.
+type ProductSearchResult struct {
+	Product `bson:",inline"`
+	Score   float64 `bson:"score"`
 }
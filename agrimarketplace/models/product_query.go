@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Sort orders supported when listing the product catalog.
+const (
+	ProductSortPriceAsc   = "price_asc"
+	ProductSortPriceDesc  = "price_desc"
+	ProductSortPopularity = "popularity"
+	ProductSortNewest     = "newest"
+)
+
+// ProductQuery represents the filters, sort order and page requested when listing the product catalog.
+type ProductQuery struct {
+	CategoryIDs []primitive.ObjectID
+	Brands      []string
+	MinPrice    *float64
+	MaxPrice    *float64
+	MinRating   *float64
+	// Attributes filters on category attribute values, e.g. {"variety": "IR-64"}.
+	Attributes map[string]string
+	// AttributeMin and AttributeMax bound numeric category attributes.
+	AttributeMin map[string]float64
+	AttributeMax map[string]float64
+	// InStockNear restricts the catalog to products held in stock by a shop near a location.
+	InStockNear *GeoFilter
+	// ProductIDs restricts the catalog to the given products when non-nil.
+	ProductIDs []primitive.ObjectID
+	// Statuses restricts the catalog to products in the given lifecycle states.
+	// By default draft and archived products are left out.
+	Statuses []string
+	Sort     string
+	// Cursor is the opaque position returned as NextCursor by the previous page.
+	Cursor string
+	// After is the decoded Cursor.
+	After *ProductCursor
+	Limit int
+}
+
+// GeoFilter represents a circle around a location.
+type GeoFilter struct {
+	Latitude       float64
+	Longitude      float64
+	RadiusInMeters float64
+}
+
+// ProductCursor represents the position of the last product of a page in the requested sort order.
+type ProductCursor struct {
+	Sort   string             `json:"s"`
+	ID     primitive.ObjectID `json:"i"`
+	Number float64            `json:"n,omitempty"` // Sort value for price and popularity
+	Time   time.Time          `json:"t,omitempty"` // Sort value for newest
+}
+
+// ProductPage represents a page of the product catalog with facet counts for the whole result.
+type ProductPage struct {
+	Products   []Product     `bson:"products"`
+	NextCursor string        `bson:"next_cursor"`
+	HasMore    bool          `bson:"has_more"`
+	Facets     ProductFacets `bson:"facets"`
+}
+
+// ProductFacets holds the number of products for each value of each filter.
+// Each facet is counted with every filter applied except its own, so that
+// the counts describe what selecting another value would return.
+type ProductFacets struct {
+	Categories  []FacetCount `bson:"categories"`
+	Brands      []FacetCount `bson:"brands"`
+	PriceRanges []FacetCount `bson:"price_ranges"`
+	Ratings     []FacetCount `bson:"ratings"`
+}
+
+// FacetCount represents the number of products having a filter value.
+type FacetCount struct {
+	Value string `bson:"value"`
+	Count int    `bson:"count"`
+}
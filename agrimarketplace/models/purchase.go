@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Purchase represents an order placed by a user with a shop.
+// It is delivered to the address chosen from the user's address book, or to their default address.
+type Purchase struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	UserID          primitive.ObjectID `bson:"user_id"`
+	ShopID          primitive.ObjectID `bson:"shop_id"`
+	AddressID       primitive.ObjectID `bson:"-"`                          // Address chosen when placing the purchase
+	DeliveryAddress *AddressSnapshot   `bson:"delivery_address,omitempty"` // Empty when the user had no address to deliver to
+	Lines           []PurchaseLine     `bson:"lines"`
+	Total           float64            `bson:"total"`
+	PurchasedAt     time.Time          `bson:"purchased_at"`
+}
+
+// PurchaseLine represents a product bought in a purchase, at the shop's price at the time.
+type PurchaseLine struct {
+	ProductID   primitive.ObjectID `bson:"product_id"`
+	Quantity    int                `bson:"quantity"`
+	UnitPrice   float64            `bson:"unit_price"`
+	Allocations []LotAllocation    `bson:"allocations,omitempty"` // Lots the quantity was taken from, for lot-tracked stock
+}
+
+// ProductCooccurrence represents how many purchases contained both a product and a related product.
+// The pair of a product with itself counts the purchases that contained the product.
+type ProductCooccurrence struct {
+	ProductID primitive.ObjectID `bson:"product_id"`
+	RelatedID primitive.ObjectID `bson:"related_id"`
+	Count     int                `bson:"count"`
+}
+
+// RelatedProduct represents a product frequently bought together with another one.
+type RelatedProduct struct {
+	Product             `bson:",inline"`
+	TimesBoughtTogether int     `bson:"times_bought_together"`
+	Score               float64 `bson:"score"` // Association strength, from 0 to 1
+}
@@ -8,4 +8,24 @@ type ServiceableProduct struct {
 	ProductID     primitive.ObjectID `bson:"product_id"`
 	ShopID        primitive.ObjectID `bson:"shop_id"`
 	IsServiceable bool               `bson:"is_serviceable"`
+	StockQuantity int                `bson:"stock_quantity"`        // Quantity held by the shop
+	Price         float64            `bson:"price,omitempty"`       // Shop selling price, 0 means the catalog price applies
+	LotTracked    bool               `bson:"lot_tracked,omitempty"` // Stock is held in lots and StockQuantity counts their unexpired quantity
+}
+
+// ProductAvailability represents a shop that currently sells a product near a location.
+type ProductAvailability struct {
+	Shop              Shop               `bson:"shop"`
+	ProductID         primitive.ObjectID `bson:"product_id"`
+	Price             float64            `bson:"price"` // Effective shop selling price
+	AvailableQuantity int                `bson:"available_quantity"`
+	DistanceMeters    float64            `bson:"distance_meters"`
+}
+
+// EffectivePrice returns the shop selling price, or the catalog price when the shop has not set one.
+func (p ServiceableProduct) EffectivePrice(catalogPrice float64) float64 {
+	if p.Price > 0 {
+		return p.Price
+	}
+	return catalogPrice
 }
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reasons a session was revoked.
+const (
+	SessionRevokedSignOut         = "sign_out"
+	SessionRevokedByUser          = "revoked"
+	SessionRevokedByAdmin         = "signed_out_everywhere"
+	SessionRevokedTokenReuse      = "token_reuse"
+	SessionRevokedPasswordChanged = "password_changed"
+)
+
+// DeviceInfo describes the device a session was started on.
+type DeviceInfo struct {
+	Name       string `bson:"name,omitempty"`     // Given by the app, such as the phone model
+	Platform   string `bson:"platform,omitempty"` // Such as "android" or "ios"
+	AppVersion string `bson:"app_version,omitempty"`
+	UserAgent  string `bson:"user_agent,omitempty"`
+	IPAddress  string `bson:"ip_address,omitempty"` // Address of the last request refreshing the session
+}
+
+// Session represents a user signed in on a device. The session is kept alive by a refresh token
+// that is replaced every time it is used; the tokens it replaced make up the session's token family.
+// Presenting a replaced token again means it was stolen, and revokes the session.
+type Session struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	UserID        primitive.ObjectID `bson:"user_id"`
+	TokenHash     string             `bson:"token_hash"`               // Keyed hash of the current refresh token
+	RotatedHashes []string           `bson:"rotated_hashes,omitempty"` // Hashes of the refresh tokens already replaced
+	Device        DeviceInfo         `bson:"device"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	LastUsedAt    time.Time          `bson:"last_used_at"`
+	ExpiresAt     time.Time          `bson:"expires_at"` // Pushed back every time the session is refreshed
+	RevokedAt     *time.Time         `bson:"revoked_at,omitempty"`
+	RevokedReason string             `bson:"revoked_reason,omitempty"`
+}
@@ -1,9 +1,25 @@
 package models
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// GeoPoint represents a GeoJSON point, positioned as [longitude, latitude].
+type GeoPoint struct {
+	Type        string    `bson:"type"` // Always "Point"
+	Coordinates []float64 `bson:"coordinates"`
+}
+
+// NewGeoPoint creates the GeoJSON point of a latitude and longitude.
+func NewGeoPoint(latitude, longitude float64) *GeoPoint {
+	return &GeoPoint{
+		Type:        "Point",
+		Coordinates: []float64{longitude, latitude},
+	}
+}
+
 // Shop represents a shop in the MongoDB database.
 type Shop struct {
 	ID             primitive.ObjectID `bson:"_id,omitempty"`
@@ -13,4 +29,6 @@ type Shop struct {
 	OperatingHours string             `bson:"operating_hours"`
 	Latitude       float64            `bson:"latitude"`
 	Longitude      float64            `bson:"longitude"`
+	Position       *GeoPoint          `bson:"position,omitempty"`   // Kept from Latitude and Longitude for geospatial queries
+	DeletedAt      *time.Time         `bson:"deleted_at,omitempty"` // Set when soft-deleted; purged after the retention window
 }
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StockLot represents a batch of a product held by a shop, as received from the manufacturer.
+type StockLot struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	ShopID         primitive.ObjectID `bson:"shop_id"`
+	ProductID      primitive.ObjectID `bson:"product_id"`
+	LotNumber      string             `bson:"lot_number"` // Batch number printed on the pack
+	ManufacturedAt time.Time          `bson:"manufactured_at"`
+	ExpiresAt      time.Time          `bson:"expires_at"` // The lot may not be sold from this moment on
+	Quantity       int                `bson:"quantity"`   // Quantity remaining in the shop
+	ReceivedAt     time.Time          `bson:"received_at"`
+}
+
+// ExpiredAt reports whether the lot is expired at the given time.
+func (l StockLot) ExpiredAt(t time.Time) bool {
+	return !t.Before(l.ExpiresAt)
+}
+
+// LotAllocation represents the quantity of a sale taken from a lot.
+type LotAllocation struct {
+	LotID     primitive.ObjectID `bson:"lot_id"`
+	LotNumber string             `bson:"lot_number"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	Quantity  int                `bson:"quantity"`
+}
+
+// NearExpiryLot represents a lot in a shop's near-expiry report.
+type NearExpiryLot struct {
+	StockLot      `bson:",inline"`
+	ProductName   string `bson:"product_name"`
+	DaysRemaining int    `bson:"days_remaining"` // Zero or negative once the lot has expired
+	Expired       bool   `bson:"expired"`
+}
@@ -1,18 +1,75 @@
 package models
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Account states of a user.
+const (
+	UserStatusPending = "pending" // Registered by email, which is not yet verified
+	UserStatusActive  = "active"
+	UserStatusClosed  = "closed" // Closed at the user's request; personal data is anonymized
+)
+
+// Roles granting access beyond a user's own data. Users without a role are farmers and shop owners.
+const (
+	RoleAdmin      = "admin"
+	RoleFieldAgent = "field_agent" // Extension officers and agronomists who visit farmers
+)
+
 // User represents a user in the MongoDB database.
 type User struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	Username     string             `bson:"username"`
+	Password     string             `bson:"password"`      // Hashed password
+	TokenVersion int                `bson:"token_version"` // Carried by the user's tokens; bumped to revoke them all
+	Email        string             `bson:"email"`
+	Phone        string             `bson:"phone,omitempty"` // E.164 format, verified by one-time password
+	FirstName    string             `bson:"first_name"`
+	LastName     string             `bson:"last_name"`
+	Location     string             `bson:"location"`
+	Latitude     float64            `bson:"latitude"`
+	Longitude    float64            `bson:"longitude"`
+	Crops        []CropPlanting     `bson:"crops,omitempty"`
+	Role         string             `bson:"role,omitempty"`
+	Discoverable bool               `bson:"discoverable"`          // Opted in to being found by nearby-users searches
+	Status       string             `bson:"status,omitempty"`      // Accounts created before email verification have no status and are active
+	VerifiedAt   *time.Time         `bson:"verified_at,omitempty"` // When the email address or phone number was verified
+	ClosedAt     *time.Time         `bson:"closed_at,omitempty"`
+	DeletedAt    *time.Time         `bson:"deleted_at,omitempty"` // Set when soft-deleted; purged after the retention window
+}
+
+// PublicUser represents the profile of a user shown to other users.
+type PublicUser struct {
+	ID        primitive.ObjectID `bson:"_id"`
 	Username  string             `bson:"username"`
-	Password  string             `bson:"password"` // Hashed password
-	Email     string             `bson:"email"`
 	FirstName string             `bson:"first_name"`
-	LastName  string             `bson:"last_name"`
-	Location  string             `bson:"location"`
-	Latitude  float64            `bson:"latitude"`
-	Longitude float64            `bson:"longitude"`
+}
+
+// NearbyUser represents a user found near a point, located only approximately.
+type NearbyUser struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Username  string             `bson:"username"`
+	FirstName string             `bson:"first_name"`
+	Geohash   string             `bson:"geohash"`   // Cell containing the user's location
+	Latitude  float64            `bson:"latitude"`  // Centre of the cell
+	Longitude float64            `bson:"longitude"` // Centre of the cell
+	Distance  string             `bson:"distance"`  // Approximate distance from the point, such as "1-5 km"
+}
+
+// PublicProfile returns the part of the user's profile shown to other users.
+func (u *User) PublicProfile() PublicUser {
+	return PublicUser{ID: u.ID, Username: u.Username, FirstName: u.FirstName}
+}
+
+// IsClosed reports whether the user has closed their account.
+func (u *User) IsClosed() bool {
+	return u.Status == UserStatusClosed
+}
+
+// IsVerified reports whether the user has verified their email address.
+func (u *User) IsVerified() bool {
+	return u.Status != UserStatusPending
 }
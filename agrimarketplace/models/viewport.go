@@ -0,0 +1,26 @@
+package models
+
+// BoundingBox represents the visible area of a map.
+// MinLongitude may be greater than MaxLongitude when the box crosses the antimeridian.
+type BoundingBox struct {
+	MinLatitude  float64 `bson:"min_latitude"`
+	MinLongitude float64 `bson:"min_longitude"`
+	MaxLatitude  float64 `bson:"max_latitude"`
+	MaxLongitude float64 `bson:"max_longitude"`
+}
+
+// ShopCluster represents a group of nearby shops shown as a single map marker.
+type ShopCluster struct {
+	Latitude  float64     `bson:"latitude"`  // Centroid of the clustered shops
+	Longitude float64     `bson:"longitude"` // Centroid of the clustered shops
+	Count     int         `bson:"count"`
+	Bounds    BoundingBox `bson:"bounds"`
+}
+
+// ShopViewport represents the shops visible in a map viewport at a given zoom level.
+// Shops that are not grouped into a cluster are returned individually.
+type ShopViewport struct {
+	Zoom     int           `bson:"zoom"`
+	Shops    []Shop        `bson:"shops"`
+	Clusters []ShopCluster `bson:"clusters"`
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddressRepository defines the interface for interacting with users' address books.
+type AddressRepository interface {
+	FindAddressByID(id string) (*models.Address, error)
+	FindAddressesByUser(userID primitive.ObjectID) ([]models.Address, error)
+	FindDefaultAddress(userID primitive.ObjectID) (*models.Address, error)
+	InsertAddress(address *models.Address) error
+	UpdateAddress(address *models.Address) error
+	SetDefaultAddress(userID, id primitive.ObjectID) error
+	DeleteAddress(id primitive.ObjectID) error
+	DeleteAddressesByUser(userID primitive.ObjectID) (int64, error)
+}
+
+// addressRepository is an implementation of the AddressRepository interface.
+type addressRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAddressRepository creates a new instance of the addressRepository.
+func NewAddressRepository(database *mongo.Database) AddressRepository {
+	return &addressRepository{
+		collection: database.Collection("addresses"),
+	}
+}
+
+// FindAddressByID retrieves an address by its ID.
+func (r *addressRepository) FindAddressByID(id string) (*models.Address, error) {
+	return r.findOne(idFilter(id))
+}
+
+// FindAddressesByUser retrieves the address book of a user, default address first, then in the order they were added.
+func (r *addressRepository) FindAddressesByUser(userID primitive.ObjectID) ([]models.Address, error) {
+	var addresses []models.Address
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "is_default", Value: -1}, {Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &addresses); err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+// FindDefaultAddress retrieves the default address of a user.
+func (r *addressRepository) FindDefaultAddress(userID primitive.ObjectID) (*models.Address, error) {
+	return r.findOne(bson.M{"user_id": userID, "is_default": true})
+}
+
+// findOne retrieves the address matching a filter.
+func (r *addressRepository) findOne(filter bson.M) (*models.Address, error) {
+	var address models.Address
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.collection.FindOne(ctx, filter).Decode(&address)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Address not found
+		}
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+// InsertAddress inserts a new address into the database.
+func (r *addressRepository) InsertAddress(address *models.Address) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateAddress updates an existing address in the database.
+// The default flag is left unchanged; it is only set by SetDefaultAddress.
+func (r *addressRepository) UpdateAddress(address *models.Address) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	fields, err := toDocument(address)
+	if err != nil {
+		return err
+	}
+	delete(fields, "is_default")
+
+	filter := bson.M{"_id": address.ID}
+	update := bson.M{"$set": fields}
+
+	_, err = r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetDefaultAddress makes an address the default one of its user, in place of any other.
+func (r *addressRepository) SetDefaultAddress(userID, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The previous default is cleared first, as a user may only have one default address
+	filter := bson.M{"user_id": userID, "_id": bson.M{"$ne": id}, "is_default": true}
+	_, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"is_default": false}})
+	if err != nil {
+		return err
+	}
+
+	filter = bson.M{"_id": id, "user_id": userID}
+	_, err = r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"is_default": true}})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAddress deletes an address by its ID.
+func (r *addressRepository) DeleteAddress(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAddressesByUser deletes the address book of a user and returns the number of addresses deleted.
+func (r *addressRepository) DeleteAddressesByUser(userID primitive.ObjectID) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CategoryRepository defines the interface for interacting with category data.
+type CategoryRepository interface {
+	FindCategoryByID(id string) (*models.Category, error)
+	FindCategoryByName(name string) (*models.Category, error)
+	FindAllCategories() ([]models.Category, error)
+	InsertCategory(category *models.Category) error
+	UpdateCategory(category *models.Category) error
+}
+
+// categoryRepository is an implementation of the CategoryRepository interface.
+type categoryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCategoryRepository creates a new instance of the categoryRepository.
+func NewCategoryRepository(database *mongo.Database) CategoryRepository {
+	return &categoryRepository{
+		collection: database.Collection("categories"),
+	}
+}
+
+// FindCategoryByID retrieves a category by its ID.
+func (r *categoryRepository) FindCategoryByID(id string) (*models.Category, error) {
+	return r.findOne(idFilter(id))
+}
+
+// FindCategoryByName retrieves a category by its name, ignoring case.
+func (r *categoryRepository) FindCategoryByName(name string) (*models.Category, error) {
+	return r.findOne(bson.M{"name": name}, options.FindOne().SetCollation(caseInsensitive))
+}
+
+// findOne retrieves the first category matching the filter.
+func (r *categoryRepository) findOne(filter bson.M, opts ...*options.FindOneOptions) (*models.Category, error) {
+	var category models.Category
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.collection.FindOne(ctx, filter, opts...).Decode(&category)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Category not found
+		}
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+// FindAllCategories retrieves every category.
+func (r *categoryRepository) FindAllCategories() ([]models.Category, error) {
+	var categories []models.Category
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// InsertCategory inserts a new category into the database.
+func (r *categoryRepository) InsertCategory(category *models.Category) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, category)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateCategory updates an existing category in the database.
+func (r *categoryRepository) UpdateCategory(category *models.Category) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": category.ID}
+	update := bson.M{"$set": category}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// cooccurrenceBatchSize is the number of pair counts written in a single bulk write.
+const cooccurrenceBatchSize = 1000
+
+// CooccurrenceRepository defines the interface for interacting with product co-occurrence counts.
+type CooccurrenceRepository interface {
+	FindCooccurrences(productID primitive.ObjectID, minCount int) ([]models.ProductCooccurrence, error)
+	FindPurchaseCounts(productIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error)
+	IncrementCooccurrences(counts []models.ProductCooccurrence) error
+	ReplaceCooccurrences(counts []models.ProductCooccurrence) error
+}
+
+// cooccurrenceRepository is an implementation of the CooccurrenceRepository interface.
+type cooccurrenceRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCooccurrenceRepository creates a new instance of the cooccurrenceRepository.
+func NewCooccurrenceRepository(database *mongo.Database) CooccurrenceRepository {
+	return &cooccurrenceRepository{
+		collection: database.Collection("product_cooccurrences"),
+	}
+}
+
+// FindCooccurrences retrieves the products bought together with a product at least minCount times,
+// most frequent first. The pair of the product with itself is left out.
+func (r *cooccurrenceRepository) FindCooccurrences(productID primitive.ObjectID, minCount int) ([]models.ProductCooccurrence, error) {
+	var counts []models.ProductCooccurrence
+	filter := bson.M{
+		"product_id": productID,
+		"related_id": bson.M{"$ne": productID},
+		"count":      bson.M{"$gte": minCount},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "count", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// FindPurchaseCounts retrieves the number of purchases that contained each of the given products.
+func (r *cooccurrenceRepository) FindPurchaseCounts(productIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	var counts []models.ProductCooccurrence
+	filter := bson.M{
+		"product_id": bson.M{"$in": productIDs},
+		"$expr":      bson.M{"$eq": bson.A{"$product_id", "$related_id"}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, err
+	}
+
+	purchaseCounts := make(map[primitive.ObjectID]int, len(counts))
+	for _, count := range counts {
+		purchaseCounts[count.ProductID] = count.Count
+	}
+
+	return purchaseCounts, nil
+}
+
+// IncrementCooccurrences adds counts to the stored pair counts.
+func (r *cooccurrenceRepository) IncrementCooccurrences(counts []models.ProductCooccurrence) error {
+	writes := make([]mongo.WriteModel, 0, len(counts))
+	for _, count := range counts {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"product_id": count.ProductID, "related_id": count.RelatedID}).
+			SetUpdate(bson.M{"$inc": bson.M{"count": count.Count}}).
+			SetUpsert(true))
+	}
+
+	return r.bulkWrite(writes)
+}
+
+// ReplaceCooccurrences replaces every stored pair count with the given counts, as computed by a full rebuild.
+// Pairs are overwritten in place so that recommendations remain available while the rebuild runs.
+func (r *cooccurrenceRepository) ReplaceCooccurrences(counts []models.ProductCooccurrence) error {
+	rebuiltAt := time.Now()
+
+	writes := make([]mongo.WriteModel, 0, len(counts))
+	for _, count := range counts {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"product_id": count.ProductID, "related_id": count.RelatedID}).
+			SetUpdate(bson.M{"$set": bson.M{"count": count.Count, "rebuilt_at": rebuiltAt}}).
+			SetUpsert(true))
+	}
+	if err := r.bulkWrite(writes); err != nil {
+		return err
+	}
+
+	// Pairs that no purchase contains any more were not rewritten
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"rebuilt_at": bson.M{"$lt": rebuiltAt}},
+		bson.M{"rebuilt_at": bson.M{"$exists": false}},
+	}})
+	return err
+}
+
+// bulkWrite applies writes in batches.
+func (r *cooccurrenceRepository) bulkWrite(writes []mongo.WriteModel) error {
+	for start := 0; start < len(writes); start += cooccurrenceBatchSize {
+		end := start + cooccurrenceBatchSize
+		if end > len(writes) {
+			end = len(writes)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		_, err := r.collection.BulkWrite(ctx, writes[start:end], options.BulkWrite().SetOrdered(false))
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CropRuleRepository defines the interface for interacting with the crop recommendation rules.
+type CropRuleRepository interface {
+	FindCropRuleByID(id string) (*models.CropRule, error)
+	FindAllCropRules() ([]models.CropRule, error)
+	FindCropRulesByCrops(crops []string) ([]models.CropRule, error)
+	InsertCropRule(rule *models.CropRule) error
+	DeleteCropRule(id primitive.ObjectID) error
+}
+
+// cropRuleRepository is an implementation of the CropRuleRepository interface.
+type cropRuleRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCropRuleRepository creates a new instance of the cropRuleRepository.
+func NewCropRuleRepository(database *mongo.Database) CropRuleRepository {
+	return &cropRuleRepository{
+		collection: database.Collection("crop_rules"),
+	}
+}
+
+// FindCropRuleByID retrieves a crop rule by its ID.
+func (r *cropRuleRepository) FindCropRuleByID(id string) (*models.CropRule, error) {
+	var rule models.CropRule
+	filter := idFilter(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.collection.FindOne(ctx, filter).Decode(&rule)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Crop rule not found
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// FindAllCropRules retrieves every crop rule, by crop and growth timeline.
+func (r *cropRuleRepository) FindAllCropRules() ([]models.CropRule, error) {
+	return r.find(bson.M{})
+}
+
+// FindCropRulesByCrops retrieves the rules of the given crops, by crop and growth timeline.
+func (r *cropRuleRepository) FindCropRulesByCrops(crops []string) ([]models.CropRule, error) {
+	return r.find(bson.M{"crop": bson.M{"$in": crops}})
+}
+
+// find retrieves the crop rules matching a filter, by crop and growth timeline.
+func (r *cropRuleRepository) find(filter bson.M) ([]models.CropRule, error) {
+	var rules []models.CropRule
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "crop", Value: 1}, {Key: "start_day", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// InsertCropRule inserts a new crop rule into the database.
+func (r *cropRuleRepository) InsertCropRule(rule *models.CropRule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, rule)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteCropRule deletes a crop rule by its ID.
+func (r *cropRuleRepository) DeleteCropRule(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeliveryFeeRepository defines the interface for interacting with delivery fee schedules.
+type DeliveryFeeRepository interface {
+	FindScheduleByShopID(shopID string) (*models.DeliveryFeeSchedule, error)
+	UpsertSchedule(schedule *models.DeliveryFeeSchedule) error
+}
+
+// deliveryFeeRepository is an implementation of the DeliveryFeeRepository interface.
+type deliveryFeeRepository struct {
+	collection *mongo.Collection
+}
+
+// NewDeliveryFeeRepository creates a new instance of the deliveryFeeRepository.
+func NewDeliveryFeeRepository(database *mongo.Database) DeliveryFeeRepository {
+	return &deliveryFeeRepository{
+		collection: database.Collection("delivery_fee_schedules"),
+	}
+}
+
+// FindScheduleByShopID retrieves the delivery fee schedule of a shop.
+func (r *deliveryFeeRepository) FindScheduleByShopID(shopID string) (*models.DeliveryFeeSchedule, error) {
+	id, err := primitive.ObjectIDFromHex(shopID)
+	if err != nil {
+		return nil, nil // Invalid IDs cannot match any schedule
+	}
+
+	var schedule models.DeliveryFeeSchedule
+	filter := bson.M{"shop_id": id}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = r.collection.FindOne(ctx, filter).Decode(&schedule)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Schedule not found
+		}
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// UpsertSchedule creates or replaces the delivery fee schedule of a shop.
+func (r *deliveryFeeRepository) UpsertSchedule(schedule *models.DeliveryFeeSchedule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"shop_id": schedule.ShopID}
+	update := bson.M{"$set": bson.M{
+		"base_fee":                schedule.BaseFee,
+		"slabs":                   schedule.Slabs,
+		"free_delivery_threshold": schedule.FreeDeliveryThreshold,
+		"max_distance_km":         schedule.MaxDistanceKm,
+		"average_speed_kmph":      schedule.AverageSpeedKmph,
+		"preparation_minutes":     schedule.PreparationMinutes,
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DuplicateKeyError is returned when a write would give a record the value of a unique field
+// already held by another record. Field names the offending field.
+type DuplicateKeyError struct {
+	Field string
+}
+
+// Error implements the error interface.
+func (e *DuplicateKeyError) Error() string {
+	return "duplicate " + e.Field
+}
+
+// duplicateKeyError converts a MongoDB duplicate key error to a DuplicateKeyError, using the
+// names of the unique indexes to find the offending field. Other errors are returned unchanged.
+func duplicateKeyError(err error, indexFields map[string]string) error {
+	if err == nil || !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	var writeException mongo.WriteException
+	if errors.As(err, &writeException) {
+		for _, writeError := range writeException.WriteErrors {
+			if field, ok := indexField(writeError.Message, indexFields); ok {
+				return &DuplicateKeyError{Field: field}
+			}
+		}
+	}
+	if field, ok := indexField(err.Error(), indexFields); ok {
+		return &DuplicateKeyError{Field: field}
+	}
+
+	return err
+}
+
+// indexField finds which unique index a duplicate key message refers to.
+func indexField(message string, indexFields map[string]string) (string, bool) {
+	for index, field := range indexFields {
+		if strings.Contains(message, "index: "+index+" ") {
+			return field, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// idFilter matches a document by its ID given in hexadecimal form.
+// IDs that are not valid ObjectIDs are matched as plain strings.
+func idFilter(id string) bson.M {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return bson.M{"_id": id}
+	}
+	return bson.M{"_id": objectID}
+}
+
+// toDocument converts a model to a BSON document, for updates that leave some of its fields out.
+func toDocument(model interface{}) (bson.M, error) {
+	data, err := bson.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+
+	var document bson.M
+	if err := bson.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// notDeleted restricts a filter to the documents that have not been soft-deleted.
+func notDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = nil
+	return filter
+}
+
+// deletedOnly restricts a filter to the documents that have been soft-deleted.
+func deletedOnly(filter bson.M) bson.M {
+	filter["deleted_at"] = bson.M{"$ne": nil}
+	return filter
+}
+
+// hiddenProductStatuses are the lifecycle states of products left out of catalog listings and searches.
+var hiddenProductStatuses = bson.A{models.ProductStatusDraft, models.ProductStatusArchived}
+
+// caseInsensitive compares strings ignoring case.
+var caseInsensitive = &options.Collation{Locale: "en", Strength: 2}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ImageRepository defines the interface for interacting with image metadata.
+type ImageRepository interface {
+	FindImageByID(id string) (*models.Image, error)
+	FindImagesByOwner(ownerType string, ownerID primitive.ObjectID) ([]models.Image, error)
+	FindImageOwners(ownerType string) ([]primitive.ObjectID, error)
+	InsertImage(image *models.Image) error
+	DeleteImage(id primitive.ObjectID) error
+}
+
+// imageRepository is an implementation of the ImageRepository interface.
+type imageRepository struct {
+	collection *mongo.Collection
+}
+
+// NewImageRepository creates a new instance of the imageRepository.
+func NewImageRepository(database *mongo.Database) ImageRepository {
+	return &imageRepository{
+		collection: database.Collection("images"),
+	}
+}
+
+// FindImageByID retrieves an image by its ID.
+func (r *imageRepository) FindImageByID(id string) (*models.Image, error) {
+	var image models.Image
+	filter := idFilter(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.collection.FindOne(ctx, filter).Decode(&image)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Image not found
+		}
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+// FindImagesByOwner retrieves the images of a product or shop, oldest first.
+func (r *imageRepository) FindImagesByOwner(ownerType string, ownerID primitive.ObjectID) ([]models.Image, error) {
+	var images []models.Image
+	filter := bson.M{"owner_type": ownerType, "owner_id": ownerID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &images); err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// FindImageOwners retrieves the IDs of every owner of the given type that has images.
+func (r *imageRepository) FindImageOwners(ownerType string) ([]primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	values, err := r.collection.Distinct(ctx, "owner_id", bson.M{"owner_type": ownerType})
+	if err != nil {
+		return nil, err
+	}
+
+	ownerIDs := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ownerIDs = append(ownerIDs, id)
+		}
+	}
+
+	return ownerIDs, nil
+}
+
+// InsertImage inserts new image metadata into the database.
+func (r *imageRepository) InsertImage(image *models.Image) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, image)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteImage deletes image metadata by its ID.
+func (r *imageRepository) DeleteImage(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
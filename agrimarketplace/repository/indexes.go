@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Error codes of index commands.
+const (
+	indexNotFoundCode        = 27 // The named index does not exist
+	indexOptionsConflictCode = 85 // An index with the same keys or name exists with other options
+)
+
+// EnsureIndexes creates the indexes the repositories rely on.
+// Creating an index that already exists is a no-op, so it is safe to call on every startup.
+func EnsureIndexes(database *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Full-text index product search finds its candidates with; names weigh more than descriptions.
+	// Words are indexed as they are written, without stemming or stop words, like the search terms
+	// of the catalog that query terms are expanded to
+	productText := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "product_name", Value: "text"},
+			{Key: "description", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("product_text").
+			SetWeights(bson.M{"product_name": 3, "description": 1}).
+			SetDefaultLanguage("none"),
+	}
+	_, err := database.Collection("products").Indexes().CreateOne(ctx, productText)
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.Code == indexOptionsConflictCode {
+		// Created with English stemming before search expanded query terms itself
+		if err := dropIndex(ctx, database.Collection("products"), "product_text"); err != nil {
+			return err
+		}
+		_, err = database.Collection("products").Indexes().CreateOne(ctx, productText)
+	}
+	if err != nil {
+		return err
+	}
+	// Search terms are only aggregated over the whole catalog, so they need no index
+	if err := dropIndex(ctx, database.Collection("products"), "search_terms_1"); err != nil {
+		return err
+	}
+	if err := backfillSearchTerms(database.Collection("products")); err != nil {
+		return err
+	}
+
+	// Category names are looked up ignoring case and must be unique
+	_, err = database.Collection("categories").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true).SetCollation(caseInsensitive),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Product SKUs are the stable key of bulk imports
+	_, err = database.Collection("products").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "sku", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"sku": bson.M{"$type": "string"}}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Variant SKUs identify a pack across the whole catalog
+	_, err = database.Collection("products").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "variants.sku", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"variants.sku": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Catalog listing cursors compare these fields, so products saved before they were kept get
+	// a popularity of zero and the creation time recorded in their ObjectID
+	_, err = database.Collection("products").UpdateMany(ctx,
+		bson.M{"popularity": nil},
+		bson.M{"$set": bson.M{"popularity": 0}},
+	)
+	if err != nil {
+		return err
+	}
+	_, err = database.Collection("products").UpdateMany(ctx,
+		bson.M{"created_at": nil},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"created_at": bson.M{"$convert": bson.M{
+				"input":   "$_id",
+				"to":      "date",
+				"onError": time.Time{},
+			}},
+		}}}},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Catalog listing sorts on these fields with _id as the tie-breaker used by cursors
+	_, err = database.Collection("products").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "price", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "popularity", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "category_id", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Shops stored before their position was kept get it from their coordinates, so that
+	// the geospatial index used by nearby searches covers them; the index rejects invalid coordinates
+	_, err = database.Collection("shops").UpdateMany(ctx,
+		bson.M{
+			"position":  bson.M{"$exists": false},
+			"latitude":  bson.M{"$gte": -90, "$lte": 90},
+			"longitude": bson.M{"$gte": -180, "$lte": 180},
+		},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"position": bson.M{
+				"type":        "Point",
+				"coordinates": bson.A{"$longitude", "$latitude"},
+			},
+		}}}},
+	)
+	if err != nil {
+		return err
+	}
+	_, err = database.Collection("shops").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "position", Value: "2dsphere"}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Shop listings and their price history are looked up per shop and product
+	_, err = database.Collection("serviceable_products").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "shop_id", Value: 1}, {Key: "product_id", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = database.Collection("price_history").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "shop_id", Value: 1}, {Key: "product_id", Value: 1}, {Key: "changed_at", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Licences are checked per shop and reported by expiry date
+	_, err = database.Collection("shop_licences").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "shop_id", Value: 1}, {Key: "licence_type", Value: 1}}},
+		{Keys: bson.D{{Key: "valid_until", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Lots are allocated per shop and product in expiry order, and reported by expiry date
+	_, err = database.Collection("stock_lots").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "shop_id", Value: 1}, {Key: "product_id", Value: 1}, {Key: "expires_at", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Related products are looked up by product, most frequent pairs first; each pair is counted once
+	_, err = database.Collection("product_cooccurrences").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "product_id", Value: 1}, {Key: "related_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "product_id", Value: 1}, {Key: "count", Value: -1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Crop rules are looked up by crop, in growth order
+	_, err = database.Collection("crop_rules").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "crop", Value: 1}, {Key: "start_day", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Users are looked up by username, email address or phone number, which must each identify a
+	// single user; names are compared ignoring case. Users signing in by phone may have no email.
+	// Creating these fails if existing users already share a value, which must be resolved first.
+	_, err = database.Collection("users").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().
+				SetName(usernameIndex).
+				SetUnique(true).
+				SetCollation(caseInsensitive),
+		},
+		{
+			Keys: bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().
+				SetName(emailIndex).
+				SetUnique(true).
+				SetCollation(caseInsensitive).
+				SetPartialFilterExpression(bson.M{"email": bson.M{"$gt": ""}}),
+		},
+		{
+			Keys: bson.D{{Key: "phone", Value: 1}},
+			Options: options.Index().
+				SetName(phoneIndex).
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"phone": bson.M{"$type": "string"}}),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Nearby-users searches only look at users who opted in to being discovered
+	_, err = database.Collection("users").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "latitude", Value: 1}, {Key: "longitude", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"discoverable": true}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// One challenge per phone, removed once its code and resend counters are no longer needed
+	_, err = database.Collection("otp_challenges").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "phone", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "purge_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Sessions are looked up by the hash of their current or replaced refresh tokens, listed per user,
+	// and removed a month after they expire
+	_, err = database.Collection("sessions").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "rotated_hashes", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_used_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(30 * 24 * 60 * 60),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Password reset tokens are looked up by hash and removed a day after they expire
+	_, err = database.Collection("password_resets").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(24 * 60 * 60),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Rate limit counters are removed once their window has passed
+	_, err = database.Collection("rate_limits").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "purge_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Plots are listed and totalled per farmer
+	_, err = database.Collection("plots").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "name", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Address books are listed per user, who may have a single default address
+	_, err = database.Collection("addresses").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().
+				SetName("default_address_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"is_default": true}),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// The privacy audit log is listed per user
+	_, err = database.Collection("privacy_audit_log").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "at", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Purchase history is listed per user, most recent first
+	_, err = database.Collection("purchases").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purchased_at", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// The purge job looks up soft-deleted documents by deletion time
+	for _, collection := range []string{"products", "shops", "users"} {
+		_, err = database.Collection(collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dropIndex drops the named index of a collection, if it exists.
+func dropIndex(ctx context.Context, collection *mongo.Collection, name string) error {
+	_, err := collection.Indexes().DropOne(ctx, name)
+	var commandErr mongo.CommandError
+	if err != nil && !(errors.As(err, &commandErr) && commandErr.Code == indexNotFoundCode) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LicenceRepository defines the interface for interacting with shop licences.
+type LicenceRepository interface {
+	FindLicenceByID(id string) (*models.ShopLicence, error)
+	FindLicencesByShops(shopIDs []primitive.ObjectID) ([]models.ShopLicence, error)
+	FindLicencesExpiringBetween(from, to time.Time) ([]models.ShopLicence, error)
+	InsertLicence(licence *models.ShopLicence) error
+	DeleteLicence(id primitive.ObjectID) error
+}
+
+// licenceRepository is an implementation of the LicenceRepository interface.
+type licenceRepository struct {
+	collection *mongo.Collection
+}
+
+// NewLicenceRepository creates a new instance of the licenceRepository.
+func NewLicenceRepository(database *mongo.Database) LicenceRepository {
+	return &licenceRepository{
+		collection: database.Collection("shop_licences"),
+	}
+}
+
+// FindLicenceByID retrieves a licence by its ID.
+func (r *licenceRepository) FindLicenceByID(id string) (*models.ShopLicence, error) {
+	var licence models.ShopLicence
+	filter := idFilter(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.collection.FindOne(ctx, filter).Decode(&licence)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Licence not found
+		}
+		return nil, err
+	}
+
+	return &licence, nil
+}
+
+// FindLicencesByShops retrieves the licences of the given shops, latest expiry first.
+func (r *licenceRepository) FindLicencesByShops(shopIDs []primitive.ObjectID) ([]models.ShopLicence, error) {
+	var licences []models.ShopLicence
+	filter := bson.M{"shop_id": bson.M{"$in": shopIDs}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "valid_until", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &licences); err != nil {
+		return nil, err
+	}
+
+	return licences, nil
+}
+
+// FindLicencesExpiringBetween retrieves the licences whose validity ends within a period, soonest first.
+func (r *licenceRepository) FindLicencesExpiringBetween(from, to time.Time) ([]models.ShopLicence, error) {
+	var licences []models.ShopLicence
+	filter := bson.M{"valid_until": bson.M{"$gte": from, "$lte": to}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "valid_until", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &licences); err != nil {
+		return nil, err
+	}
+
+	return licences, nil
+}
+
+// InsertLicence inserts a new licence into the database.
+func (r *licenceRepository) InsertLicence(licence *models.ShopLicence) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, licence)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteLicence deletes a licence by its ID.
+func (r *licenceRepository) DeleteLicence(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OTPRepository defines the interface for interacting with the one-time passwords sent to phones.
+type OTPRepository interface {
+	FindOTPByPhone(phone string) (*models.OTPChallenge, error)
+	SaveOTP(challenge *models.OTPChallenge) error
+	RecordOTPAttempt(id primitive.ObjectID, maxAttempts int) (bool, error)
+	DeleteOTP(id primitive.ObjectID) error
+}
+
+// otpRepository is an implementation of the OTPRepository interface.
+type otpRepository struct {
+	collection *mongo.Collection
+}
+
+// NewOTPRepository creates a new instance of the otpRepository.
+func NewOTPRepository(database *mongo.Database) OTPRepository {
+	return &otpRepository{
+		collection: database.Collection("otp_challenges"),
+	}
+}
+
+// FindOTPByPhone retrieves the challenge of a phone number.
+func (r *otpRepository) FindOTPByPhone(phone string) (*models.OTPChallenge, error) {
+	var challenge models.OTPChallenge
+	filter := bson.M{"phone": phone}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.collection.FindOne(ctx, filter).Decode(&challenge)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // No challenge for this phone
+		}
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// SaveOTP replaces the challenge of a phone number.
+func (r *otpRepository) SaveOTP(challenge *models.OTPChallenge) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"phone": challenge.Phone}
+	result := r.collection.FindOneAndReplace(ctx, filter, challenge,
+		options.FindOneAndReplace().SetUpsert(true).SetReturnDocument(options.After))
+
+	var saved models.OTPChallenge
+	if err := result.Decode(&saved); err != nil {
+		return err
+	}
+	challenge.ID = saved.ID
+
+	return nil
+}
+
+// RecordOTPAttempt counts a verification attempt against a challenge.
+// It returns false, without counting, when the challenge has no attempts left.
+func (r *otpRepository) RecordOTPAttempt(id primitive.ObjectID, maxAttempts int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "attempts": bson.M{"$lt": maxAttempts}}
+	update := bson.M{"$inc": bson.M{"attempts": 1}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// DeleteOTP deletes a challenge once its code has been used.
+func (r *otpRepository) DeleteOTP(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
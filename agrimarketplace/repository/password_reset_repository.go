@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PasswordResetRepository defines the interface for interacting with password reset tokens.
+type PasswordResetRepository interface {
+	FindPasswordResetByTokenHash(tokenHash string) (*models.PasswordReset, error)
+	InsertPasswordReset(reset *models.PasswordReset) error
+	MarkPasswordResetUsed(id primitive.ObjectID, usedAt time.Time) (bool, error)
+}
+
+// passwordResetRepository is an implementation of the PasswordResetRepository interface.
+type passwordResetRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPasswordResetRepository creates a new instance of the passwordResetRepository.
+func NewPasswordResetRepository(database *mongo.Database) PasswordResetRepository {
+	return &passwordResetRepository{
+		collection: database.Collection("password_resets"),
+	}
+}
+
+// FindPasswordResetByTokenHash retrieves a password reset by the hash of its token.
+func (r *passwordResetRepository) FindPasswordResetByTokenHash(tokenHash string) (*models.PasswordReset, error) {
+	var reset models.PasswordReset
+	filter := bson.M{"token_hash": tokenHash}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.collection.FindOne(ctx, filter).Decode(&reset)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Password reset not found
+		}
+		return nil, err
+	}
+
+	return &reset, nil
+}
+
+// InsertPasswordReset inserts a new password reset into the database.
+func (r *passwordResetRepository) InsertPasswordReset(reset *models.PasswordReset) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, reset)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkPasswordResetUsed records that a password reset token was used.
+// It returns false when the token had already been used.
+func (r *passwordResetRepository) MarkPasswordResetUsed(id primitive.ObjectID, usedAt time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "used_at": nil}
+	update := bson.M{"$set": bson.M{"used_at": usedAt}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PlotRepository defines the interface for interacting with the plots of land held by farmers.
+type PlotRepository interface {
+	FindPlotByID(id string) (*models.Plot, error)
+	FindPlotsByUser(userID primitive.ObjectID) ([]models.Plot, error)
+	FindCropAreasByUser(userID primitive.ObjectID) ([]models.CropArea, error)
+	InsertPlot(plot *models.Plot) error
+	UpdatePlot(plot *models.Plot) error
+	DeletePlot(id primitive.ObjectID) error
+	DeletePlotsByUser(userID primitive.ObjectID) (int64, error)
+}
+
+// plotRepository is an implementation of the PlotRepository interface.
+type plotRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPlotRepository creates a new instance of the plotRepository.
+func NewPlotRepository(database *mongo.Database) PlotRepository {
+	return &plotRepository{
+		collection: database.Collection("plots"),
+	}
+}
+
+// FindPlotByID retrieves a plot by its ID.
+func (r *plotRepository) FindPlotByID(id string) (*models.Plot, error) {
+	var plot models.Plot
+	filter := idFilter(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.collection.FindOne(ctx, filter).Decode(&plot)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Plot not found
+		}
+		return nil, err
+	}
+
+	return &plot, nil
+}
+
+// FindPlotsByUser retrieves the plots of a user, by name.
+func (r *plotRepository) FindPlotsByUser(userID primitive.ObjectID) ([]models.Plot, error) {
+	var plots []models.Plot
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &plots); err != nil {
+		return nil, err
+	}
+
+	return plots, nil
+}
+
+// FindCropAreasByUser totals the area of a user's plots by their current crop, by crop.
+// Fallow plots are left out.
+func (r *plotRepository) FindCropAreasByUser(userID primitive.ObjectID) ([]models.CropArea, error) {
+	var areas []models.CropArea
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userID, "current_crop": bson.M{"$gt": ""}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           "$current_crop",
+			"plots":         bson.M{"$sum": 1},
+			"area_hectares": bson.M{"$sum": "$area_hectares"},
+			"area_acres":    bson.M{"$sum": "$area_acres"},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &areas); err != nil {
+		return nil, err
+	}
+
+	return areas, nil
+}
+
+// InsertPlot inserts a new plot into the database.
+func (r *plotRepository) InsertPlot(plot *models.Plot) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, plot)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePlot updates an existing plot in the database.
+func (r *plotRepository) UpdatePlot(plot *models.Plot) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": plot.ID}
+	update := bson.M{"$set": plot}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeletePlot deletes a plot by its ID.
+func (r *plotRepository) DeletePlot(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeletePlotsByUser deletes the plots of a user and returns the number of plots deleted.
+func (r *plotRepository) DeletePlotsByUser(userID primitive.ObjectID) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
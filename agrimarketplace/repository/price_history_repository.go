@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PriceHistoryRepository defines the interface for interacting with shop price changes.
+type PriceHistoryRepository interface {
+	FindPriceHistory(shopID, productID primitive.ObjectID) ([]models.PriceChange, error)
+	InsertPriceChange(change *models.PriceChange) error
+}
+
+// priceHistoryRepository is an implementation of the PriceHistoryRepository interface.
+type priceHistoryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPriceHistoryRepository creates a new instance of the priceHistoryRepository.
+func NewPriceHistoryRepository(database *mongo.Database) PriceHistoryRepository {
+	return &priceHistoryRepository{
+		collection: database.Collection("price_history"),
+	}
+}
+
+// FindPriceHistory retrieves the price changes of a product in a shop, most recent first.
+func (r *priceHistoryRepository) FindPriceHistory(shopID, productID primitive.ObjectID) ([]models.PriceChange, error) {
+	var changes []models.PriceChange
+	filter := bson.M{"shop_id": shopID, "product_id": productID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "changed_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &changes); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// InsertPriceChange records a price change.
+func (r *priceHistoryRepository) InsertPriceChange(change *models.PriceChange) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, change)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
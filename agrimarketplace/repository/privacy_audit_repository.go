@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PrivacyAuditRepository defines the interface for interacting with the log of actions taken on users' personal data.
+// Entries are only ever added.
+type PrivacyAuditRepository interface {
+	FindPrivacyAuditEntriesByUser(userID primitive.ObjectID) ([]models.PrivacyAuditEntry, error)
+	InsertPrivacyAuditEntry(entry *models.PrivacyAuditEntry) error
+}
+
+// privacyAuditRepository is an implementation of the PrivacyAuditRepository interface.
+type privacyAuditRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPrivacyAuditRepository creates a new instance of the privacyAuditRepository.
+func NewPrivacyAuditRepository(database *mongo.Database) PrivacyAuditRepository {
+	return &privacyAuditRepository{
+		collection: database.Collection("privacy_audit_log"),
+	}
+}
+
+// FindPrivacyAuditEntriesByUser retrieves the actions taken on a user's personal data, oldest first.
+func (r *privacyAuditRepository) FindPrivacyAuditEntriesByUser(userID primitive.ObjectID) ([]models.PrivacyAuditEntry, error) {
+	var entries []models.PrivacyAuditEntry
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// InsertPrivacyAuditEntry inserts a new entry into the log.
+func (r *privacyAuditRepository) InsertPrivacyAuditEntry(entry *models.PrivacyAuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
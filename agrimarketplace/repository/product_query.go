@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// priceRangeBoundaries are the lower bounds of the price range facet buckets.
+var priceRangeBoundaries = []float64{0, 100, 500, 1000, 5000}
+
+// ratingBoundaries are the lower bounds of the rating facet buckets.
+var ratingBoundaries = []float64{0, 1, 2, 3, 4}
+
+// productSortFields maps a sort order to the field it sorts on and its direction.
+var productSortFields = map[string]struct {
+	field     string
+	direction int
+}{
+	models.ProductSortPriceAsc:   {"price", 1},
+	models.ProductSortPriceDesc:  {"price", -1},
+	models.ProductSortPopularity: {"popularity", -1},
+	models.ProductSortNewest:     {"created_at", -1},
+}
+
+// FindProducts lists a page of the catalog matching the query, with facet counts for the whole result.
+// The query's sort order must be one of the models.ProductSort constants.
+func (r *productRepository) FindProducts(query models.ProductQuery) (*models.ProductPage, error) {
+	sortField, ok := productSortFields[query.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unsupported product sort %q", query.Sort)
+	}
+
+	filters := productQueryFilters(query)
+
+	// The page itself is filtered by every filter and positioned after the cursor
+	pageMatch := matchAllExcept(filters, "")
+	if query.After != nil {
+		pageMatch = bson.M{"$and": bson.A{pageMatch, cursorFilter(sortField.field, sortField.direction, query.After)}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$facet", Value: bson.M{
+			"products": bson.A{
+				bson.M{"$match": pageMatch},
+				bson.M{"$sort": bson.D{{Key: sortField.field, Value: sortField.direction}, {Key: "_id", Value: sortField.direction}}},
+				bson.M{"$limit": query.Limit + 1},
+			},
+			"categories": bson.A{
+				bson.M{"$match": matchAllExcept(filters, "category")},
+				bson.M{"$group": bson.M{"_id": "$category_id", "count": bson.M{"$sum": 1}}},
+				bson.M{"$project": bson.M{"_id": 0, "value": bson.M{"$toString": "$_id"}, "count": 1}},
+				bson.M{"$sort": bson.M{"count": -1}},
+			},
+			"brands": bson.A{
+				bson.M{"$match": matchAllExcept(filters, "brand")},
+				bson.M{"$group": bson.M{"_id": "$brand", "count": bson.M{"$sum": 1}}},
+				bson.M{"$project": bson.M{"_id": 0, "value": "$_id", "count": 1}},
+				bson.M{"$sort": bson.M{"count": -1}},
+			},
+			"price_ranges": bucketFacet(matchAllExcept(filters, "price"), "$price", priceRangeBoundaries),
+			"ratings":      bucketFacet(matchAllExcept(filters, "rating"), "$rating", ratingBoundaries),
+		}}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Products    []models.Product    `bson:"products"`
+		Categories  []models.FacetCount `bson:"categories"`
+		Brands      []models.FacetCount `bson:"brands"`
+		PriceRanges []models.FacetCount `bson:"price_ranges"`
+		Ratings     []models.FacetCount `bson:"ratings"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	page := &models.ProductPage{}
+	if len(results) == 0 {
+		return page, nil
+	}
+
+	result := results[0]
+	page.Products = result.Products
+	if len(page.Products) > query.Limit {
+		page.Products = page.Products[:query.Limit]
+		page.HasMore = true
+	}
+	page.Facets = models.ProductFacets{
+		Categories:  result.Categories,
+		Brands:      result.Brands,
+		PriceRanges: result.PriceRanges,
+		Ratings:     result.Ratings,
+	}
+
+	return page, nil
+}
+
+// productQueryFilters returns the Mongo filter of each facet of the query, keyed by facet name.
+// Filters that do not correspond to a facet are keyed by "base" and apply to every facet.
+func productQueryFilters(query models.ProductQuery) map[string]bson.M {
+	base := notDeleted(bson.M{"status": bson.M{"$nin": hiddenProductStatuses}})
+	if len(query.Statuses) > 0 {
+		statuses := bson.A{}
+		for _, status := range query.Statuses {
+			statuses = append(statuses, status)
+			if status == models.ProductStatusActive {
+				statuses = append(statuses, nil) // Products saved before lifecycle states are active
+			}
+		}
+		base["status"] = bson.M{"$in": statuses}
+	}
+	if query.ProductIDs != nil {
+		base["_id"] = bson.M{"$in": query.ProductIDs}
+	}
+	filters := map[string]bson.M{"base": base}
+
+	if len(query.CategoryIDs) > 0 {
+		filters["category"] = bson.M{"category_id": bson.M{"$in": query.CategoryIDs}}
+	}
+	if len(query.Brands) > 0 {
+		filters["brand"] = bson.M{"brand": bson.M{"$in": query.Brands}}
+	}
+	if query.MinPrice != nil || query.MaxPrice != nil {
+		price := bson.M{}
+		if query.MinPrice != nil {
+			price["$gte"] = *query.MinPrice
+		}
+		if query.MaxPrice != nil {
+			price["$lte"] = *query.MaxPrice
+		}
+		filters["price"] = bson.M{"price": price}
+	}
+	if query.MinRating != nil {
+		filters["rating"] = bson.M{"rating": bson.M{"$gte": *query.MinRating}}
+	}
+	if attributes := attributeFilter(query); len(attributes) > 0 {
+		filters["attributes"] = attributes
+	}
+
+	return filters
+}
+
+// attributeFilter matches category attribute values and numeric attribute bounds.
+func attributeFilter(query models.ProductQuery) bson.M {
+	var clauses bson.A
+
+	for name, value := range query.Attributes {
+		// Query values arrive as text, while attributes are stored with their schema type
+		candidates := bson.A{value}
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			candidates = append(candidates, number)
+		}
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			candidates = append(candidates, boolean)
+		}
+		clauses = append(clauses, bson.M{"attributes." + name: bson.M{"$in": candidates}})
+	}
+	for name, min := range query.AttributeMin {
+		clauses = append(clauses, bson.M{"attributes." + name: bson.M{"$gte": min}})
+	}
+	for name, max := range query.AttributeMax {
+		clauses = append(clauses, bson.M{"attributes." + name: bson.M{"$lte": max}})
+	}
+
+	if len(clauses) == 0 {
+		return nil
+	}
+	return bson.M{"$and": clauses}
+}
+
+// matchAllExcept combines every filter except the one of the given facet.
+func matchAllExcept(filters map[string]bson.M, facet string) bson.M {
+	var clauses bson.A
+	for name, filter := range filters {
+		if name != facet {
+			clauses = append(clauses, filter)
+		}
+	}
+	if len(clauses) == 0 {
+		return bson.M{}
+	}
+
+	return bson.M{"$and": clauses}
+}
+
+// cursorFilter matches the products that come after the cursor in the given sort order.
+// Ties on the sort field are broken by _id so that pages never overlap or skip products.
+func cursorFilter(field string, direction int, after *models.ProductCursor) bson.M {
+	var value interface{} = after.Number
+	if field == "created_at" {
+		value = after.Time
+	}
+
+	op := "$gt"
+	if direction < 0 {
+		op = "$lt"
+	}
+
+	return bson.M{"$or": bson.A{
+		bson.M{field: bson.M{op: value}},
+		bson.M{field: value, "_id": bson.M{op: after.ID}},
+	}}
+}
+
+// bucketFacet counts the products matching a filter in ranges starting at the given boundaries.
+func bucketFacet(match bson.M, field string, boundaries []float64) bson.A {
+	var branches bson.A
+	for i := len(boundaries) - 1; i >= 0; i-- {
+		label := fmt.Sprintf("%g+", boundaries[i])
+		if i+1 < len(boundaries) {
+			label = fmt.Sprintf("%g-%g", boundaries[i], boundaries[i+1])
+		}
+		branches = append(branches, bson.M{
+			"case": bson.M{"$gte": bson.A{field, boundaries[i]}},
+			"then": label,
+		})
+	}
+
+	return bson.A{
+		bson.M{"$match": match},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"$switch": bson.M{"branches": branches, "default": "other"}},
+			"count": bson.M{"$sum": 1},
+			"lower": bson.M{"$min": field},
+		}},
+		// Ranges are disjoint, so ordering by their lowest member orders the ranges themselves
+		bson.M{"$sort": bson.M{"lower": 1}},
+		bson.M{"$project": bson.M{"_id": 0, "value": "$_id", "count": 1}},
+	}
+}
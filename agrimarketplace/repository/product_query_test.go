@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCursorFilterPagesThroughTies(t *testing.T) {
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// The first five products were created at the same moment, and prices repeat every three products
+	var products []bson.M
+	for i := 0; i < 9; i++ {
+		createdAt := created
+		if i >= 5 {
+			createdAt = created.Add(time.Duration(i) * time.Hour)
+		}
+		products = append(products, bson.M{
+			"_id":        primitive.NewObjectID(),
+			"created_at": createdAt,
+			"price":      float64(100 * (i % 3)),
+		})
+	}
+
+	tests := []struct {
+		field     string
+		direction int
+	}{
+		{"created_at", -1},
+		{"price", 1},
+		{"price", -1},
+	}
+
+	for _, tt := range tests {
+		for _, pageSize := range []int{1, 2, 4} {
+			want := sortProducts(products, tt.field, tt.direction)
+
+			var got []bson.M
+			var after *models.ProductCursor
+			for pages := 0; ; pages++ {
+				if pages > len(products) {
+					t.Fatalf("sort by %s %d in pages of %d: pagination does not end", tt.field, tt.direction, pageSize)
+				}
+
+				page := want
+				if after != nil {
+					page = filterProducts(want, cursorFilter(tt.field, tt.direction, after))
+				}
+				if len(page) > pageSize {
+					page = page[:pageSize]
+				}
+				if len(page) == 0 {
+					break
+				}
+				got = append(got, page...)
+
+				last := page[len(page)-1]
+				after = &models.ProductCursor{ID: last["_id"].(primitive.ObjectID)}
+				if tt.field == "created_at" {
+					after.Time = last["created_at"].(time.Time)
+				} else {
+					after.Number = last[tt.field].(float64)
+				}
+			}
+
+			if !reflect.DeepEqual(productIDs(got), productIDs(want)) {
+				t.Errorf("sort by %s %d in pages of %d = %v, want every product once, in order %v",
+					tt.field, tt.direction, pageSize, productIDs(got), productIDs(want))
+			}
+		}
+	}
+}
+
+// sortProducts sorts products by a field and then by _id, in the given direction, like FindProducts.
+func sortProducts(products []bson.M, field string, direction int) []bson.M {
+	sorted := append([]bson.M(nil), products...)
+	sort.Slice(sorted, func(i, j int) bool {
+		c := compareValues(sorted[i][field], sorted[j][field])
+		if c == 0 {
+			c = compareValues(sorted[i]["_id"], sorted[j]["_id"])
+		}
+		return c*direction < 0
+	})
+	return sorted
+}
+
+// filterProducts returns the products matching a filter, keeping their order.
+func filterProducts(products []bson.M, filter bson.M) []bson.M {
+	var matched []bson.M
+	for _, product := range products {
+		if matchesFilter(product, filter) {
+			matched = append(matched, product)
+		}
+	}
+	return matched
+}
+
+// matchesFilter evaluates the equality, $gt, $lt and $or conditions cursorFilter builds.
+func matchesFilter(document bson.M, filter bson.M) bool {
+	for key, condition := range filter {
+		if key == "$or" {
+			matched := false
+			for _, clause := range condition.(bson.A) {
+				if matchesFilter(document, clause.(bson.M)) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+			continue
+		}
+
+		operators, ok := condition.(bson.M)
+		if !ok {
+			if compareValues(document[key], condition) != 0 {
+				return false
+			}
+			continue
+		}
+		for operator, value := range operators {
+			c := compareValues(document[key], value)
+			switch operator {
+			case "$gt":
+				if c <= 0 {
+					return false
+				}
+			case "$lt":
+				if c >= 0 {
+					return false
+				}
+			default:
+				panic(fmt.Sprintf("unsupported operator %s", operator))
+			}
+		}
+	}
+	return true
+}
+
+// compareValues compares two values of the types products are sorted on.
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case float64:
+		b := b.(float64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case primitive.ObjectID:
+		b := b.(primitive.ObjectID)
+		return bytes.Compare(a[:], b[:])
+	}
+	panic(fmt.Sprintf("unsupported value %v", a))
+}
+
+func productIDs(products []bson.M) []string {
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product["_id"].(primitive.ObjectID).Hex())
+	}
+	return ids
+}
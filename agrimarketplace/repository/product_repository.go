@@ -2,26 +2,63 @@ package repository
 
 import (
 	"agrimarketplace/models"
+	"agrimarketplace/search"
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // ProductRepository defines the interface for interacting with product data.
 type ProductRepository interface {
 	FindProductByID(id string) (*models.Product, error)
 	FindProductsByCategory(categoryID string) ([]models.Product, error)
+	FindProductBySKU(sku string) (*models.Product, error)
+	FindProductsByIDs(ids []primitive.ObjectID) ([]models.Product, error)
+	FindAllProducts() ([]models.Product, error)
+	StreamProducts(fn func(product models.Product) error) error
+	FindProducts(query models.ProductQuery) (*models.ProductPage, error)
 	InsertProduct(product *models.Product) error
 	UpdateProduct(product *models.Product) error
 	DeleteProduct(id string) error
+	FindDeletedProductByID(id string) (*models.Product, error)
+	RestoreProduct(id string) error
+	PurgeDeletedProducts(deletedBefore time.Time) ([]primitive.ObjectID, error)
 }
 
+// ProductTextSearcher is implemented by repositories that search the catalog without loading it in memory.
+type ProductTextSearcher interface {
+	SearchProducts(query search.Query, limit int) ([]models.ProductSearchResult, error)
+}
+
+const (
+	// maxSearchCandidates is the number of products matching a search, best scored by the text index, that are ranked.
+	maxSearchCandidates = 500
+	// searchCorpusTTL is how long the search terms and statistics of the catalog are kept before being reloaded.
+	searchCorpusTTL = time.Minute
+)
+
 // productRepository is an implementation of the ProductRepository interface.
 type productRepository struct {
 	collection *mongo.Collection
+
+	// corpus caches the search terms of the catalog, which typo-tolerant search compares query terms
+	// with, and the statistics search results are ranked with
+	corpusMu       sync.Mutex
+	corpus         *searchCorpus
+	corpusLoadedAt time.Time
+}
+
+// searchCorpus holds the search terms of the searchable products and their statistics.
+type searchCorpus struct {
+	terms []string
+	stats search.CorpusStats
 }
 
 // NewProductRepository creates a new instance of the productRepository.
@@ -34,7 +71,7 @@ func NewProductRepository(database *mongo.Database) ProductRepository {
 // FindProductByID retrieves a product by its ID.
 func (r *productRepository) FindProductByID(id string) (*models.Product, error) {
 	var product models.Product
-	filter := bson.M{"_id": id}
+	filter := notDeleted(idFilter(id))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -53,7 +90,48 @@ func (r *productRepository) FindProductByID(id string) (*models.Product, error)
 // FindProductsByCategory retrieves products by their category ID.
 func (r *productRepository) FindProductsByCategory(categoryID string) ([]models.Product, error) {
 	var products []models.Product
-	filter := bson.M{"category_id": categoryID}
+	filter := notDeleted(bson.M{"category_id": categoryID, "status": bson.M{"$nin": hiddenProductStatuses}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// FindProductBySKU retrieves a product by its SKU.
+// Soft-deleted products are included, since their SKUs stay reserved until they are purged.
+func (r *productRepository) FindProductBySKU(sku string) (*models.Product, error) {
+	var product models.Product
+	filter := bson.M{"sku": sku}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.collection.FindOne(ctx, filter).Decode(&product)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Product not found
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// FindProductsByIDs retrieves the products with the given IDs, in no particular order.
+func (r *productRepository) FindProductsByIDs(ids []primitive.ObjectID) ([]models.Product, error) {
+	var products []models.Product
+	filter := notDeleted(bson.M{"_id": bson.M{"$in": ids}})
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -71,12 +149,69 @@ func (r *productRepository) FindProductsByCategory(categoryID string) ([]models.
 	return products, nil
 }
 
+// StreamProducts calls fn for every product in the catalog, in SKU order, without loading them all at once.
+// Iteration stops at the first error returned by fn.
+func (r *productRepository) StreamProducts(fn func(product models.Product) error) error {
+	// Exporting a large catalog can take a while, so only bound the time between batches
+	ctx := context.Background()
+
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{}), options.Find().SetSort(bson.D{{Key: "sku", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for {
+		batchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		more := cursor.Next(batchCtx)
+		cancel()
+		if !more {
+			break
+		}
+
+		var product models.Product
+		if err := cursor.Decode(&product); err != nil {
+			return err
+		}
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// FindAllProducts retrieves every product in the catalog.
+func (r *productRepository) FindAllProducts() ([]models.Product, error) {
+	var products []models.Product
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
 // InsertProduct inserts a new product into the database.
 func (r *productRepository) InsertProduct(product *models.Product) error {
+	document, err := productDocument(product)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := r.collection.InsertOne(ctx, product)
+	_, err = r.collection.InsertOne(ctx, document)
 	if err != nil {
 		return err
 	}
@@ -86,13 +221,18 @@ func (r *productRepository) InsertProduct(product *models.Product) error {
 
 // UpdateProduct updates an existing product in the database.
 func (r *productRepository) UpdateProduct(product *models.Product) error {
+	document, err := productDocument(product)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{"_id": product.ID}
-	update := bson.M{"$set": product}
+	filter := notDeleted(bson.M{"_id": product.ID})
+	update := bson.M{"$set": document}
 
-	_, err := r.collection.UpdateOne(ctx, filter, update)
+	_, err = r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
@@ -100,17 +240,236 @@ func (r *productRepository) UpdateProduct(product *models.Product) error {
 	return nil
 }
 
-// DeleteProduct deletes a product by its ID.
+// DeleteProduct soft-deletes a product by its ID.
 func (r *productRepository) DeleteProduct(id string) error {
+	return softDelete(r.collection, id)
+}
+
+// FindDeletedProductByID retrieves a soft-deleted product by its ID.
+func (r *productRepository) FindDeletedProductByID(id string) (*models.Product, error) {
+	var product models.Product
+	found, err := findDeleted(r.collection, id, &product)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// RestoreProduct undoes the soft deletion of a product.
+func (r *productRepository) RestoreProduct(id string) error {
+	return restoreDeleted(r.collection, id)
+}
+
+// PurgeDeletedProducts permanently removes the products soft-deleted before the given time and returns their IDs.
+func (r *productRepository) PurgeDeletedProducts(deletedBefore time.Time) ([]primitive.ObjectID, error) {
+	return purgeDeleted(r.collection, deletedBefore)
+}
+
+// SearchProducts runs a relevance-ranked search over product names and descriptions.
+//
+// Query terms are compared with the terms of the whole catalog, exactly or within the typos they
+// tolerate, and the products holding a matched term are found through the product_text index.
+// The candidates the text index scores best are then ranked with the same BM25 scorer, using the
+// statistics of the whole catalog, so exact, typo-tolerant and synonym matches share a single
+// scale and scores do not depend on which products were candidates.
+func (r *productRepository) SearchProducts(query search.Query, limit int) ([]models.ProductSearchResult, error) {
+	corpus, err := r.searchCorpus()
+	if err != nil {
+		return nil, err
+	}
+	terms := query.MatchingTerms(corpus.terms)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{"_id": id}
+	// Common terms can match much of the catalog, so only the candidates the text index scores best are ranked
+	filter := notDeleted(bson.M{
+		"$text":  bson.M{"$search": strings.Join(terms, " ")},
+		"status": bson.M{"$nin": hiddenProductStatuses},
+	})
+	textScore := bson.M{"$meta": "textScore"}
+	findOptions := options.Find().
+		SetProjection(bson.M{"text_score": textScore}).
+		SetSort(bson.D{{Key: "text_score", Value: textScore}, {Key: "_id", Value: 1}}).
+		SetLimit(maxSearchCandidates)
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var candidates []models.Product
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+
+	index := search.NewIndex()
+	byID := make(map[string]models.Product, len(candidates))
+	for _, product := range candidates {
+		index.Add(ProductDocument(product))
+		byID[product.ID.Hex()] = product
+	}
+
+	var results []models.ProductSearchResult
+	for _, match := range index.SearchWithStats(query, limit, corpus.stats) {
+		results = append(results, models.ProductSearchResult{Product: byID[match.ID], Score: match.Score})
+	}
+
+	return results, nil
+}
+
+// searchCorpus returns the search terms of the searchable products with the number of products
+// holding each, and the number and average length of those products, reloading them once they
+// are older than searchCorpusTTL. Terms are streamed from an aggregation, so their number is not
+// bounded by the size of a single reply.
+func (r *productRepository) searchCorpus() (*searchCorpus, error) {
+	r.corpusMu.Lock()
+	defer r.corpusMu.Unlock()
+
+	if r.corpus != nil && time.Since(r.corpusLoadedAt) < searchCorpusTTL {
+		return r.corpus, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	searchable := bson.M{"$match": notDeleted(bson.M{"status": bson.M{"$nin": hiddenProductStatuses}})}
+	corpus := &searchCorpus{stats: search.CorpusStats{DocumentFrequency: map[string]int{}}}
+
+	cursor, err := r.collection.Aggregate(ctx, bson.A{
+		searchable,
+		bson.M{"$group": bson.M{
+			"_id":            nil,
+			"documents":      bson.M{"$sum": 1},
+			"average_length": bson.M{"$avg": "$search_length"},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var totals []struct {
+		Documents     int     `bson:"documents"`
+		AverageLength float64 `bson:"average_length"`
+	}
+	if err := cursor.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+	if len(totals) > 0 {
+		corpus.stats.Documents = totals[0].Documents
+		corpus.stats.AverageLength = totals[0].AverageLength
+	}
+
+	cursor, err = r.collection.Aggregate(ctx, bson.A{
+		searchable,
+		bson.M{"$unwind": "$search_terms"},
+		bson.M{"$group": bson.M{"_id": "$search_terms", "documents": bson.M{"$sum": 1}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var term struct {
+			Text      string `bson:"_id"`
+			Documents int    `bson:"documents"`
+		}
+		if err := cursor.Decode(&term); err != nil {
+			return nil, err
+		}
+		corpus.terms = append(corpus.terms, term.Text)
+		corpus.stats.DocumentFrequency[term.Text] = term.Documents
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	r.corpus = corpus
+	r.corpusLoadedAt = time.Now()
+
+	return corpus, nil
+}
+
+// searchTerms returns the distinct terms of a product's search document. They are stored with the
+// product so that searches can compare query terms with those of the catalog and count the
+// products holding each.
+func searchTerms(product *models.Product) []string {
+	seen := map[string]bool{}
+	terms := []string{}
+	for _, field := range ProductDocument(*product).Fields {
+		for _, term := range search.Tokenize(field.Text) {
+			if !seen[term] {
+				seen[term] = true
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms
+}
+
+// searchLength returns the weighted number of terms of a product's search document, which ranking
+// compares with the average of the catalog.
+func searchLength(product *models.Product) float64 {
+	length := 0.0
+	for _, field := range ProductDocument(*product).Fields {
+		length += field.Weight * float64(len(search.Tokenize(field.Text)))
+	}
+	return length
+}
+
+// productDocument converts a product to the document stored for it, with its search terms and length.
+func productDocument(product *models.Product) (bson.M, error) {
+	document, err := toDocument(product)
+	if err != nil {
+		return nil, err
+	}
+	document["search_terms"] = searchTerms(product)
+	document["search_length"] = searchLength(product)
+	return document, nil
+}
+
+// backfillSearchTerms stores the search terms and length of the products saved before they were kept.
+// Products are updated one at a time, so only each update is bounded in time.
+func backfillSearchTerms(collection *mongo.Collection) error {
+	ctx := context.Background()
 
-	_, err := r.collection.DeleteOne(ctx, filter)
+	cursor, err := collection.Find(ctx, bson.M{"search_length": bson.M{"$exists": false}})
 	if err != nil {
 		return err
 	}
+	defer cursor.Close(ctx)
 
-	return nil
+	for cursor.Next(ctx) {
+		var product models.Product
+		if err := cursor.Decode(&product); err != nil {
+			return err
+		}
+
+		updateCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		_, err := collection.UpdateOne(updateCtx,
+			bson.M{"_id": product.ID},
+			bson.M{"$set": bson.M{"search_terms": searchTerms(&product), "search_length": searchLength(&product)}},
+		)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// ProductDocument converts a product into a search document, weighing its name above its description.
+func ProductDocument(product models.Product) search.Document {
+	return search.Document{
+		ID: product.ID.Hex(),
+		Fields: []search.Field{
+			{Text: product.ProductName, Weight: 3},
+			{Text: product.Description, Weight: 1},
+		},
+	}
 }
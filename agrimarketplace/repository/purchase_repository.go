@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PurchaseRepository defines the interface for interacting with purchase data.
+type PurchaseRepository interface {
+	FindPurchaseByID(id string) (*models.Purchase, error)
+	FindPurchasesByUser(userID primitive.ObjectID) ([]models.Purchase, error)
+	StreamPurchases(fn func(purchase models.Purchase) error) error
+	InsertPurchase(purchase *models.Purchase) error
+	RedactDeliveryAddresses(userID primitive.ObjectID) (int64, error)
+}
+
+// purchaseRepository is an implementation of the PurchaseRepository interface.
+type purchaseRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPurchaseRepository creates a new instance of the purchaseRepository.
+func NewPurchaseRepository(database *mongo.Database) PurchaseRepository {
+	return &purchaseRepository{
+		collection: database.Collection("purchases"),
+	}
+}
+
+// FindPurchaseByID retrieves a purchase by its ID.
+func (r *purchaseRepository) FindPurchaseByID(id string) (*models.Purchase, error) {
+	var purchase models.Purchase
+	filter := idFilter(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.collection.FindOne(ctx, filter).Decode(&purchase)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Purchase not found
+		}
+		return nil, err
+	}
+
+	return &purchase, nil
+}
+
+// FindPurchasesByUser retrieves the purchases of a user, most recent first.
+func (r *purchaseRepository) FindPurchasesByUser(userID primitive.ObjectID) ([]models.Purchase, error) {
+	var purchases []models.Purchase
+	filter := bson.M{"user_id": userID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "purchased_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &purchases); err != nil {
+		return nil, err
+	}
+
+	return purchases, nil
+}
+
+// StreamPurchases calls fn for every purchase, without loading them all at once.
+// Iteration stops at the first error returned by fn.
+func (r *purchaseRepository) StreamPurchases(fn func(purchase models.Purchase) error) error {
+	// Reading every purchase can take a while, so only bound the time between batches
+	ctx := context.Background()
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"lines.allocations": 0}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for {
+		batchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		more := cursor.Next(batchCtx)
+		cancel()
+		if !more {
+			break
+		}
+
+		var purchase models.Purchase
+		if err := cursor.Decode(&purchase); err != nil {
+			return err
+		}
+		if err := fn(purchase); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// InsertPurchase inserts a new purchase into the database.
+func (r *purchaseRepository) InsertPurchase(purchase *models.Purchase) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, purchase)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RedactDeliveryAddresses clears the street address, landmark and coordinates from the delivery
+// addresses of a user's purchases, keeping the locality, district, state and PIN code the shops'
+// records need. It returns the number of purchases changed.
+func (r *purchaseRepository) RedactDeliveryAddresses(userID primitive.ObjectID) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": userID, "delivery_address": bson.M{"$exists": true}}
+	update := bson.M{"$unset": bson.M{
+		"delivery_address.line1":     "",
+		"delivery_address.line2":     "",
+		"delivery_address.landmark":  "",
+		"delivery_address.latitude":  "",
+		"delivery_address.longitude": "",
+	}}
+
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RateLimitRepository defines the interface for counting attempts within fixed time windows.
+type RateLimitRepository interface {
+	Hit(key string, window time.Duration, now time.Time) (int, error)
+}
+
+// rateLimitRepository is an implementation of the RateLimitRepository interface.
+type rateLimitRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRateLimitRepository creates a new instance of the rateLimitRepository.
+func NewRateLimitRepository(database *mongo.Database) RateLimitRepository {
+	return &rateLimitRepository{
+		collection: database.Collection("rate_limits"),
+	}
+}
+
+// Hit counts an attempt for a key and returns the number of attempts made in the current window,
+// including this one. Windows are aligned on multiples of their duration.
+func (r *rateLimitRepository) Hit(key string, window time.Duration, now time.Time) (int, error) {
+	windowStart := now.Truncate(window)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": key + ":" + strconv.FormatInt(windowStart.Unix(), 10)}
+	update := bson.M{
+		"$inc":         bson.M{"count": 1},
+		"$setOnInsert": bson.M{"purge_at": windowStart.Add(window)},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var counter struct {
+		Count int `bson:"count"`
+	}
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter); err != nil {
+		return 0, err
+	}
+
+	return counter.Count, nil
+}
@@ -3,26 +3,44 @@ package repository
 import (
 	"agrimarketplace/models"
 	"context"
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // ServiceableProductRepository defines the interface for interacting with serviceable product data.
 type ServiceableProductRepository interface {
 	FindServiceableProducts() ([]models.ServiceableProduct, error)
+	FindServiceableProductsByProduct(productID string) ([]models.ServiceableProduct, error)
+	FindServiceableProductsByShops(shopIDs []primitive.ObjectID) ([]models.ServiceableProduct, error)
+	FindServiceableProduct(shopID, productID primitive.ObjectID) (*models.ServiceableProduct, error)
+	SetServiceableProductPrice(shopID, productID primitive.ObjectID, price float64) error
+	SetServiceableProductStock(shopID, productID primitive.ObjectID, isServiceable bool, stockQuantity int) error
+	SetServiceableProductLotStock(shopID, productID primitive.ObjectID, stockQuantity int) error
+	AdjustServiceableProductStock(shopID, productID primitive.ObjectID, delta int) (bool, error)
+}
+
+// ProductAvailabilityAggregator is implemented by repositories that can join shop locations,
+// stock and serviceability for a product in a single query.
+type ProductAvailabilityAggregator interface {
+	AggregateProductAvailability(productID string, latitude, longitude float64, radiusInMeters float64) ([]models.ProductAvailability, error)
 }
 
 // serviceableProductRepository is an implementation of the ServiceableProductRepository interface.
 type serviceableProductRepository struct {
-	collection *mongo.Collection
+	collection     *mongo.Collection
+	shopCollection *mongo.Collection
 }
 
 // NewServiceableProductRepository creates a new instance of the serviceableProductRepository.
 func NewServiceableProductRepository(database *mongo.Database) ServiceableProductRepository {
 	return &serviceableProductRepository{
-		collection: database.Collection("serviceable_products"),
+		collection:     database.Collection("serviceable_products"),
+		shopCollection: database.Collection("shops"),
 	}
 }
 
@@ -46,3 +64,211 @@ func (r *serviceableProductRepository) FindServiceableProducts() ([]models.Servi
 
 	return serviceableProducts, nil
 }
+
+// FindServiceableProductsByProduct finds the serviceable, in-stock listings of a product across all shops.
+func (r *serviceableProductRepository) FindServiceableProductsByProduct(productID string) ([]models.ServiceableProduct, error) {
+	id, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, nil // Invalid IDs cannot match any listing
+	}
+
+	var serviceableProducts []models.ServiceableProduct
+	filter := bson.M{
+		"product_id":     id,
+		"is_serviceable": true,
+		"stock_quantity": bson.M{"$gt": 0},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &serviceableProducts); err != nil {
+		return nil, err
+	}
+
+	return serviceableProducts, nil
+}
+
+// FindServiceableProductsByShops finds the serviceable, in-stock listings of the given shops.
+func (r *serviceableProductRepository) FindServiceableProductsByShops(shopIDs []primitive.ObjectID) ([]models.ServiceableProduct, error) {
+	var serviceableProducts []models.ServiceableProduct
+	filter := bson.M{
+		"shop_id":        bson.M{"$in": shopIDs},
+		"is_serviceable": true,
+		"stock_quantity": bson.M{"$gt": 0},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &serviceableProducts); err != nil {
+		return nil, err
+	}
+
+	return serviceableProducts, nil
+}
+
+// FindServiceableProduct retrieves a shop's listing of a product, whether or not it is serviceable.
+func (r *serviceableProductRepository) FindServiceableProduct(shopID, productID primitive.ObjectID) (*models.ServiceableProduct, error) {
+	var serviceableProduct models.ServiceableProduct
+	filter := bson.M{"shop_id": shopID, "product_id": productID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.collection.FindOne(ctx, filter).Decode(&serviceableProduct)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Listing not found
+		}
+		return nil, err
+	}
+
+	return &serviceableProduct, nil
+}
+
+// SetServiceableProductPrice sets a shop's selling price for a product.
+// A listing without stock is created when the shop does not list the product yet.
+func (r *serviceableProductRepository) SetServiceableProductPrice(shopID, productID primitive.ObjectID, price float64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"shop_id": shopID, "product_id": productID}
+	update := bson.M{
+		"$set":         bson.M{"price": price},
+		"$setOnInsert": bson.M{"is_serviceable": true, "stock_quantity": 0},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetServiceableProductStock sets whether a shop serves a product and the quantity it holds.
+// The listing is created when the shop does not list the product yet.
+func (r *serviceableProductRepository) SetServiceableProductStock(shopID, productID primitive.ObjectID, isServiceable bool, stockQuantity int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"shop_id": shopID, "product_id": productID}
+	update := bson.M{"$set": bson.M{"is_serviceable": isServiceable, "stock_quantity": stockQuantity}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetServiceableProductLotStock sets the stock of a listing tracked by lot to the unexpired quantity of its lots.
+// A serviceable listing is created when the shop does not list the product yet.
+func (r *serviceableProductRepository) SetServiceableProductLotStock(shopID, productID primitive.ObjectID, stockQuantity int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"shop_id": shopID, "product_id": productID}
+	update := bson.M{
+		"$set":         bson.M{"stock_quantity": stockQuantity, "lot_tracked": true},
+		"$setOnInsert": bson.M{"is_serviceable": true},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AdjustServiceableProductStock adds delta to the stock of a listing. A negative delta is only applied
+// when the listing holds enough stock; the result reports whether the stock was changed.
+func (r *serviceableProductRepository) AdjustServiceableProductStock(shopID, productID primitive.ObjectID, delta int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"shop_id": shopID, "product_id": productID}
+	if delta < 0 {
+		filter["stock_quantity"] = bson.M{"$gte": -delta}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"stock_quantity": delta}})
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// AggregateProductAvailability finds the nearby shops holding serviceable stock of a product.
+// It starts from the shops' geospatial index and joins their listings in the same pipeline.
+func (r *serviceableProductRepository) AggregateProductAvailability(productID string, latitude, longitude float64, radiusInMeters float64) ([]models.ProductAvailability, error) {
+	id, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, nil // Invalid IDs cannot match any listing
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.M{
+			"near":          models.NewGeoPoint(latitude, longitude),
+			"key":           "position",
+			"distanceField": "distance_meters",
+			"maxDistance":   radiusInMeters,
+			"spherical":     true,
+			"query":         notDeleted(bson.M{}),
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "serviceable_products",
+			"let":  bson.M{"shop_id": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{
+					"product_id":     id,
+					"is_serviceable": true,
+					"stock_quantity": bson.M{"$gt": 0},
+					"$expr":          bson.M{"$eq": bson.A{"$shop_id", "$$shop_id"}},
+				}},
+			},
+			"as": "listing",
+		}}},
+		{{Key: "$unwind", Value: "$listing"}},
+		{{Key: "$project", Value: bson.M{
+			"_id":                0,
+			"shop":               "$$ROOT",
+			"product_id":         "$listing.product_id",
+			"price":              "$listing.price",
+			"available_quantity": "$listing.stock_quantity",
+			"distance_meters":    1,
+		}}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.shopCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var availability []models.ProductAvailability
+	if err := cursor.All(ctx, &availability); err != nil {
+		return nil, err
+	}
+
+	return availability, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SessionRepository defines the interface for interacting with users' sign-in sessions.
+type SessionRepository interface {
+	FindSessionByID(id string) (*models.Session, error)
+	FindSessionByTokenHash(tokenHash string) (*models.Session, error)
+	FindSessionByRotatedHash(tokenHash string) (*models.Session, error)
+	FindActiveSessionsByUser(userID primitive.ObjectID, now time.Time) ([]models.Session, error)
+	InsertSession(session *models.Session) error
+	RotateSessionToken(session *models.Session, newTokenHash string, maxRotatedHashes int) (bool, error)
+	RevokeSession(id primitive.ObjectID, reason string, revokedAt time.Time) (bool, error)
+	RevokeUserSessions(userID primitive.ObjectID, reason string, revokedAt time.Time) (int64, error)
+}
+
+// sessionRepository is an implementation of the SessionRepository interface.
+type sessionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSessionRepository creates a new instance of the sessionRepository.
+func NewSessionRepository(database *mongo.Database) SessionRepository {
+	return &sessionRepository{
+		collection: database.Collection("sessions"),
+	}
+}
+
+// FindSessionByID retrieves a session by its ID.
+func (r *sessionRepository) FindSessionByID(id string) (*models.Session, error) {
+	return r.findOne(idFilter(id))
+}
+
+// FindSessionByTokenHash retrieves the session whose current refresh token has the given hash.
+func (r *sessionRepository) FindSessionByTokenHash(tokenHash string) (*models.Session, error) {
+	return r.findOne(bson.M{"token_hash": tokenHash})
+}
+
+// FindSessionByRotatedHash retrieves the session in which a refresh token with the given hash was replaced.
+func (r *sessionRepository) FindSessionByRotatedHash(tokenHash string) (*models.Session, error) {
+	return r.findOne(bson.M{"rotated_hashes": tokenHash})
+}
+
+// findOne retrieves the session matching a filter.
+func (r *sessionRepository) findOne(filter bson.M) (*models.Session, error) {
+	var session models.Session
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := r.collection.FindOne(ctx, filter).Decode(&session)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Session not found
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// FindActiveSessionsByUser retrieves the sessions of a user that are neither revoked nor expired, most recently used first.
+func (r *sessionRepository) FindActiveSessionsByUser(userID primitive.ObjectID, now time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	filter := bson.M{"user_id": userID, "revoked_at": nil, "expires_at": bson.M{"$gt": now}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "last_used_at", Value: -1}}).
+		SetProjection(bson.M{"token_hash": 0, "rotated_hashes": 0})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// InsertSession inserts a new session into the database.
+func (r *sessionRepository) InsertSession(session *models.Session) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, session)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RotateSessionToken replaces the refresh token of a session, keeping the hashes of the most recent
+// replaced tokens, and records the session's new use, device and expiry. The token is only replaced
+// if it is still the one the session was read with and the session is not revoked; it returns false
+// otherwise, such as when the same token is used twice at once.
+func (r *sessionRepository) RotateSessionToken(session *models.Session, newTokenHash string, maxRotatedHashes int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": session.ID, "token_hash": session.TokenHash, "revoked_at": nil}
+	update := bson.M{
+		"$set": bson.M{
+			"token_hash":   newTokenHash,
+			"device":       session.Device,
+			"last_used_at": session.LastUsedAt,
+			"expires_at":   session.ExpiresAt,
+		},
+		"$push": bson.M{"rotated_hashes": bson.M{
+			"$each":  bson.A{session.TokenHash},
+			"$slice": -maxRotatedHashes,
+		}},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// RevokeSession revokes a session. It returns false when the session was already revoked.
+func (r *sessionRepository) RevokeSession(id primitive.ObjectID, reason string, revokedAt time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id, "revoked_at": nil}
+	update := bson.M{"$set": bson.M{"revoked_at": revokedAt, "revoked_reason": reason}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// RevokeUserSessions revokes every session of a user and returns the number of sessions revoked.
+func (r *sessionRepository) RevokeUserSessions(userID primitive.ObjectID, reason string, revokedAt time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": userID, "revoked_at": nil}
+	update := bson.M{"$set": bson.M{"revoked_at": revokedAt, "revoked_reason": reason}}
+
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -7,16 +7,23 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // ShopRepository defines the interface for interacting with shop data.
 type ShopRepository interface {
 	FindShopByID(id string) (*models.Shop, error)
+	FindShopsByOwner(ownerID primitive.ObjectID) ([]models.Shop, error)
 	InsertShop(shop *models.Shop) error
 	UpdateShop(shop *models.Shop) error
 	DeleteShop(id string) error
+	FindDeletedShopByID(id string) (*models.Shop, error)
+	RestoreShop(id string) error
+	PurgeDeletedShops(deletedBefore time.Time) ([]primitive.ObjectID, error)
 	FindNearbyShops(latitude, longitude float64, radiusInMeters float64) ([]models.Shop, error)
+	FindShopsInBoundingBox(box models.BoundingBox) ([]models.Shop, error)
 }
 
 // shopRepository is an implementation of the ShopRepository interface.
@@ -34,7 +41,7 @@ func NewShopRepository(database *mongo.Database) ShopRepository {
 // FindShopByID retrieves a shop by its ID.
 func (r *shopRepository) FindShopByID(id string) (*models.Shop, error) {
 	var shop models.Shop
-	filter := bson.M{"_id": id}
+	filter := notDeleted(idFilter(id))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -52,6 +59,8 @@ func (r *shopRepository) FindShopByID(id string) (*models.Shop, error) {
 
 // InsertShop inserts a new shop into the database.
 func (r *shopRepository) InsertShop(shop *models.Shop) error {
+	shop.Position = models.NewGeoPoint(shop.Latitude, shop.Longitude)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -65,10 +74,12 @@ func (r *shopRepository) InsertShop(shop *models.Shop) error {
 
 // UpdateShop updates an existing shop in the database.
 func (r *shopRepository) UpdateShop(shop *models.Shop) error {
+	shop.Position = models.NewGeoPoint(shop.Latitude, shop.Longitude)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{"_id": shop.ID}
+	filter := notDeleted(bson.M{"_id": shop.ID})
 	update := bson.M{"$set": shop}
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
@@ -79,38 +90,43 @@ func (r *shopRepository) UpdateShop(shop *models.Shop) error {
 	return nil
 }
 
-// DeleteShop deletes a shop by its ID.
+// DeleteShop soft-deletes a shop by its ID.
 func (r *shopRepository) DeleteShop(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	filter := bson.M{"_id": id}
+	return softDelete(r.collection, id)
+}
 
-	_, err := r.collection.DeleteOne(ctx, filter)
-	if err != nil {
-		return err
+// FindDeletedShopByID retrieves a soft-deleted shop by its ID.
+func (r *shopRepository) FindDeletedShopByID(id string) (*models.Shop, error) {
+	var shop models.Shop
+	found, err := findDeleted(r.collection, id, &shop)
+	if err != nil || !found {
+		return nil, err
 	}
 
-	return nil
+	return &shop, nil
+}
+
+// RestoreShop undoes the soft deletion of a shop.
+func (r *shopRepository) RestoreShop(id string) error {
+	return restoreDeleted(r.collection, id)
+}
+
+// PurgeDeletedShops permanently removes the shops soft-deleted before the given time and returns their IDs.
+func (r *shopRepository) PurgeDeletedShops(deletedBefore time.Time) ([]primitive.ObjectID, error) {
+	return purgeDeleted(r.collection, deletedBefore)
 }
 
 // FindNearbyShops finds nearby shops based on latitude and longitude within a specified radius.
 func (r *shopRepository) FindNearbyShops(latitude, longitude float64, radiusInMeters float64) ([]models.Shop, error) {
-	// Create a GeoJSON point representing the coordinates
-	point := bson.M{
-		"type":        "Point",
-		"coordinates": []float64{longitude, latitude},
-	}
-
 	// Create a GeoJSON query for finding shops within the specified radius
-	query := bson.M{
-		"location": bson.M{
+	query := notDeleted(bson.M{
+		"position": bson.M{
 			"$nearSphere": bson.M{
-				"$geometry":    point,
+				"$geometry":    models.NewGeoPoint(latitude, longitude),
 				"$maxDistance": radiusInMeters,
 			},
 		},
-	}
+	})
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -128,3 +144,56 @@ func (r *shopRepository) FindNearbyShops(latitude, longitude float64, radiusInMe
 
 	return nearbyShops, nil
 }
+
+// FindShopsByOwner retrieves the shops owned by a user, by name.
+func (r *shopRepository) FindShopsByOwner(ownerID primitive.ObjectID) ([]models.Shop, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "shop_name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{"owner_id": ownerID}), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var shops []models.Shop
+	if err := cursor.All(ctx, &shops); err != nil {
+		return nil, err
+	}
+
+	return shops, nil
+}
+
+// FindShopsInBoundingBox finds the shops located inside a latitude/longitude bounding box.
+func (r *shopRepository) FindShopsInBoundingBox(box models.BoundingBox) ([]models.Shop, error) {
+	query := notDeleted(bson.M{
+		"latitude": bson.M{"$gte": box.MinLatitude, "$lte": box.MaxLatitude},
+	})
+
+	// A box crossing the antimeridian is split into its eastern and western halves
+	if box.MinLongitude <= box.MaxLongitude {
+		query["longitude"] = bson.M{"$gte": box.MinLongitude, "$lte": box.MaxLongitude}
+	} else {
+		query["$or"] = bson.A{
+			bson.M{"longitude": bson.M{"$gte": box.MinLongitude}},
+			bson.M{"longitude": bson.M{"$lte": box.MaxLongitude}},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var shops []models.Shop
+	if err := cursor.All(ctx, &shops); err != nil {
+		return nil, err
+	}
+
+	return shops, nil
+}
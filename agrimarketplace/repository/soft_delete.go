@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Soft-deleted documents keep their data and references, and are only marked with a
+// deleted_at time. Default queries leave them out; they can be restored until purged.
+
+// softDelete marks the document with the given ID as deleted, unless it already is.
+func softDelete(collection *mongo.Collection, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := notDeleted(idFilter(id))
+	update := bson.M{"$set": bson.M{"deleted_at": time.Now()}}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// restoreDeleted clears the deletion mark of the document with the given ID.
+func restoreDeleted(collection *mongo.Collection, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := deletedOnly(idFilter(id))
+	update := bson.M{"$unset": bson.M{"deleted_at": ""}}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// findDeleted decodes the soft-deleted document with the given ID into result.
+// It reports false when there is no such document.
+func findDeleted(collection *mongo.Collection, id string, result interface{}) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := collection.FindOne(ctx, deletedOnly(idFilter(id))).Decode(result)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// purgeDeleted permanently removes the documents soft-deleted before the given time
+// and returns their IDs, so that the records depending on them can be cleaned up.
+func purgeDeleted(collection *mongo.Collection, deletedBefore time.Time) ([]primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{"deleted_at": bson.M{"$lt": deletedBefore}}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var documents []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+	// Documents are removed one at a time so that those restored in the meantime are kept and not reported
+	var ids []primitive.ObjectID
+	for _, document := range documents {
+		result, err := collection.DeleteOne(ctx, bson.M{"_id": document.ID, "deleted_at": bson.M{"$lt": deletedBefore}})
+		if err != nil {
+			return ids, err
+		}
+		if result.DeletedCount > 0 {
+			ids = append(ids, document.ID)
+		}
+	}
+
+	return ids, nil
+}
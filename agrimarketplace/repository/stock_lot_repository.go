@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"agrimarketplace/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StockLotRepository defines the interface for interacting with the stock lots held by shops.
+type StockLotRepository interface {
+	FindLotsByShopProduct(shopID, productID primitive.ObjectID) ([]models.StockLot, error)
+	FindLotsExpiringBetween(shopID primitive.ObjectID, from, to time.Time) ([]models.StockLot, error)
+	InsertLot(lot *models.StockLot) error
+	AdjustLotQuantity(id primitive.ObjectID, delta int) (bool, error)
+}
+
+// stockLotRepository is an implementation of the StockLotRepository interface.
+type stockLotRepository struct {
+	collection *mongo.Collection
+}
+
+// NewStockLotRepository creates a new instance of the stockLotRepository.
+func NewStockLotRepository(database *mongo.Database) StockLotRepository {
+	return &stockLotRepository{
+		collection: database.Collection("stock_lots"),
+	}
+}
+
+// FindLotsByShopProduct retrieves the lots of a product in a shop that still hold stock, soonest expiry first.
+func (r *stockLotRepository) FindLotsByShopProduct(shopID, productID primitive.ObjectID) ([]models.StockLot, error) {
+	filter := bson.M{"shop_id": shopID, "product_id": productID, "quantity": bson.M{"$gt": 0}}
+	return r.findLots(filter)
+}
+
+// FindLotsExpiringBetween retrieves the lots of a shop that still hold stock and expire within a period,
+// soonest expiry first. A zero shop ID matches the lots of every shop.
+func (r *stockLotRepository) FindLotsExpiringBetween(shopID primitive.ObjectID, from, to time.Time) ([]models.StockLot, error) {
+	filter := bson.M{"expires_at": bson.M{"$gte": from, "$lte": to}, "quantity": bson.M{"$gt": 0}}
+	if !shopID.IsZero() {
+		filter["shop_id"] = shopID
+	}
+	return r.findLots(filter)
+}
+
+// findLots retrieves the lots matching a filter, soonest expiry first.
+func (r *stockLotRepository) findLots(filter bson.M) ([]models.StockLot, error) {
+	var lots []models.StockLot
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "expires_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &lots); err != nil {
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+// InsertLot inserts a new lot into the database.
+func (r *stockLotRepository) InsertLot(lot *models.StockLot) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.InsertOne(ctx, lot)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AdjustLotQuantity adds delta to the quantity of a lot. A negative delta is only applied
+// when the lot holds enough stock; the result reports whether the quantity was changed.
+func (r *stockLotRepository) AdjustLotQuantity(id primitive.ObjectID, delta int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": id}
+	if delta < 0 {
+		filter["quantity"] = bson.M{"$gte": -delta}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"quantity": delta}})
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
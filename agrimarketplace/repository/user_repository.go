@@ -7,17 +7,47 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// Names of the unique indexes of the users collection, by the field they cover.
+const (
+	usernameIndex = "username_unique"
+	emailIndex    = "email_unique"
+	phoneIndex    = "phone_unique"
+)
+
+// userIndexFields maps the unique indexes of the users collection to the field they cover.
+var userIndexFields = map[string]string{
+	usernameIndex: "username",
+	emailIndex:    "email",
+	phoneIndex:    "phone",
+}
+
 // UserRepository defines the interface for interacting with user data.
+// Usernames and email addresses are unique ignoring case, and phone numbers are unique;
+// InsertUser and UpdateUser return a *DuplicateKeyError naming the field when a write would
+// break this, whatever the backend.
 type UserRepository interface {
 	FindUserByID(id string) (*models.User, error)
 	FindUserByUsername(username string) (*models.User, error)
+	FindUserByEmail(email string) (*models.User, error)
+	FindUserByPhone(phone string) (*models.User, error)
 	InsertUser(user *models.User) error
 	UpdateUser(user *models.User) error
+	SetUserPassword(id primitive.ObjectID, passwordHash string) error
+	RevokeUserTokens(id primitive.ObjectID) error
+	SetUserCrops(id primitive.ObjectID, crops []models.CropPlanting) error
+	ActivateUser(id primitive.ObjectID, verifiedAt time.Time) (bool, error)
+	AnonymizeUser(id primitive.ObjectID, username string, closedAt time.Time) error
 	DeleteUser(id string) error
-	FindNearbyUsers(latitude, longitude float64, radiusInMeters float64) ([]models.User, error)
+	FindDeletedUserByID(id string) (*models.User, error)
+	RestoreUser(id string) error
+	PurgeDeletedUsers(deletedBefore time.Time) ([]primitive.ObjectID, error)
+	FindDiscoverableUsersInBox(box models.BoundingBox) ([]models.User, error)
+	SetUserRole(id primitive.ObjectID, role string) error
 }
 
 // userRepository is an implementation of the UserRepository interface.
@@ -35,7 +65,7 @@ func NewUserRepository(database *mongo.Database) UserRepository {
 // FindUserByID retrieves a user by their ID.
 func (r *userRepository) FindUserByID(id string) (*models.User, error) {
 	var user models.User
-	filter := bson.M{"_id": id}
+	filter := notDeleted(idFilter(id))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -51,15 +81,29 @@ func (r *userRepository) FindUserByID(id string) (*models.User, error) {
 	return &user, nil
 }
 
-// FindUserByUsername retrieves a user by their username.
+// FindUserByUsername retrieves a user by their username, ignoring case.
 func (r *userRepository) FindUserByUsername(username string) (*models.User, error) {
+	return r.findOne(notDeleted(bson.M{"username": username}), options.FindOne().SetCollation(caseInsensitive))
+}
+
+// FindUserByEmail retrieves a user by their email address, ignoring case.
+func (r *userRepository) FindUserByEmail(email string) (*models.User, error) {
+	return r.findOne(notDeleted(bson.M{"email": email}), options.FindOne().SetCollation(caseInsensitive))
+}
+
+// FindUserByPhone retrieves a user by their phone number in E.164 format.
+func (r *userRepository) FindUserByPhone(phone string) (*models.User, error) {
+	return r.findOne(notDeleted(bson.M{"phone": phone}))
+}
+
+// findOne retrieves the user matching a filter.
+func (r *userRepository) findOne(filter bson.M, opts ...*options.FindOneOptions) (*models.User, error) {
 	var user models.User
-	filter := bson.M{"username": username}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	err := r.collection.FindOne(ctx, filter, opts...).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, nil // User not found
@@ -77,19 +121,49 @@ func (r *userRepository) InsertUser(user *models.User) error {
 
 	_, err := r.collection.InsertOne(ctx, user)
 	if err != nil {
-		return err
+		return duplicateKeyError(err, userIndexFields)
 	}
 
 	return nil
 }
 
 // UpdateUser updates an existing user in the database.
+// The password and token version are left unchanged; they are only set by SetUserPassword.
+// The role is only set by SetUserRole.
 func (r *userRepository) UpdateUser(user *models.User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{"_id": user.ID}
-	update := bson.M{"$set": user}
+	fields, err := toDocument(user)
+	if err != nil {
+		return err
+	}
+	delete(fields, "password")
+	delete(fields, "token_version")
+	delete(fields, "role")
+
+	filter := notDeleted(bson.M{"_id": user.ID})
+	update := bson.M{"$set": fields}
+
+	_, err = r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return duplicateKeyError(err, userIndexFields)
+	}
+
+	return nil
+}
+
+// SetUserPassword replaces the password hash of a user and bumps their token version,
+// revoking every token issued to them.
+func (r *userRepository) SetUserPassword(id primitive.ObjectID, passwordHash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := notDeleted(bson.M{"_id": id})
+	update := bson.M{
+		"$set": bson.M{"password": passwordHash},
+		"$inc": bson.M{"token_version": 1},
+	}
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
@@ -99,14 +173,31 @@ func (r *userRepository) UpdateUser(user *models.User) error {
 	return nil
 }
 
-// DeleteUser deletes a user by their ID.
-func (r *userRepository) DeleteUser(id string) error {
+// RevokeUserTokens bumps the token version of a user, revoking every token issued to them.
+func (r *userRepository) RevokeUserTokens(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := notDeleted(bson.M{"_id": id})
+	update := bson.M{"$inc": bson.M{"token_version": 1}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetUserCrops replaces the crops grown by a user.
+func (r *userRepository) SetUserCrops(id primitive.ObjectID, crops []models.CropPlanting) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{"_id": id}
+	filter := notDeleted(bson.M{"_id": id})
+	update := bson.M{"$set": bson.M{"crops": crops}}
 
-	_, err := r.collection.DeleteOne(ctx, filter)
+	_, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
@@ -114,22 +205,97 @@ func (r *userRepository) DeleteUser(id string) error {
 	return nil
 }
 
-// FindNearbyUsers finds nearby users based on latitude and longitude within a specified radius.
-func (r *userRepository) FindNearbyUsers(latitude, longitude float64, radiusInMeters float64) ([]models.User, error) {
-	// Create a GeoJSON point representing the coordinates
-	point := bson.M{
-		"type":        "Point",
-		"coordinates": []float64{longitude, latitude},
+// ActivateUser marks a pending user as verified and active.
+// It returns false when the user is not pending verification.
+func (r *userRepository) ActivateUser(id primitive.ObjectID, verifiedAt time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := notDeleted(bson.M{"_id": id, "status": models.UserStatusPending})
+	update := bson.M{"$set": bson.M{"status": models.UserStatusActive, "verified_at": verifiedAt}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
 	}
 
-	// Create a GeoJSON query for finding users within the specified radius
-	query := bson.M{
-		"location": bson.M{
-			"$nearSphere": bson.M{
-				"$geometry":    point,
-				"$maxDistance": radiusInMeters,
-			},
+	return result.ModifiedCount > 0, nil
+}
+
+// AnonymizeUser closes a user's account, replacing their username and clearing their personal data.
+// The user is kept, so that the records referring to them stay valid, and every token issued to them is revoked.
+func (r *userRepository) AnonymizeUser(id primitive.ObjectID, username string, closedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := notDeleted(bson.M{"_id": id})
+	update := bson.M{
+		"$set": bson.M{
+			"username":   username,
+			"password":   "",
+			"email":      "",
+			"first_name": "",
+			"last_name":  "",
+			"location":   "",
+			"latitude":   0.0,
+			"longitude":  0.0,
+			"status":     models.UserStatusClosed,
+			"closed_at":  closedAt,
 		},
+		"$unset": bson.M{"phone": "", "crops": ""},
+		"$inc":   bson.M{"token_version": 1},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return duplicateKeyError(err, userIndexFields)
+	}
+
+	return nil
+}
+
+// DeleteUser soft-deletes a user by their ID.
+func (r *userRepository) DeleteUser(id string) error {
+	return softDelete(r.collection, id)
+}
+
+// FindDeletedUserByID retrieves a soft-deleted user by their ID.
+func (r *userRepository) FindDeletedUserByID(id string) (*models.User, error) {
+	var user models.User
+	found, err := findDeleted(r.collection, id, &user)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// RestoreUser undoes the soft deletion of a user.
+func (r *userRepository) RestoreUser(id string) error {
+	return restoreDeleted(r.collection, id)
+}
+
+// PurgeDeletedUsers permanently removes the users soft-deleted before the given time and returns their IDs.
+func (r *userRepository) PurgeDeletedUsers(deletedBefore time.Time) ([]primitive.ObjectID, error) {
+	return purgeDeleted(r.collection, deletedBefore)
+}
+
+// FindDiscoverableUsersInBox retrieves the users who opted in to being discovered and are located in a box.
+func (r *userRepository) FindDiscoverableUsersInBox(box models.BoundingBox) ([]models.User, error) {
+	query := notDeleted(bson.M{
+		"discoverable": true,
+		"status":       bson.M{"$ne": models.UserStatusClosed},
+		"latitude":     bson.M{"$gte": box.MinLatitude, "$lte": box.MaxLatitude},
+	})
+
+	// A box crossing the antimeridian is split into its eastern and western halves
+	if box.MinLongitude <= box.MaxLongitude {
+		query["longitude"] = bson.M{"$gte": box.MinLongitude, "$lte": box.MaxLongitude}
+	} else {
+		query["$or"] = bson.A{
+			bson.M{"longitude": bson.M{"$gte": box.MinLongitude}},
+			bson.M{"longitude": bson.M{"$lte": box.MaxLongitude}},
+		}
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -141,10 +307,28 @@ func (r *userRepository) FindNearbyUsers(latitude, longitude float64, radiusInMe
 	}
 	defer cursor.Close(ctx)
 
-	var nearbyUsers []models.User
-	if err := cursor.All(ctx, &nearbyUsers); err != nil {
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
 		return nil, err
 	}
 
-	return nearbyUsers, nil
+	return users, nil
+}
+
+// SetUserRole grants a role to a user, or removes their role when it is empty.
+func (r *userRepository) SetUserRole(id primitive.ObjectID, role string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"role": role}}
+	if role == "" {
+		update = bson.M{"$unset": bson.M{"role": ""}}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, notDeleted(bson.M{"_id": id}), update)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,93 @@
+package search
+
+// MaxEdits returns the number of typos tolerated for a term of the given length.
+// Short terms must match exactly since a single edit changes them completely.
+func MaxEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// EditDistance returns the optimal string alignment distance between a and b,
+// counting insertions, deletions, substitutions and transpositions of adjacent
+// characters. It stops early and returns max+1 once the distance exceeds max.
+func EditDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if abs(len(ra)-len(rb)) > max {
+		return max + 1
+	}
+
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && prev2[j-2]+1 < curr[j] {
+				curr[j] = prev2[j-2] + 1
+			}
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	if prev[len(rb)] > max {
+		return max + 1
+	}
+	return prev[len(rb)]
+}
+
+// matchTerm reports whether a query term matches a term, exactly or within the typos it tolerates,
+// and the number of edits it takes.
+func matchTerm(queryTerm QueryTerm, term string) (int, bool) {
+	if term == queryTerm.Text {
+		return 0, true
+	}
+	if !queryTerm.Fuzzy {
+		return 0, false
+	}
+
+	maxEdits := MaxEdits(queryTerm.Text)
+	if maxEdits == 0 {
+		return 0, false
+	}
+	edits := EditDistance(queryTerm.Text, term, maxEdits)
+	return edits, edits <= maxEdits
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
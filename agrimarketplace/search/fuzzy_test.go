@@ -0,0 +1,88 @@
+package search
+
+import "testing"
+
+func TestMaxEdits(t *testing.T) {
+	tests := []struct {
+		term string
+		want int
+	}{
+		{"dap", 0},
+		{"urea", 1},
+		{"mustard", 1},
+		{"fertilizer", 2},
+		{"बीज", 0},
+		{"कीटनाशक", 1},
+	}
+
+	for _, tt := range tests {
+		if got := MaxEdits(tt.term); got != tt.want {
+			t.Errorf("MaxEdits(%q) = %d, want %d", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"urea", "urea", 1, 0},
+		{"urea", "uera", 1, 1},  // Transposition
+		{"urea", "ureaa", 1, 1}, // Insertion
+		{"urea", "ure", 1, 1},   // Deletion
+		{"urea", "uria", 1, 1},  // Substitution
+		{"seed", "seeds", 2, 1}, // Plural
+		{"fertilizer", "fertiliser", 2, 1},
+		{"fertilizer", "fertlizr", 2, 2},
+		{"pesticide", "insecticide", 2, 3}, // Capped at max+1
+		{"wheat", "cotton", 1, 2},          // Capped at max+1
+		{"abc", "abcdef", 1, 2},            // Length difference alone exceeds max
+		{"गेहूं", "गेहू", 1, 1},
+	}
+
+	for _, tt := range tests {
+		if got := EditDistance(tt.a, tt.b, tt.max); got != tt.want {
+			t.Errorf("EditDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestMatchingTerms(t *testing.T) {
+	vocabulary := []string{"urea", "fertilizer", "seed", "seeds", "dap", "map", "paddy", "rice", "mustard"}
+	synonyms := NewSynonyms([][]string{{"dhan", "paddy", "rice"}})
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"urea", []string{"urea"}},
+		{"uera", []string{"urea"}},            // Typo within one edit
+		{"fertlizer", []string{"fertilizer"}}, // Typo within two edits
+		{"seed", []string{"seed", "seeds"}},
+		{"dap", []string{"dap"}}, // Too short to tolerate typos, so "map" is not matched
+		{"dhan", []string{"paddy", "rice"}},
+		{"cotton", nil},
+	}
+
+	for _, tt := range tests {
+		got := ParseQuery(tt.query, synonyms).MatchingTerms(vocabulary)
+		if !sameTerms(got, tt.want) {
+			t.Errorf("MatchingTerms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+// sameTerms reports whether two lists hold the same terms, in any order.
+func sameTerms(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, term := range a {
+		if !contains(b, term) {
+			return false
+		}
+	}
+	return true
+}
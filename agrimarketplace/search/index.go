@@ -0,0 +1,220 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 ranking parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field is a piece of document text with its relative importance.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Document is the unit indexed and returned by an Index.
+type Document struct {
+	ID     string
+	Fields []Field
+}
+
+// Result is a matching document and its relevance score.
+type Result struct {
+	ID    string
+	Score float64
+}
+
+// CorpusStats describes the whole collection that documents are ranked against, when an index
+// holds only some of its documents.
+type CorpusStats struct {
+	Documents     int
+	AverageLength float64 // Weighted number of terms of a document, on average
+	// DocumentFrequency holds the number of documents of the collection each term appears in.
+	DocumentFrequency map[string]int
+}
+
+// Index is an in-memory inverted index with typo-tolerant, BM25-ranked search.
+// It is safe for concurrent use.
+type Index struct {
+	mu sync.RWMutex
+	// postings maps a term to the weighted frequency of the term in each document.
+	postings map[string]map[string]float64
+	// lengths holds the weighted number of terms of each document.
+	lengths     map[string]float64
+	totalLength float64
+}
+
+// NewIndex creates an empty Index.
+func NewIndex() *Index {
+	return &Index{
+		postings: map[string]map[string]float64{},
+		lengths:  map[string]float64{},
+	}
+}
+
+// Add indexes a document, replacing any previous version with the same ID.
+func (i *Index) Add(doc Document) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(doc.ID)
+
+	length := 0.0
+	for _, field := range doc.Fields {
+		weight := field.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		for _, term := range Tokenize(field.Text) {
+			if i.postings[term] == nil {
+				i.postings[term] = map[string]float64{}
+			}
+			i.postings[term][doc.ID] += weight
+			length += weight
+		}
+	}
+
+	i.lengths[doc.ID] = length
+	i.totalLength += length
+}
+
+// Remove deletes a document from the index.
+func (i *Index) Remove(id string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(id)
+}
+
+func (i *Index) remove(id string) {
+	length, ok := i.lengths[id]
+	if !ok {
+		return
+	}
+
+	for term, docs := range i.postings {
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	delete(i.lengths, id)
+	i.totalLength -= length
+}
+
+// Len returns the number of indexed documents.
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return len(i.lengths)
+}
+
+// Search returns up to limit documents matching the query, most relevant first.
+// A limit of zero or less returns every match.
+func (i *Index) Search(query Query, limit int) []Result {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if len(i.lengths) == 0 {
+		return nil
+	}
+	return i.search(query, limit, len(i.lengths), i.totalLength/float64(len(i.lengths)), nil)
+}
+
+// SearchWithStats is like Search, but scores the indexed documents as members of a larger
+// collection described by stats, so that their scores do not depend on which documents were indexed.
+func (i *Index) SearchWithStats(query Query, limit int, stats CorpusStats) []Result {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if len(i.lengths) == 0 {
+		return nil
+	}
+	averageLength := stats.AverageLength
+	if averageLength <= 0 {
+		averageLength = i.totalLength / float64(len(i.lengths))
+	}
+	return i.search(query, limit, stats.Documents, averageLength, stats.DocumentFrequency)
+}
+
+// search scores the documents matching the query with BM25 over a collection of the given number
+// of documents and average length. Document frequencies are taken from frequencies, and from the
+// index for the terms it lacks or undercounts, which stale collection statistics can do.
+func (i *Index) search(query Query, limit int, documents int, averageLength float64, frequencies map[string]int) []Result {
+	if documents < len(i.lengths) {
+		documents = len(i.lengths)
+	}
+
+	// best holds, for each document, the best score obtained by each query term group
+	best := map[string]map[int]float64{}
+	for _, queryTerm := range query.Terms {
+		for term, edits := range i.matchingTerms(queryTerm) {
+			docs := i.postings[term]
+			documentFrequency := len(docs)
+			if frequencies[term] > documentFrequency {
+				documentFrequency = frequencies[term]
+			}
+			if documentFrequency > documents {
+				documentFrequency = documents
+			}
+			idf := math.Log(1 + (float64(documents)-float64(documentFrequency)+0.5)/(float64(documentFrequency)+0.5))
+			for id, frequency := range docs {
+				norm := frequency * (bm25K1 + 1) / (frequency + bm25K1*(1-bm25B+bm25B*i.lengths[id]/averageLength))
+				score := idf * norm * queryTerm.Weight / float64(1+edits)
+				if best[id] == nil {
+					best[id] = map[int]float64{}
+				}
+				if score > best[id][queryTerm.Group] {
+					best[id][queryTerm.Group] = score
+				}
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(best))
+	for id, groups := range best {
+		result := Result{ID: id}
+		for _, score := range groups {
+			result.Score += score
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].ID < results[b].ID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+// matchingTerms returns the indexed terms matched by a query term and the number of edits for each.
+func (i *Index) matchingTerms(queryTerm QueryTerm) map[string]int {
+	matches := map[string]int{}
+	if _, ok := i.postings[queryTerm.Text]; ok {
+		matches[queryTerm.Text] = 0
+	}
+	if !queryTerm.Fuzzy || MaxEdits(queryTerm.Text) == 0 {
+		return matches
+	}
+
+	for term := range i.postings {
+		if edits, ok := matchTerm(queryTerm, term); ok {
+			matches[term] = edits
+		}
+	}
+
+	return matches
+}
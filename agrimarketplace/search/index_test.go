@@ -0,0 +1,141 @@
+package search
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// testCatalog is a small catalog whose names weigh three times their descriptions, like products.
+var testCatalog = []Document{
+	{ID: "urea", Fields: []Field{{Text: "Urea 45 kg", Weight: 3}, {Text: "Nitrogen fertilizer for every crop", Weight: 1}}},
+	{ID: "dap", Fields: []Field{{Text: "DAP fertilizer", Weight: 3}, {Text: "Phosphate fertilizer for sowing", Weight: 1}}},
+	{ID: "sprayer", Fields: []Field{{Text: "Knapsack sprayer", Weight: 3}, {Text: "Sprays pesticide and liquid fertilizer", Weight: 1}}},
+	{ID: "paddy", Fields: []Field{{Text: "Hybrid paddy seed", Weight: 3}, {Text: "High yield rice for kharif", Weight: 1}}},
+	{ID: "wheat", Fields: []Field{{Text: "Wheat seed HD 2967", Weight: 3}, {Text: "Rabi wheat variety", Weight: 1}}},
+}
+
+func TestIndexSearchRanking(t *testing.T) {
+	index := NewIndex()
+	for _, doc := range testCatalog {
+		index.Add(doc)
+	}
+	synonyms := NewSynonyms([][]string{{"khaad", "fertilizer"}, {"dhan", "paddy"}})
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		// The product named after the term ranks above those that only mention it,
+		// and of those the shorter document ranks first
+		{"name above description", "fertilizer", 0, []string{"dap", "sprayer", "urea"}},
+		{"typo", "fertlizer", 0, []string{"dap", "sprayer", "urea"}},
+		{"synonym", "khaad", 0, []string{"dap", "sprayer", "urea"}},
+		{"limit", "fertilizer", 2, []string{"dap", "sprayer"}},
+		// A document matching every typed term ranks above those matching one
+		{"all terms first", "wheat seed", 0, []string{"wheat", "paddy"}},
+		// Of two products named after the term, the shorter one ranks first
+		{"shorter document first", "seed", 0, []string{"paddy", "wheat"}},
+		{"synonym of a name", "dhan", 0, []string{"paddy"}},
+		{"no match", "tractor", 0, []string{}},
+	}
+
+	for _, tt := range tests {
+		results := index.Search(ParseQuery(tt.query, synonyms), tt.limit)
+		got := []string{}
+		for _, result := range results {
+			got = append(got, result.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Search(%q) = %q, want %q", tt.name, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestIndexTypoScoresBelowExact(t *testing.T) {
+	index := NewIndex()
+	index.Add(Document{ID: "exact", Fields: []Field{{Text: "urea", Weight: 1}}})
+	index.Add(Document{ID: "typo", Fields: []Field{{Text: "uria", Weight: 1}}})
+
+	results := index.Search(ParseQuery("urea", nil), 0)
+	if len(results) != 2 || results[0].ID != "exact" || results[0].Score <= results[1].Score {
+		t.Fatalf("Search(%q) = %+v, want the exact match scored above the typo", "urea", results)
+	}
+}
+
+func TestIndexAddReplacesAndRemoves(t *testing.T) {
+	index := NewIndex()
+	index.Add(Document{ID: "p1", Fields: []Field{{Text: "urea", Weight: 1}}})
+	index.Add(Document{ID: "p1", Fields: []Field{{Text: "potash", Weight: 1}}})
+
+	if results := index.Search(ParseQuery("urea", nil), 0); len(results) != 0 {
+		t.Errorf("Search(%q) after replacement = %+v, want no results", "urea", results)
+	}
+	if results := index.Search(ParseQuery("potash", nil), 0); len(results) != 1 {
+		t.Errorf("Search(%q) = %+v, want the replaced document", "potash", results)
+	}
+
+	index.Remove("p1")
+	if index.Len() != 0 {
+		t.Errorf("Len() after Remove = %d, want 0", index.Len())
+	}
+}
+
+func TestIndexSearchWithStatsScoresLikeTheWholeCollection(t *testing.T) {
+	full := NewIndex()
+	for _, doc := range testCatalog {
+		full.Add(doc)
+	}
+	want := map[string]float64{}
+	query := ParseQuery("fertilizer sprayer", nil)
+	for _, result := range full.Search(query, 0) {
+		want[result.ID] = result.Score
+	}
+
+	// Only some of the documents matching the query are candidates
+	candidates := NewIndex()
+	candidates.Add(testCatalog[1])
+	candidates.Add(testCatalog[2])
+
+	got := candidates.SearchWithStats(query, 0, corpusStats(testCatalog))
+	if len(got) != 2 {
+		t.Fatalf("SearchWithStats() = %+v, want both candidates", got)
+	}
+	for _, result := range got {
+		if math.Abs(result.Score-want[result.ID]) > 1e-9 {
+			t.Errorf("SearchWithStats() scored %s %v, want %v as in the whole collection", result.ID, result.Score, want[result.ID])
+		}
+	}
+
+	// Ranked on their own, the candidates score differently
+	local := candidates.Search(query, 0)
+	if math.Abs(local[0].Score-want[local[0].ID]) < 1e-9 {
+		t.Errorf("Search() scored %s %v, want a score depending on the candidates", local[0].ID, local[0].Score)
+	}
+	// Missing statistics fall back to those of the candidates
+	if fallback := candidates.SearchWithStats(query, 0, CorpusStats{}); !reflect.DeepEqual(fallback, local) {
+		t.Errorf("SearchWithStats() without statistics = %+v, want %+v", fallback, local)
+	}
+}
+
+// corpusStats computes the statistics of a collection of documents as an index holding all of them would.
+func corpusStats(docs []Document) CorpusStats {
+	stats := CorpusStats{Documents: len(docs), DocumentFrequency: map[string]int{}}
+	totalLength := 0.0
+	for _, doc := range docs {
+		seen := map[string]bool{}
+		for _, field := range doc.Fields {
+			for _, term := range Tokenize(field.Text) {
+				totalLength += field.Weight
+				if !seen[term] {
+					seen[term] = true
+					stats.DocumentFrequency[term]++
+				}
+			}
+		}
+	}
+	stats.AverageLength = totalLength / float64(len(docs))
+	return stats
+}
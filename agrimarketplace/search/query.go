@@ -0,0 +1,69 @@
+package search
+
+// synonymWeight is the relevance of a synonym relative to the term typed by the user.
+const synonymWeight = 0.8
+
+// QueryTerm is a single term of a parsed query.
+type QueryTerm struct {
+	Text   string
+	Weight float64
+	// Fuzzy reports whether the term may match catalog terms within MaxEdits typos.
+	Fuzzy bool
+	// Group identifies the typed term that this term was derived from. A document
+	// is scored once per group, by its best matching term.
+	Group int
+}
+
+// Query is a tokenized search query expanded with synonyms.
+type Query struct {
+	Terms []QueryTerm
+}
+
+// ParseQuery tokenizes a search string and expands each term with its synonyms.
+func ParseQuery(text string, synonyms Synonyms) Query {
+	var query Query
+	seen := map[string]bool{}
+
+	for group, token := range Tokenize(text) {
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+		query.Terms = append(query.Terms, QueryTerm{Text: token, Weight: 1, Fuzzy: true, Group: group})
+
+		for _, synonym := range synonyms.Expand(token) {
+			query.Terms = append(query.Terms, QueryTerm{Text: synonym, Weight: synonymWeight, Group: group})
+		}
+	}
+
+	return query
+}
+
+// Texts returns the text of every query term.
+func (q Query) Texts() []string {
+	texts := make([]string, 0, len(q.Terms))
+	for _, term := range q.Terms {
+		texts = append(texts, term.Text)
+	}
+	return texts
+}
+
+// MatchingTerms returns the terms of a vocabulary matched by any term of the query,
+// exactly or within the typos the term tolerates.
+func (q Query) MatchingTerms(vocabulary []string) []string {
+	var matches []string
+	for _, term := range vocabulary {
+		for _, queryTerm := range q.Terms {
+			if _, ok := matchTerm(queryTerm, term); ok {
+				matches = append(matches, term)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// Empty reports whether the query has no terms.
+func (q Query) Empty() bool {
+	return len(q.Terms) == 0
+}
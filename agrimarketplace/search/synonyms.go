@@ -0,0 +1,76 @@
+package search
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Synonyms maps a term to the other terms that should match it, such as
+// vernacular names ("khaad", "beej") to catalog vocabulary ("fertilizer", "seed").
+type Synonyms map[string][]string
+
+// NewSynonyms builds a dictionary from groups of equivalent terms.
+// Every term of a group matches all the other terms of the same group.
+func NewSynonyms(groups [][]string) Synonyms {
+	synonyms := Synonyms{}
+	for _, group := range groups {
+		var terms []string
+		for _, phrase := range group {
+			terms = append(terms, Tokenize(phrase)...)
+		}
+		for _, term := range terms {
+			for _, other := range terms {
+				if other != term && !contains(synonyms[term], other) {
+					synonyms[term] = append(synonyms[term], other)
+				}
+			}
+		}
+	}
+
+	return synonyms
+}
+
+// LoadSynonyms reads a JSON array of synonym groups, e.g. [["khaad", "fertilizer"], ["beej", "seed"]].
+func LoadSynonyms(r io.Reader) (Synonyms, error) {
+	var groups [][]string
+	if err := json.NewDecoder(r).Decode(&groups); err != nil {
+		return nil, err
+	}
+
+	return NewSynonyms(groups), nil
+}
+
+// Expand returns the synonyms of a term. Misspelt terms are matched against the
+// dictionary with the same typo tolerance as catalog terms.
+func (s Synonyms) Expand(term string) []string {
+	if synonyms, ok := s[term]; ok {
+		return synonyms
+	}
+
+	maxEdits := MaxEdits(term)
+	if maxEdits == 0 {
+		return nil
+	}
+
+	var expanded []string
+	for key, synonyms := range s {
+		if EditDistance(term, key, maxEdits) <= maxEdits {
+			for _, synonym := range append([]string{key}, synonyms...) {
+				if !contains(expanded, synonym) {
+					expanded = append(expanded, synonym)
+				}
+			}
+		}
+	}
+
+	return expanded
+}
+
+func contains(terms []string, term string) bool {
+	for _, t := range terms {
+		if t == term {
+			return true
+		}
+	}
+	return false
+}
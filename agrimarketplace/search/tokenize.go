@@ -0,0 +1,13 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenize splits text into lower-case terms made of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r) && !unicode.Is(unicode.Mc, r)
+	})
+}
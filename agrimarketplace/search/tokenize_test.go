@@ -0,0 +1,29 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"   ", []string{}},
+		{"Urea", []string{"urea"}},
+		{"DAP 18:46:0 Fertilizer", []string{"dap", "18", "46", "0", "fertilizer"}},
+		{"Neem-oil, 500ml (organic)", []string{"neem", "oil", "500ml", "organic"}},
+		{"hybrid  paddy\tseeds\n", []string{"hybrid", "paddy", "seeds"}},
+		// Devanagari vowel signs and viramas are part of words, not separators
+		{"गेहूं बीज", []string{"गेहूं", "बीज"}},
+		{"खाद", []string{"खाद"}},
+	}
+
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
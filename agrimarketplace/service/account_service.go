@@ -0,0 +1,441 @@
+package service
+
+import (
+	"agrimarketplace/auth"
+	"agrimarketplace/mail"
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"agrimarketplace/sms"
+	"agrimarketplace/validation"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// verifyEmailPurpose is the purpose of the tokens sent to verify email addresses.
+const verifyEmailPurpose = "verify-email"
+
+// AccountConfig holds the settings of account registration.
+type AccountConfig struct {
+	// VerificationTTL is how long a verification link remains valid.
+	VerificationTTL time.Duration
+	// VerificationURL is the page verification links point to; the token is added as the token query parameter.
+	VerificationURL string
+	// PasswordResetTTL is how long a password reset link remains valid.
+	PasswordResetTTL time.Duration
+	// PasswordResetURL is the page password reset links point to; the token is added as the token query parameter.
+	PasswordResetURL string
+	// MaxPasswordAttempts is the number of password changes, and of password reset requests,
+	// accepted for an account within PasswordAttemptWindow.
+	MaxPasswordAttempts   int
+	PasswordAttemptWindow time.Duration
+}
+
+// AccountService defines the interface for registering users and verifying their accounts.
+type AccountService interface {
+	Register(user *models.User) error
+	ConfirmEmail(token string) (*models.User, error)
+	UpdateUser(user *models.User) error
+	ResendVerification(username string) error
+	SignIn(login, password string, device models.DeviceInfo) (*models.Login, error)
+	ChangePassword(userID, currentPassword, newPassword string) error
+	RequestPasswordReset(login, channel string) error
+	ResetPassword(token, newPassword string) error
+}
+
+// accountService is an implementation of the AccountService interface.
+type accountService struct {
+	userRepo          repository.UserRepository
+	passwordResetRepo repository.PasswordResetRepository
+	rateLimitRepo     repository.RateLimitRepository
+	userService       UserService
+	sessionService    SessionService
+	mailer            mail.Mailer
+	smsSender         sms.SMSSender
+	signer            *auth.Signer
+	config            AccountConfig
+}
+
+// NewAccountService creates a new instance of the accountService.
+func NewAccountService(userRepo repository.UserRepository, passwordResetRepo repository.PasswordResetRepository, rateLimitRepo repository.RateLimitRepository, userService UserService, sessionService SessionService, mailer mail.Mailer, smsSender sms.SMSSender, signer *auth.Signer, config AccountConfig) AccountService {
+	return &accountService{
+		userRepo:          userRepo,
+		passwordResetRepo: passwordResetRepo,
+		rateLimitRepo:     rateLimitRepo,
+		userService:       userService,
+		sessionService:    sessionService,
+		mailer:            mailer,
+		smsSender:         smsSender,
+		signer:            signer,
+		config:            config,
+	}
+}
+
+// Register creates a user pending verification from their plain-text password and emails them a
+// verification link. The account is activated by ConfirmEmail. A *ConflictError is returned when
+// the username or email address is already taken.
+func (s *accountService) Register(user *models.User) error {
+	normalizeUserIdentity(user)
+
+	// Registration needs an email address to verify, and a password
+	errs := validation.Errors{}
+	if user.Email == "" {
+		errs.Add("email", "is required")
+	}
+	if len([]rune(user.Password)) < auth.MinPasswordLength {
+		errs.Add("password", fmt.Sprintf("must be at least %d characters", auth.MinPasswordLength))
+	}
+	userSchema.Check(user, errs)
+	if err := errs.Err(); err != nil {
+		return err
+	}
+
+	var err error
+	user.Password, err = auth.HashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+
+	// Generate an ObjectID for the user
+	user.ID = primitive.NewObjectID()
+	user.Status = models.UserStatusPending
+	user.VerifiedAt = nil
+	user.Phone = "" // Phone numbers are added by signing in with a one-time password
+
+	if err := s.userService.InsertUser(user); err != nil {
+		return err
+	}
+
+	// The user can ask for another link if this one is lost
+	if err := s.sendVerification(user); err != nil {
+		log.Printf("Error sending verification email to user %s: %v", user.ID.Hex(), err)
+	}
+
+	return nil
+}
+
+// ConfirmEmail activates the account a verification token was issued for.
+// Confirming an account that is already active succeeds without changing it.
+func (s *accountService) ConfirmEmail(token string) (*models.User, error) {
+	claims, err := s.signer.Verify(token, verifyEmailPurpose)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindUserByID(claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if claims.Version != user.TokenVersion {
+		return nil, auth.ErrInvalidToken
+	}
+	if user.IsVerified() {
+		return user, nil
+	}
+
+	now := time.Now()
+	if _, err := s.userRepo.ActivateUser(user.ID, now); err != nil {
+		return nil, err
+	}
+
+	user.Status = models.UserStatusActive
+	user.VerifiedAt = &now
+	return user, nil
+}
+
+// UpdateUser updates a user's profile. When the email address changes, the account returns to pending
+// verification and a verification link is emailed to the new address.
+func (s *accountService) UpdateUser(user *models.User) error {
+	existingUser, err := s.userRepo.FindUserByID(user.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if existingUser == nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.userService.UpdateUser(user); err != nil {
+		return err
+	}
+	if user.Email == "" || user.Email == existingUser.Email {
+		return nil
+	}
+
+	// The user can ask for another link if this one is lost
+	if err := s.sendVerification(user); err != nil {
+		log.Printf("Error sending verification email to user %s: %v", user.ID.Hex(), err)
+	}
+
+	return nil
+}
+
+// ResendVerification emails a new verification link to a user pending verification.
+func (s *accountService) ResendVerification(username string) error {
+	user, err := s.userRepo.FindUserByUsername(strings.ToLower(strings.TrimSpace(username)))
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.IsVerified() {
+		return ErrUserAlreadyVerified
+	}
+
+	return s.sendVerification(user)
+}
+
+// SignIn starts a session for the user with a username, email address or phone number and their
+// password. Accounts pending verification and closed accounts cannot sign in. Unknown logins and
+// wrong passwords both return ErrInvalidCredentials, so the response does not reveal which accounts exist.
+func (s *accountService) SignIn(login, password string, device models.DeviceInfo) (*models.Login, error) {
+	user, err := s.findUserByLogin(login)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	// Attempts are counted whether or not they succeed, so the password cannot be guessed
+	if err := s.checkRateLimit("sign-in:" + user.ID.Hex()); err != nil {
+		return nil, err
+	}
+	if user.Password == "" || !auth.CheckPassword(user.Password, password) {
+		return nil, ErrInvalidCredentials
+	}
+	if user.IsClosed() {
+		return nil, ErrAccountClosed
+	}
+	if !user.IsVerified() {
+		return nil, ErrUserNotVerified
+	}
+
+	return s.sessionService.StartSession(user, device)
+}
+
+// ChangePassword replaces the password of a user who knows their current one.
+// Changing the password revokes every token issued to the user and signs them out of every session.
+func (s *accountService) ChangePassword(userID, currentPassword, newPassword string) error {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
+	// Attempts are counted whether or not they succeed, so the current password cannot be guessed
+	if err := s.checkRateLimit("password-change:" + user.ID.Hex()); err != nil {
+		return err
+	}
+	if user.Password == "" || !auth.CheckPassword(user.Password, currentPassword) {
+		return ErrIncorrectPassword
+	}
+
+	passwordHash, err := auth.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.setPassword(user, passwordHash)
+}
+
+// RequestPasswordReset sends a single-use password reset link to the user with a username, email
+// address or phone number, by email or SMS. Without a channel, email is used when the user has an
+// email address. Unknown accounts are ignored so the response does not reveal which accounts exist.
+func (s *accountService) RequestPasswordReset(login, channel string) error {
+	if channel != "" && channel != models.ResetChannelEmail && channel != models.ResetChannelSMS {
+		return ErrInvalidResetChannel
+	}
+
+	user, err := s.findUserByLogin(login)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	if channel == "" {
+		channel = models.ResetChannelSMS
+		if user.Email != "" {
+			channel = models.ResetChannelEmail
+		}
+	}
+	if (channel == models.ResetChannelEmail && user.Email == "") || (channel == models.ResetChannelSMS && user.Phone == "") {
+		return ErrInvalidResetChannel
+	}
+
+	if err := s.checkRateLimit("password-reset:" + user.ID.Hex()); err != nil {
+		return err
+	}
+
+	token, err := auth.GenerateToken(24)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	reset := &models.PasswordReset{
+		ID:           primitive.NewObjectID(),
+		UserID:       user.ID,
+		TokenHash:    s.signer.Digest(token),
+		TokenVersion: user.TokenVersion,
+		Channel:      channel,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(s.config.PasswordResetTTL),
+	}
+	if err := s.passwordResetRepo.InsertPasswordReset(reset); err != nil {
+		return err
+	}
+
+	link, err := tokenLink(s.config.PasswordResetURL, token)
+	if err != nil {
+		return err
+	}
+	minutes := int(s.config.PasswordResetTTL.Minutes())
+
+	if channel == models.ResetChannelSMS {
+		return s.smsSender.Send(user.Phone, fmt.Sprintf("Reset your password within %d minutes: %s", minutes, link))
+	}
+	return s.mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nYou can choose a new password by opening the link below within %d minutes:\n\n%s\n\nIf you did not ask to reset your password, you can ignore this message.",
+			user.Username, minutes, link),
+	})
+}
+
+// ResetPassword sets a new password from a password reset token. The token can only be used once,
+// and resetting the password revokes every token issued to the user, including other reset links.
+func (s *accountService) ResetPassword(token, newPassword string) error {
+	if len([]rune(newPassword)) < auth.MinPasswordLength {
+		return auth.ErrWeakPassword
+	}
+
+	reset, err := s.passwordResetRepo.FindPasswordResetByTokenHash(s.signer.Digest(token))
+	if err != nil {
+		return err
+	}
+	if reset == nil || reset.UsedAt != nil {
+		return ErrInvalidResetToken
+	}
+	now := time.Now()
+	if now.After(reset.ExpiresAt) {
+		return ErrResetTokenExpired
+	}
+
+	user, err := s.userRepo.FindUserByID(reset.UserID.Hex())
+	if err != nil {
+		return err
+	}
+	if user == nil || user.TokenVersion != reset.TokenVersion {
+		return ErrInvalidResetToken
+	}
+
+	passwordHash, err := auth.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// Marking the token used first keeps two concurrent requests from both resetting the password
+	used, err := s.passwordResetRepo.MarkPasswordResetUsed(reset.ID, now)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidResetToken
+	}
+
+	return s.setPassword(user, passwordHash)
+}
+
+// setPassword replaces a user's password hash, revoking their tokens and signing them out everywhere.
+func (s *accountService) setPassword(user *models.User, passwordHash string) error {
+	if err := s.userRepo.SetUserPassword(user.ID, passwordHash); err != nil {
+		return err
+	}
+
+	// The bumped token version already rejects the user's access tokens; revoking the
+	// sessions also stops their refresh tokens and clears them from the user's session list
+	_, err := s.sessionService.RevokeAllSessions(user.ID.Hex(), models.SessionRevokedPasswordChanged)
+	return err
+}
+
+// findUserByLogin finds the user with a username, email address or phone number.
+func (s *accountService) findUserByLogin(login string) (*models.User, error) {
+	login = strings.ToLower(strings.TrimSpace(login))
+	if login == "" {
+		return nil, nil
+	}
+
+	user, err := s.userRepo.FindUserByUsername(login)
+	if err != nil || user != nil {
+		return user, err
+	}
+
+	if strings.Contains(login, "@") {
+		return s.userRepo.FindUserByEmail(login)
+	}
+
+	phone, err := normalizePhone(login)
+	if err != nil {
+		return nil, nil
+	}
+	return s.userRepo.FindUserByPhone(phone)
+}
+
+// checkRateLimit counts an attempt against a key, returning ErrTooManyAttempts once the
+// configured number of attempts has been made within the current window.
+func (s *accountService) checkRateLimit(key string) error {
+	attempts, err := s.rateLimitRepo.Hit(key, s.config.PasswordAttemptWindow, time.Now())
+	if err != nil {
+		return err
+	}
+	if attempts > s.config.MaxPasswordAttempts {
+		return ErrTooManyAttempts
+	}
+
+	return nil
+}
+
+// tokenLink adds a token to a link as its token query parameter.
+func tokenLink(rawURL, token string) (string, error) {
+	link, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
+
+// sendVerification emails a user a link carrying a signed verification token.
+func (s *accountService) sendVerification(user *models.User) error {
+	token, err := s.signer.Sign(user.ID.Hex(), verifyEmailPurpose, user.TokenVersion, s.config.VerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link, err := tokenLink(s.config.VerificationURL, token)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nPlease verify your email address by opening the link below within %d hours:\n\n%s\n\nIf you did not create an account, you can ignore this message.",
+			user.Username, int(s.config.VerificationTTL.Hours()), link),
+	})
+}
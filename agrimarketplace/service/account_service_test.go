@@ -0,0 +1,106 @@
+package service
+
+import (
+	"agrimarketplace/auth"
+	"agrimarketplace/models"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newTestAccounts creates an account service and the session service it signs users in with,
+// backed by in-memory repositories holding the given users.
+func newTestAccounts(users ...*models.User) (AccountService, SessionService) {
+	userRepo := newFakeUserRepository(users...)
+	signer := auth.NewSigner([]byte("test signing key"))
+	sessionService := NewSessionService(newFakeSessionRepository(), userRepo, signer, SessionConfig{
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	})
+	accountService := NewAccountService(userRepo, nil, newFakeRateLimitRepository(), nil, sessionService, nil, nil, signer, AccountConfig{
+		MaxPasswordAttempts:   5,
+		PasswordAttemptWindow: time.Minute,
+	})
+	return accountService, sessionService
+}
+
+// newTestUser creates a user with a password and the given status.
+func newTestUser(t *testing.T, username, password, status string) *models.User {
+	t.Helper()
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &models.User{ID: primitive.NewObjectID(), Username: username, Email: username + "@example.com", Password: hash, Status: status}
+}
+
+func TestSignInIssuesTokenRejectedAfterPasswordChange(t *testing.T) {
+	user := newTestUser(t, "ravi", "old password", models.UserStatusActive)
+	accounts, sessions := newTestAccounts(user)
+
+	login, err := accounts.SignIn("Ravi@example.com", "old password", models.DeviceInfo{Name: "test"})
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	if login.AccessToken == "" || login.RefreshToken == "" {
+		t.Fatalf("SignIn() = %+v, want access and refresh tokens", login)
+	}
+	authenticated, err := sessions.Authenticate(login.AccessToken)
+	if err != nil || authenticated.ID != user.ID {
+		t.Fatalf("Authenticate() = %v, %v, want user %s", authenticated, err, user.ID.Hex())
+	}
+
+	if err := accounts.ChangePassword(user.ID.Hex(), "old password", "new password"); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if _, err := sessions.Authenticate(login.AccessToken); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Authenticate() after password change error = %v, want %v", err, auth.ErrInvalidToken)
+	}
+	if _, err := accounts.SignIn("ravi", "old password", models.DeviceInfo{}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("SignIn() with old password error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if _, err := accounts.SignIn("ravi", "new password", models.DeviceInfo{}); err != nil {
+		t.Errorf("SignIn() with new password error = %v", err)
+	}
+}
+
+func TestSignInRejections(t *testing.T) {
+	accounts, _ := newTestAccounts(
+		newTestUser(t, "active", "password", models.UserStatusActive),
+		newTestUser(t, "pending", "password", models.UserStatusPending),
+		newTestUser(t, "closed", "password", models.UserStatusClosed),
+	)
+
+	tests := []struct {
+		login    string
+		password string
+		want     error
+	}{
+		{"active", "wrong password", ErrInvalidCredentials},
+		{"unknown", "password", ErrInvalidCredentials},
+		{"", "password", ErrInvalidCredentials},
+		{"pending", "password", ErrUserNotVerified},
+		{"closed", "password", ErrAccountClosed},
+		// The account's status is only revealed to someone who knows its password
+		{"pending", "wrong password", ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		if _, err := accounts.SignIn(tt.login, tt.password, models.DeviceInfo{}); !errors.Is(err, tt.want) {
+			t.Errorf("SignIn(%q, %q) error = %v, want %v", tt.login, tt.password, err, tt.want)
+		}
+	}
+}
+
+func TestSignInRateLimited(t *testing.T) {
+	accounts, _ := newTestAccounts(newTestUser(t, "ravi", "password", models.UserStatusActive))
+
+	for i := 0; i < 5; i++ {
+		accounts.SignIn("ravi", "wrong password", models.DeviceInfo{})
+	}
+	if _, err := accounts.SignIn("ravi", "password", models.DeviceInfo{}); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("SignIn() after 5 failed attempts error = %v, want %v", err, ErrTooManyAttempts)
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"agrimarketplace/validation"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxAddressesPerUser is the number of addresses a user may save in their address book.
+const maxAddressesPerUser = 20
+
+// AddressService defines the interface for managing users' address books.
+type AddressService interface {
+	AddAddress(userID string, address *models.Address) error
+	GetAddressByID(id string) (*models.Address, error)
+	GetUserAddresses(userID string) ([]models.Address, error)
+	UpdateAddress(address *models.Address) error
+	SetDefaultAddress(id string) (*models.Address, error)
+	DeleteAddress(id string) error
+}
+
+// addressService is an implementation of the AddressService interface.
+type addressService struct {
+	addressRepo repository.AddressRepository
+	userRepo    repository.UserRepository
+}
+
+// NewAddressService creates a new instance of the addressService.
+func NewAddressService(addressRepo repository.AddressRepository, userRepo repository.UserRepository) AddressService {
+	return &addressService{
+		addressRepo: addressRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// AddAddress adds an address to a user's address book. The first address added becomes the default,
+// and an address added as the default replaces the previous one.
+func (s *addressService) AddAddress(userID string, address *models.Address) error {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.IsClosed() {
+		return ErrAccountClosed
+	}
+
+	if err := prepareAddress(address); err != nil {
+		return err
+	}
+
+	addresses, err := s.addressRepo.FindAddressesByUser(user.ID)
+	if err != nil {
+		return err
+	}
+	if len(addresses) >= maxAddressesPerUser {
+		return ErrTooManyAddresses
+	}
+
+	// Generate an ObjectID for the address
+	address.ID = primitive.NewObjectID()
+	address.UserID = user.ID
+	address.CreatedAt = time.Now()
+	address.UpdatedAt = address.CreatedAt
+
+	// The default is set once the address is saved, so there is never more than one
+	makeDefault := address.IsDefault || len(addresses) == 0
+	address.IsDefault = false
+	if err := s.addressRepo.InsertAddress(address); err != nil {
+		return err
+	}
+	if makeDefault {
+		if err := s.addressRepo.SetDefaultAddress(user.ID, address.ID); err != nil {
+			return err
+		}
+		address.IsDefault = true
+	}
+
+	return nil
+}
+
+// GetAddressByID retrieves an address by its ID.
+func (s *addressService) GetAddressByID(id string) (*models.Address, error) {
+	address, err := s.addressRepo.FindAddressByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if address == nil {
+		return nil, ErrAddressNotFound
+	}
+
+	return address, nil
+}
+
+// GetUserAddresses retrieves the address book of a user, default address first.
+func (s *addressService) GetUserAddresses(userID string) ([]models.Address, error) {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	addresses, err := s.addressRepo.FindAddressesByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if addresses == nil {
+		addresses = []models.Address{}
+	}
+
+	return addresses, nil
+}
+
+// UpdateAddress updates an address. Purchases keep the address as it was when they were placed.
+// Addresses stay in the address book they were added to, and the default is changed with SetDefaultAddress.
+func (s *addressService) UpdateAddress(address *models.Address) error {
+	existingAddress, err := s.addressRepo.FindAddressByID(address.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if existingAddress == nil {
+		return ErrAddressNotFound
+	}
+
+	if err := prepareAddress(address); err != nil {
+		return err
+	}
+
+	address.UserID = existingAddress.UserID
+	address.IsDefault = existingAddress.IsDefault
+	address.CreatedAt = existingAddress.CreatedAt
+	address.UpdatedAt = time.Now()
+
+	return s.addressRepo.UpdateAddress(address)
+}
+
+// SetDefaultAddress makes an address the default one of its user.
+func (s *addressService) SetDefaultAddress(id string) (*models.Address, error) {
+	address, err := s.addressRepo.FindAddressByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if address == nil {
+		return nil, ErrAddressNotFound
+	}
+
+	if err := s.addressRepo.SetDefaultAddress(address.UserID, address.ID); err != nil {
+		return nil, err
+	}
+
+	address.IsDefault = true
+	return address, nil
+}
+
+// DeleteAddress deletes an address. When it was the default, the oldest remaining address becomes the default.
+func (s *addressService) DeleteAddress(id string) error {
+	address, err := s.addressRepo.FindAddressByID(id)
+	if err != nil {
+		return err
+	}
+	if address == nil {
+		return ErrAddressNotFound
+	}
+
+	if err := s.addressRepo.DeleteAddress(address.ID); err != nil {
+		return err
+	}
+	if !address.IsDefault {
+		return nil
+	}
+
+	remaining, err := s.addressRepo.FindAddressesByUser(address.UserID)
+	if err != nil || len(remaining) == 0 {
+		return err
+	}
+	return s.addressRepo.SetDefaultAddress(address.UserID, remaining[0].ID)
+}
+
+// prepareAddress normalizes and validates an address.
+func prepareAddress(address *models.Address) error {
+	address.Label = strings.TrimSpace(address.Label)
+	address.Line1 = strings.TrimSpace(address.Line1)
+	address.Line2 = strings.TrimSpace(address.Line2)
+	address.Locality = strings.TrimSpace(address.Locality)
+	address.District = strings.TrimSpace(address.District)
+	address.State = strings.TrimSpace(address.State)
+	address.PostalCode = strings.ReplaceAll(address.PostalCode, " ", "")
+	address.Landmark = strings.TrimSpace(address.Landmark)
+
+	// Deliveries are routed and priced from the coordinates
+	errs := validation.Errors{}
+	if address.Latitude == 0 && address.Longitude == 0 {
+		errs.Add("latitude", "coordinates are required")
+	}
+	addressSchema.Check(address, errs)
+	return errs.Err()
+}
+
+// addressSnapshot copies an address for a purchase, so that later edits leave the purchase unchanged.
+func addressSnapshot(address *models.Address) *models.AddressSnapshot {
+	return &models.AddressSnapshot{
+		AddressID:     address.ID,
+		Label:         address.Label,
+		PostalAddress: address.PostalAddress,
+	}
+}
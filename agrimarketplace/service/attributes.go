@@ -0,0 +1,188 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// attributeNamePattern restricts attribute names to identifiers that are safe to use in queries.
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// AttributeValidationError is returned when product attributes, variants or attribute schemas are invalid.
+// Fields maps each offending attribute name to the reason it was rejected.
+type AttributeValidationError struct {
+	Fields map[string]string
+}
+
+// Error implements the error interface.
+func (e *AttributeValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		messages = append(messages, name+": "+e.Fields[name])
+	}
+	return "invalid attributes: " + strings.Join(messages, "; ")
+}
+
+// validateAttributeSchema checks that the attribute definitions of a category are consistent.
+func validateAttributeSchema(definitions []models.AttributeDefinition) error {
+	fields := map[string]string{}
+
+	for _, definition := range definitions {
+		name := definition.Name
+		if !attributeNamePattern.MatchString(name) {
+			fields[name] = "name must be lower-case letters, digits and underscores"
+			continue
+		}
+
+		switch definition.Type {
+		case models.AttributeTypeString:
+			if definition.Pattern != "" {
+				if _, err := regexp.Compile(definition.Pattern); err != nil {
+					fields[name] = "invalid pattern"
+				}
+			}
+		case models.AttributeTypeNumber, models.AttributeTypeInteger:
+			if definition.Min != nil && definition.Max != nil && *definition.Min > *definition.Max {
+				fields[name] = "min must not exceed max"
+			}
+		case models.AttributeTypeBoolean:
+		case models.AttributeTypeEnum:
+			if len(definition.Enum) == 0 {
+				fields[name] = "enum attributes need allowed values"
+			}
+		default:
+			fields[name] = fmt.Sprintf("unsupported type %q", definition.Type)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, definition := range definitions {
+		if seen[definition.Name] && fields[definition.Name] == "" {
+			fields[definition.Name] = "defined more than once"
+		}
+		seen[definition.Name] = true
+	}
+
+	if len(fields) > 0 {
+		return &AttributeValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// validateAttributes checks product attribute values against the category schema.
+// Integer values decoded from JSON as floats are normalized to int64.
+func validateAttributes(definitions []models.AttributeDefinition, values map[string]interface{}) error {
+	fields := map[string]string{}
+	known := map[string]bool{}
+
+	for _, definition := range definitions {
+		known[definition.Name] = true
+
+		value, ok := values[definition.Name]
+		if !ok || value == nil {
+			if definition.Required {
+				fields[definition.Name] = "is required"
+			}
+			continue
+		}
+
+		normalized, reason := checkAttributeValue(definition, value)
+		if reason != "" {
+			fields[definition.Name] = reason
+			continue
+		}
+		values[definition.Name] = normalized
+	}
+
+	for name := range values {
+		if !known[name] {
+			fields[name] = "is not defined for this category"
+		}
+	}
+
+	if len(fields) > 0 {
+		return &AttributeValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// checkAttributeValue returns the normalized value, or the reason it does not match the definition.
+func checkAttributeValue(definition models.AttributeDefinition, value interface{}) (interface{}, string) {
+	switch definition.Type {
+	case models.AttributeTypeString:
+		text, ok := value.(string)
+		if !ok {
+			return nil, "must be a string"
+		}
+		if definition.Pattern != "" && !regexp.MustCompile(definition.Pattern).MatchString(text) {
+			return nil, "must match " + definition.Pattern
+		}
+		return text, ""
+
+	case models.AttributeTypeNumber, models.AttributeTypeInteger:
+		number, ok := toFloat(value)
+		if !ok {
+			return nil, "must be a number"
+		}
+		if definition.Min != nil && number < *definition.Min {
+			return nil, fmt.Sprintf("must be at least %g", *definition.Min)
+		}
+		if definition.Max != nil && number > *definition.Max {
+			return nil, fmt.Sprintf("must be at most %g", *definition.Max)
+		}
+		if definition.Type == models.AttributeTypeInteger {
+			if number != math.Trunc(number) {
+				return nil, "must be a whole number"
+			}
+			return int64(number), ""
+		}
+		return number, ""
+
+	case models.AttributeTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return nil, "must be true or false"
+		}
+		return value, ""
+
+	case models.AttributeTypeEnum:
+		text, ok := value.(string)
+		if !ok {
+			return nil, "must be a string"
+		}
+		for _, allowed := range definition.Enum {
+			if text == allowed {
+				return text, ""
+			}
+		}
+		return nil, "must be one of " + strings.Join(definition.Enum, ", ")
+	}
+
+	return nil, fmt.Sprintf("unsupported type %q", definition.Type)
+}
+
+// toFloat converts any numeric value to a float64.
+func toFloat(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case float32:
+		return float64(number), true
+	case int:
+		return float64(number), true
+	case int32:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	}
+	return 0, false
+}
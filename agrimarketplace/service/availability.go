@@ -0,0 +1,48 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// productsInStockNear returns the IDs of the products held in stock by shops within the area.
+// The result is never nil so that an area without stock filters out every product.
+func productsInStockNear(shopRepo repository.ShopRepository, serviceableProductRepo repository.ServiceableProductRepository, licenceService LicenceService, area models.GeoFilter) ([]primitive.ObjectID, error) {
+	productIDs := []primitive.ObjectID{}
+
+	shops, err := shopRepo.FindNearbyShops(area.Latitude, area.Longitude, area.RadiusInMeters)
+	if err != nil {
+		return nil, err
+	}
+	if len(shops) == 0 {
+		return productIDs, nil
+	}
+
+	shopIDs := make([]primitive.ObjectID, 0, len(shops))
+	for _, shop := range shops {
+		shopIDs = append(shopIDs, shop.ID)
+	}
+
+	listings, err := serviceableProductRepo.FindServiceableProductsByShops(shopIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Regulated products only count as in stock at shops holding a valid licence for them
+	listings, err = licenceService.FilterLicensedListings(listings)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[primitive.ObjectID]bool{}
+	for _, listing := range listings {
+		if !seen[listing.ProductID] {
+			seen[listing.ProductID] = true
+			productIDs = append(productIDs, listing.ProductID)
+		}
+	}
+
+	return productIDs, nil
+}
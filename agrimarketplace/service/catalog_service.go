@@ -0,0 +1,449 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"agrimarketplace/validation"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Formats supported by catalog import and export.
+const (
+	CatalogFormatCSV    = "csv"
+	CatalogFormatNDJSON = "ndjson"
+)
+
+// catalogColumns are the CSV columns of the catalog, in export order.
+// Attributes and variants are JSON-encoded within their cells.
+var catalogColumns = []string{"sku", "product_name", "description", "category", "brand", "price", "stock_quantity", "status", "required_licence", "attributes", "variants"}
+
+// CatalogRecord is a product as it appears in catalog import and export files.
+// Categories are referred to by name so that files can be maintained in spreadsheets.
+type CatalogRecord struct {
+	SKU             string                  `json:"sku"`
+	ProductName     string                  `json:"product_name"`
+	Description     string                  `json:"description"`
+	Category        string                  `json:"category"`
+	Brand           string                  `json:"brand"`
+	Price           float64                 `json:"price"`
+	StockQuantity   int                     `json:"stock_quantity"`
+	Status          string                  `json:"status,omitempty"`           // Lifecycle state; new products default to active
+	RequiredLicence string                  `json:"required_licence,omitempty"` // Overrides the licence type required by the category
+	Attributes      map[string]interface{}  `json:"attributes,omitempty"`
+	Variants        []models.ProductVariant `json:"variants,omitempty"`
+}
+
+// CatalogService defines the interface for bulk catalog import and export.
+type CatalogService interface {
+	ImportCatalog(r io.Reader, format string, dryRun bool) (*models.ImportReport, error)
+	ExportCatalog(w io.Writer, format string) error
+}
+
+// catalogService is an implementation of the CatalogService interface.
+type catalogService struct {
+	productService ProductService
+	productRepo    repository.ProductRepository
+	categoryRepo   repository.CategoryRepository
+}
+
+// NewCatalogService creates a new instance of the catalogService.
+func NewCatalogService(productService ProductService, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository) CatalogService {
+	return &catalogService{
+		productService: productService,
+		productRepo:    productRepo,
+		categoryRepo:   categoryRepo,
+	}
+}
+
+// ImportCatalog creates or updates products by SKU from a CSV or NDJSON file.
+// Rows are processed independently: an invalid row is reported and the import continues.
+// In a dry run every row is validated but nothing is written.
+func (s *catalogService) ImportCatalog(r io.Reader, format string, dryRun bool) (*models.ImportReport, error) {
+	report := &models.ImportReport{DryRun: dryRun}
+	categories := map[string]*models.Category{}
+	seen := map[string]int{}
+
+	handle := func(row int, record *CatalogRecord, parseErr error) error {
+		result := models.ImportRowResult{Row: row}
+		if record != nil {
+			result.SKU = record.SKU
+		}
+
+		var errs []string
+		if parseErr != nil {
+			errs = []string{parseErr.Error()}
+		} else if first, ok := seen[record.SKU]; ok && record.SKU != "" {
+			errs = []string{fmt.Sprintf("sku %s already appears on row %d", record.SKU, first)}
+		} else {
+			seen[record.SKU] = row
+			result.Action, errs = s.importRecord(record, categories, dryRun)
+		}
+
+		if len(errs) > 0 {
+			result.Action = models.ImportActionError
+			result.Errors = errs
+		}
+		switch result.Action {
+		case models.ImportActionCreate:
+			report.Created++
+		case models.ImportActionUpdate:
+			report.Updated++
+		default:
+			report.Failed++
+		}
+		report.Total++
+		report.Rows = append(report.Rows, result)
+
+		return nil
+	}
+
+	var err error
+	switch format {
+	case CatalogFormatCSV:
+		err = readCatalogCSV(r, handle)
+	case CatalogFormatNDJSON:
+		err = readCatalogNDJSON(r, handle)
+	default:
+		return nil, ErrUnsupportedCatalogFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// importRecord validates a record and, unless in a dry run, creates or updates its product.
+// It returns the action taken and the validation errors of the record.
+func (s *catalogService) importRecord(record *CatalogRecord, categories map[string]*models.Category, dryRun bool) (string, []string) {
+	var errs []string
+	if record.SKU == "" {
+		errs = append(errs, "sku is required")
+	}
+	if record.ProductName == "" {
+		errs = append(errs, "product_name is required")
+	}
+	if record.Price < 0 {
+		errs = append(errs, "price must not be negative")
+	}
+	if record.StockQuantity < 0 {
+		errs = append(errs, "stock_quantity must not be negative")
+	}
+
+	var categoryID primitive.ObjectID
+	if record.Category != "" {
+		category, err := s.findCategory(record.Category, categories)
+		if err != nil {
+			return "", []string{err.Error()}
+		}
+		if category == nil {
+			errs = append(errs, "unknown category "+record.Category)
+		} else {
+			categoryID = category.ID
+		}
+	}
+	if len(errs) > 0 {
+		return "", errs
+	}
+
+	existing, err := s.productRepo.FindProductBySKU(record.SKU)
+	if err != nil {
+		return "", []string{err.Error()}
+	}
+	if existing != nil && existing.DeletedAt != nil {
+		return "", []string{"sku " + record.SKU + " belongs to a deleted product; restore it before importing"}
+	}
+
+	product := &models.Product{
+		SKU:             record.SKU,
+		ProductName:     record.ProductName,
+		Description:     record.Description,
+		CategoryID:      categoryID,
+		Brand:           record.Brand,
+		Price:           record.Price,
+		StockQuantity:   record.StockQuantity,
+		Status:          record.Status,
+		Attributes:      record.Attributes,
+		Variants:        record.Variants,
+		RequiredLicence: record.RequiredLicence,
+	}
+	action := models.ImportActionCreate
+	if existing != nil {
+		action = models.ImportActionUpdate
+		product.ID = existing.ID
+		// Figures maintained by the platform are not part of the file
+		product.Rating = existing.Rating
+		product.Popularity = existing.Popularity
+	}
+
+	switch {
+	case dryRun:
+		err = s.productService.ValidateProduct(product)
+	case existing != nil:
+		err = s.productService.UpdateProduct(product)
+	default:
+		err = s.productService.CreateProduct(product)
+	}
+	if err != nil {
+		return "", validationMessages(err)
+	}
+
+	return action, nil
+}
+
+// findCategory resolves a category name, caching the lookups of an import.
+func (s *catalogService) findCategory(name string, categories map[string]*models.Category) (*models.Category, error) {
+	key := strings.ToLower(name)
+	if category, ok := categories[key]; ok {
+		return category, nil
+	}
+
+	category, err := s.categoryRepo.FindCategoryByName(name)
+	if err != nil {
+		return nil, err
+	}
+	categories[key] = category
+
+	return category, nil
+}
+
+// ExportCatalog streams every product of the catalog as CSV or NDJSON.
+func (s *catalogService) ExportCatalog(w io.Writer, format string) error {
+	if format != CatalogFormatCSV && format != CatalogFormatNDJSON {
+		return ErrUnsupportedCatalogFormat
+	}
+
+	categories, err := s.categoryRepo.FindAllCategories()
+	if err != nil {
+		return err
+	}
+	categoryNames := make(map[primitive.ObjectID]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+	}
+
+	toRecord := func(product models.Product) CatalogRecord {
+		return CatalogRecord{
+			SKU:             product.SKU,
+			ProductName:     product.ProductName,
+			Description:     product.Description,
+			Category:        categoryNames[product.CategoryID],
+			Brand:           product.Brand,
+			Price:           product.Price,
+			StockQuantity:   product.StockQuantity,
+			Status:          product.Status,
+			Attributes:      product.Attributes,
+			Variants:        product.Variants,
+			RequiredLicence: product.RequiredLicence,
+		}
+	}
+
+	if format == CatalogFormatNDJSON {
+		encoder := json.NewEncoder(w)
+		return s.productRepo.StreamProducts(func(product models.Product) error {
+			return encoder.Encode(toRecord(product))
+		})
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(catalogColumns); err != nil {
+		return err
+	}
+	err = s.productRepo.StreamProducts(func(product models.Product) error {
+		row, err := catalogCSVRow(toRecord(product))
+		if err != nil {
+			return err
+		}
+		return writer.Write(row)
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// readCatalogCSV parses a CSV catalog with a header row and calls handle for every record.
+func readCatalogCSV(r io.Reader, handle func(row int, record *CatalogRecord, err error) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if !errors.As(err, &parseErr) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidCatalogFile, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"sku", "product_name"} {
+		if _, ok := columns[required]; !ok {
+			return fmt.Errorf("%w: missing %s column", ErrInvalidCatalogFile, required)
+		}
+	}
+
+	for row := 2; ; row++ {
+		cells, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return err
+			}
+			if err := handle(row, nil, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		record, err := parseCatalogCSVRow(cells, columns)
+		if err := handle(row, record, err); err != nil {
+			return err
+		}
+	}
+}
+
+// parseCatalogCSVRow converts the cells of a CSV row into a record.
+func parseCatalogCSVRow(cells []string, columns map[string]int) (*CatalogRecord, error) {
+	cell := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(cells) {
+			return strings.TrimSpace(cells[i])
+		}
+		return ""
+	}
+
+	record := &CatalogRecord{
+		SKU:             cell("sku"),
+		ProductName:     cell("product_name"),
+		Description:     cell("description"),
+		Category:        cell("category"),
+		Brand:           cell("brand"),
+		Status:          cell("status"),
+		RequiredLicence: cell("required_licence"),
+	}
+
+	var err error
+	if value := cell("price"); value != "" {
+		if record.Price, err = strconv.ParseFloat(value, 64); err != nil {
+			return record, errors.New("price must be a number")
+		}
+	}
+	if value := cell("stock_quantity"); value != "" {
+		if record.StockQuantity, err = strconv.Atoi(value); err != nil {
+			return record, errors.New("stock_quantity must be a whole number")
+		}
+	}
+	if value := cell("attributes"); value != "" {
+		if err := json.Unmarshal([]byte(value), &record.Attributes); err != nil {
+			return record, errors.New("attributes must be a JSON object")
+		}
+	}
+	if value := cell("variants"); value != "" {
+		if err := json.Unmarshal([]byte(value), &record.Variants); err != nil {
+			return record, errors.New("variants must be a JSON array")
+		}
+	}
+
+	return record, nil
+}
+
+// catalogCSVRow converts a record into CSV cells in catalogColumns order.
+func catalogCSVRow(record CatalogRecord) ([]string, error) {
+	attributes, variants := "", ""
+	if len(record.Attributes) > 0 {
+		data, err := json.Marshal(record.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		attributes = string(data)
+	}
+	if len(record.Variants) > 0 {
+		data, err := json.Marshal(record.Variants)
+		if err != nil {
+			return nil, err
+		}
+		variants = string(data)
+	}
+
+	return []string{
+		record.SKU,
+		record.ProductName,
+		record.Description,
+		record.Category,
+		record.Brand,
+		strconv.FormatFloat(record.Price, 'f', -1, 64),
+		strconv.Itoa(record.StockQuantity),
+		record.Status,
+		record.RequiredLicence,
+		attributes,
+		variants,
+	}, nil
+}
+
+// readCatalogNDJSON parses a catalog with one JSON record per line and calls handle for every record.
+func readCatalogNDJSON(r io.Reader, handle func(row int, record *CatalogRecord, err error) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for row := 1; scanner.Scan(); row++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var record CatalogRecord
+		var err error
+		if jsonErr := json.Unmarshal([]byte(line), &record); jsonErr != nil {
+			err = errors.New("invalid JSON: " + jsonErr.Error())
+		}
+		record.SKU = strings.TrimSpace(record.SKU)
+		if err := handle(row, &record, err); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// validationMessages flattens a product validation error into messages for an import report.
+func validationMessages(err error) []string {
+	var messages []string
+	var fieldErr *validation.Error
+	var attributeErr *AttributeValidationError
+	switch {
+	case errors.As(err, &fieldErr):
+		for name, message := range fieldErr.Fields {
+			messages = append(messages, name+": "+message)
+		}
+	case errors.As(err, &attributeErr):
+		for name, message := range attributeErr.Fields {
+			messages = append(messages, "attributes."+name+": "+message)
+		}
+	default:
+		return []string{err.Error()}
+	}
+
+	sort.Strings(messages)
+	return messages
+}
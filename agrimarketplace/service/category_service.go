@@ -0,0 +1,91 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CategoryService defines the interface for working with product categories and their attribute schemas.
+type CategoryService interface {
+	GetCategoryByID(id string) (*models.Category, error)
+	GetCategories() ([]models.Category, error)
+	CreateCategory(category *models.Category) error
+	UpdateCategory(category *models.Category) error
+}
+
+// categoryService is an implementation of the CategoryService interface.
+type categoryService struct {
+	categoryRepo repository.CategoryRepository
+}
+
+// NewCategoryService creates a new instance of the categoryService.
+func NewCategoryService(categoryRepo repository.CategoryRepository) CategoryService {
+	return &categoryService{
+		categoryRepo: categoryRepo,
+	}
+}
+
+// GetCategoryByID retrieves a category by its ID.
+func (s *categoryService) GetCategoryByID(id string) (*models.Category, error) {
+	return s.categoryRepo.FindCategoryByID(id)
+}
+
+// GetCategories retrieves every category.
+func (s *categoryService) GetCategories() ([]models.Category, error) {
+	return s.categoryRepo.FindAllCategories()
+}
+
+// CreateCategory creates a new category after validating its attribute schema.
+func (s *categoryService) CreateCategory(category *models.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return ErrInvalidCategory
+	}
+	if err := validateAttributeSchema(category.Attributes); err != nil {
+		return err
+	}
+	if category.RequiredLicence != "" && !validLicenceType(category.RequiredLicence) {
+		return ErrInvalidLicenceType
+	}
+
+	existingCategory, err := s.categoryRepo.FindCategoryByName(category.Name)
+	if err != nil {
+		return err
+	}
+	if existingCategory != nil {
+		return ErrCategoryAlreadyExists
+	}
+
+	// Generate an ObjectID for the category
+	category.ID = primitive.NewObjectID()
+
+	return s.categoryRepo.InsertCategory(category)
+}
+
+// UpdateCategory updates an existing category after validating its attribute schema.
+// Products already in the category are validated against the new schema on their next update.
+func (s *categoryService) UpdateCategory(category *models.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return ErrInvalidCategory
+	}
+	if err := validateAttributeSchema(category.Attributes); err != nil {
+		return err
+	}
+	if category.RequiredLicence != "" && !validLicenceType(category.RequiredLicence) {
+		return ErrInvalidLicenceType
+	}
+
+	existingCategory, err := s.categoryRepo.FindCategoryByID(category.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if existingCategory == nil {
+		return ErrCategoryNotFound
+	}
+
+	return s.categoryRepo.UpdateCategory(category)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"math"
+	"sort"
+)
+
+const (
+	// tileSize is the size in pixels of a web map tile.
+	tileSize = 256.0
+	// clusterCellPixels is the width of the grid cell, in screen pixels, into which shops are grouped.
+	clusterCellPixels = 64.0
+	// maxClusterZoom is the zoom level from which every shop is shown individually.
+	maxClusterZoom = 15
+	// maxZoom is the highest zoom level supported by web maps.
+	maxZoom = 22
+)
+
+// cellKey identifies a grid cell in web-mercator pixel space.
+type cellKey struct {
+	x, y int64
+}
+
+// clusterShops groups shops that fall into the same screen-space grid cell at the given zoom.
+// Cells holding a single shop are returned as individual shops.
+func clusterShops(shops []models.Shop, zoom int) ([]models.Shop, []models.ShopCluster) {
+	if zoom >= maxClusterZoom {
+		return shops, nil
+	}
+
+	cells := map[cellKey][]models.Shop{}
+	var keys []cellKey
+	for _, shop := range shops {
+		// Longitude 180 is the antimeridian, like -180, so both fall in the first column of the grid
+		x, y := mercatorPixels(shop.Latitude, wrapLongitude(shop.Longitude), zoom)
+		key := cellKey{x: int64(x / clusterCellPixels), y: int64(y / clusterCellPixels)}
+		if _, ok := cells[key]; !ok {
+			keys = append(keys, key)
+		}
+		cells[key] = append(cells[key], shop)
+	}
+
+	// Keep the output deterministic so map markers do not jump between requests
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].y != keys[j].y {
+			return keys[i].y < keys[j].y
+		}
+		return keys[i].x < keys[j].x
+	})
+
+	var single []models.Shop
+	var clusters []models.ShopCluster
+	for _, key := range keys {
+		members := cells[key]
+		if len(members) == 1 {
+			single = append(single, members[0])
+			continue
+		}
+		clusters = append(clusters, newShopCluster(members))
+	}
+
+	return single, clusters
+}
+
+// newShopCluster builds a cluster from its member shops.
+// Longitudes are taken relative to the first member, so that a cluster crossing the antimeridian
+// is centred among its members rather than on the other side of the globe. Its bounds then have a
+// minimum longitude greater than their maximum, like any box crossing the antimeridian.
+func newShopCluster(members []models.Shop) models.ShopCluster {
+	cluster := models.ShopCluster{
+		Count: len(members),
+		Bounds: models.BoundingBox{
+			MinLatitude:  math.Inf(1),
+			MinLongitude: math.Inf(1),
+			MaxLatitude:  math.Inf(-1),
+			MaxLongitude: math.Inf(-1),
+		},
+	}
+
+	origin := members[0].Longitude
+	for _, shop := range members {
+		longitude := origin + wrapLongitude(shop.Longitude-origin)
+		cluster.Latitude += shop.Latitude
+		cluster.Longitude += longitude
+		cluster.Bounds.MinLatitude = math.Min(cluster.Bounds.MinLatitude, shop.Latitude)
+		cluster.Bounds.MinLongitude = math.Min(cluster.Bounds.MinLongitude, longitude)
+		cluster.Bounds.MaxLatitude = math.Max(cluster.Bounds.MaxLatitude, shop.Latitude)
+		cluster.Bounds.MaxLongitude = math.Max(cluster.Bounds.MaxLongitude, longitude)
+	}
+	cluster.Latitude /= float64(len(members))
+	cluster.Longitude = wrapLongitude(cluster.Longitude / float64(len(members)))
+	cluster.Bounds.MinLongitude = wrapLongitude(cluster.Bounds.MinLongitude)
+	cluster.Bounds.MaxLongitude = wrapLongitude(cluster.Bounds.MaxLongitude)
+
+	return cluster
+}
+
+// mercatorPixels projects a coordinate to web-mercator pixel coordinates at the given zoom.
+func mercatorPixels(latitude, longitude float64, zoom int) (float64, float64) {
+	// Web mercator is undefined at the poles
+	latitude = math.Max(math.Min(latitude, 85.05112878), -85.05112878)
+
+	scale := tileSize * math.Exp2(float64(zoom))
+	sinLat := math.Sin(latitude * math.Pi / 180)
+
+	x := (longitude + 180) / 360 * scale
+	y := (0.5 - math.Log((1+sinLat)/(1-sinLat))/(4*math.Pi)) * scale
+
+	return x, y
+}
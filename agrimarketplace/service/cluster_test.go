@@ -0,0 +1,92 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestClusterShopsAroundAntimeridian(t *testing.T) {
+	shop := func(latitude, longitude float64) models.Shop {
+		return models.Shop{ID: primitive.NewObjectID(), Latitude: latitude, Longitude: longitude}
+	}
+
+	tests := []struct {
+		name          string
+		shops         []models.Shop
+		wantSingle    int
+		wantCluster   *models.ShopCluster // Expected single cluster, if any
+		wantNoCluster bool
+	}{
+		{
+			name:        "west of the antimeridian",
+			shops:       []models.Shop{shop(10, 170), shop(10.5, 175), shop(11, 179)},
+			wantCluster: &models.ShopCluster{Latitude: 10.5, Longitude: 174.6667, Count: 3, Bounds: models.BoundingBox{MinLatitude: 10, MinLongitude: 170, MaxLatitude: 11, MaxLongitude: 179}},
+		},
+		// Shops on the antimeridian are recorded at 180 or -180; the cluster is centred beside them,
+		// not at longitude 0, and its bounds cross the antimeridian
+		{
+			name:        "on the antimeridian",
+			shops:       []models.Shop{shop(10, 180), shop(10.2, -180), shop(10.1, -179.8)},
+			wantCluster: &models.ShopCluster{Latitude: 10.1, Longitude: -179.9333, Count: 3, Bounds: models.BoundingBox{MinLatitude: 10, MinLongitude: -180, MaxLatitude: 10.2, MaxLongitude: -179.8}},
+		},
+		{
+			name:        "on the antimeridian, listed from the east",
+			shops:       []models.Shop{shop(10.1, -179.8), shop(10.2, -180), shop(10, 180)},
+			wantCluster: &models.ShopCluster{Latitude: 10.1, Longitude: -179.9333, Count: 3, Bounds: models.BoundingBox{MinLatitude: 10, MinLongitude: -180, MaxLatitude: 10.2, MaxLongitude: -179.8}},
+		},
+		// Grid cells end at the antimeridian, so shops just either side of it are never merged
+		// into a cluster centred on the other side of the globe
+		{
+			name:          "either side of the antimeridian",
+			shops:         []models.Shop{shop(10, 179.9), shop(10, -179.9)},
+			wantSingle:    2,
+			wantNoCluster: true,
+		},
+	}
+
+	for _, tt := range tests {
+		single, clusters := clusterShops(tt.shops, 2)
+		if len(single) != tt.wantSingle {
+			t.Errorf("%s: clusterShops() returned %d single shops, want %d", tt.name, len(single), tt.wantSingle)
+		}
+		if tt.wantNoCluster {
+			if len(clusters) != 0 {
+				t.Errorf("%s: clusterShops() clusters = %+v, want none", tt.name, clusters)
+			}
+			continue
+		}
+		if len(clusters) != 1 {
+			t.Errorf("%s: clusterShops() clusters = %+v, want one", tt.name, clusters)
+			continue
+		}
+
+		got, want := clusters[0], tt.wantCluster
+		if got.Count != want.Count || !near(got.Latitude, want.Latitude) || !nearLongitude(got.Longitude, want.Longitude) ||
+			!near(got.Bounds.MinLatitude, want.Bounds.MinLatitude) || !nearLongitude(got.Bounds.MinLongitude, want.Bounds.MinLongitude) ||
+			!near(got.Bounds.MaxLatitude, want.Bounds.MaxLatitude) || !nearLongitude(got.Bounds.MaxLongitude, want.Bounds.MaxLongitude) {
+			t.Errorf("%s: clusterShops() cluster = %+v, want %+v", tt.name, got, *want)
+		}
+	}
+}
+
+func TestClusterShopsAtHighZoom(t *testing.T) {
+	shops := []models.Shop{{Latitude: 10, Longitude: 180}, {Latitude: 10, Longitude: -180}}
+
+	single, clusters := clusterShops(shops, maxClusterZoom)
+	if len(single) != 2 || len(clusters) != 0 {
+		t.Errorf("clusterShops() at zoom %d = %d single shops and %d clusters, want every shop on its own", maxClusterZoom, len(single), len(clusters))
+	}
+}
+
+// near reports whether two coordinates are equal to within about ten metres.
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-4
+}
+
+// nearLongitude is like near for longitudes, which are equal 360 degrees apart.
+func nearLongitude(a, b float64) bool {
+	return near(wrapLongitude(a-b), 0)
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// defaultRecommendationRadius is the distance from a farmer within which shops are searched when none is requested.
+	defaultRecommendationRadius = 25000
+
+	// defaultProductsPerStage is the number of products recommended for each growth stage when no limit is requested.
+	defaultProductsPerStage = 10
+)
+
+// CropService defines the interface for working with farmers' crops and the products they need.
+type CropService interface {
+	SetUserCrops(userID string, crops []models.CropPlanting) (*models.User, error)
+	AddCropRule(rule *models.CropRule) error
+	GetCropRules() ([]models.CropRule, error)
+	DeleteCropRule(id string) error
+	RecommendProducts(userID string, radiusInMeters float64, perStage int) ([]models.CropRecommendation, error)
+}
+
+// cropService is an implementation of the CropService interface.
+type cropService struct {
+	cropRuleRepo   repository.CropRuleRepository
+	userRepo       repository.UserRepository
+	categoryRepo   repository.CategoryRepository
+	productService ProductService
+}
+
+// NewCropService creates a new instance of the cropService.
+func NewCropService(cropRuleRepo repository.CropRuleRepository, userRepo repository.UserRepository, categoryRepo repository.CategoryRepository, productService ProductService) CropService {
+	return &cropService{
+		cropRuleRepo:   cropRuleRepo,
+		userRepo:       userRepo,
+		categoryRepo:   categoryRepo,
+		productService: productService,
+	}
+}
+
+// SetUserCrops replaces the crops grown by a user.
+func (s *cropService) SetUserCrops(userID string, crops []models.CropPlanting) (*models.User, error) {
+	crops, err := normalizeCrops(crops)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.userRepo.SetUserCrops(user.ID, crops); err != nil {
+		return nil, err
+	}
+
+	user.Crops = crops
+	return user, nil
+}
+
+// AddCropRule adds a rule mapping a growth stage of a crop to the products it needs.
+func (s *cropService) AddCropRule(rule *models.CropRule) error {
+	rule.Crop = normalizeCropName(rule.Crop)
+	rule.Season = strings.ToLower(strings.TrimSpace(rule.Season))
+	rule.Stage = strings.TrimSpace(rule.Stage)
+	if rule.Crop == "" || rule.Stage == "" || rule.EndDay < rule.StartDay ||
+		(rule.Season != "" && !validSeason(rule.Season)) ||
+		(len(rule.CategoryIDs) == 0 && len(rule.Attributes) == 0) {
+		return ErrInvalidCropRule
+	}
+
+	for _, categoryID := range rule.CategoryIDs {
+		category, err := s.categoryRepo.FindCategoryByID(categoryID.Hex())
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			return ErrCategoryNotFound
+		}
+	}
+
+	// Generate an ObjectID for the rule
+	rule.ID = primitive.NewObjectID()
+
+	return s.cropRuleRepo.InsertCropRule(rule)
+}
+
+// GetCropRules retrieves every crop rule, by crop and growth timeline.
+func (s *cropService) GetCropRules() ([]models.CropRule, error) {
+	return s.cropRuleRepo.FindAllCropRules()
+}
+
+// DeleteCropRule deletes a crop rule by its ID.
+func (s *cropService) DeleteCropRule(id string) error {
+	rule, err := s.cropRuleRepo.FindCropRuleByID(id)
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		return ErrCropRuleNotFound
+	}
+
+	return s.cropRuleRepo.DeleteCropRule(rule.ID)
+}
+
+// RecommendProducts lists, for the current and upcoming growth stages of a user's crops, the matching
+// products available from shops near the user. Stages are ordered by when they start.
+func (s *cropService) RecommendProducts(userID string, radiusInMeters float64, perStage int) ([]models.CropRecommendation, error) {
+	if radiusInMeters <= 0 {
+		radiusInMeters = defaultRecommendationRadius
+	}
+	if perStage <= 0 {
+		perStage = defaultProductsPerStage
+	}
+
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	recommendations := []models.CropRecommendation{}
+	if len(user.Crops) == 0 {
+		return recommendations, nil
+	}
+	if user.Latitude == 0 && user.Longitude == 0 {
+		return nil, ErrUserLocationRequired
+	}
+
+	var crops []string
+	for _, planting := range user.Crops {
+		crops = append(crops, planting.Crop)
+	}
+	rules, err := s.cropRuleRepo.FindCropRulesByCrops(crops)
+	if err != nil {
+		return nil, err
+	}
+
+	area := models.GeoFilter{Latitude: user.Latitude, Longitude: user.Longitude, RadiusInMeters: radiusInMeters}
+	now := time.Now()
+	for _, planting := range user.Crops {
+		day := int(math.Floor(now.Sub(planting.SowingDate).Hours() / 24)) // Days since sowing
+
+		for _, rule := range rules {
+			if rule.Crop != planting.Crop || (rule.Season != "" && rule.Season != planting.Season) || rule.EndDay < day {
+				continue
+			}
+
+			page, err := s.productService.ListProducts(models.ProductQuery{
+				CategoryIDs: rule.CategoryIDs,
+				Attributes:  rule.Attributes,
+				InStockNear: &area,
+				Sort:        models.ProductSortPopularity,
+				Limit:       perStage,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if len(page.Products) == 0 {
+				continue
+			}
+
+			recommendations = append(recommendations, models.CropRecommendation{
+				Crop:     planting.Crop,
+				Season:   planting.Season,
+				Stage:    rule.Stage,
+				StartsOn: planting.SowingDate.AddDate(0, 0, rule.StartDay),
+				EndsOn:   planting.SowingDate.AddDate(0, 0, rule.EndDay),
+				Current:  rule.StartDay <= day,
+				Products: page.Products,
+			})
+		}
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].StartsOn.Before(recommendations[j].StartsOn)
+	})
+
+	return recommendations, nil
+}
+
+// normalizeCrops checks the crops grown by a user and normalizes their names.
+// The season is derived from the sowing date when it is not given.
+func normalizeCrops(crops []models.CropPlanting) ([]models.CropPlanting, error) {
+	normalized := make([]models.CropPlanting, 0, len(crops))
+	for _, planting := range crops {
+		planting.Crop = normalizeCropName(planting.Crop)
+		planting.Season = strings.ToLower(strings.TrimSpace(planting.Season))
+		if planting.Crop == "" || planting.SownArea <= 0 || planting.SowingDate.IsZero() {
+			return nil, ErrInvalidCrop
+		}
+		if planting.Season == "" {
+			planting.Season = models.SeasonOf(planting.SowingDate)
+		}
+		if !validSeason(planting.Season) {
+			return nil, ErrInvalidCrop
+		}
+		normalized = append(normalized, planting)
+	}
+
+	return normalized, nil
+}
+
+// normalizeCropName returns the form in which crop names are stored and matched.
+func normalizeCropName(crop string) string {
+	return strings.ToLower(strings.Join(strings.Fields(crop), " "))
+}
+
+// validSeason reports whether a season is one of the known cropping seasons.
+func validSeason(season string) bool {
+	switch season {
+	case models.SeasonKharif, models.SeasonRabi, models.SeasonZaid:
+		return true
+	}
+	return false
+}
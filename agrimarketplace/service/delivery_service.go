@@ -0,0 +1,213 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeliveryConfig holds the platform-wide delivery settings.
+type DeliveryConfig struct {
+	// DefaultSchedule is used for shops that have not configured their own fee schedule.
+	DefaultSchedule models.DeliveryFeeSchedule
+	// RoadFactor converts straight-line distance into an approximate road distance.
+	RoadFactor float64
+	// Location is the time zone in which shop operating hours are expressed.
+	Location *time.Location
+	// Now returns the current time; it defaults to time.Now.
+	Now func() time.Time
+}
+
+// DeliveryService defines the interface for estimating delivery cost and arrival time.
+type DeliveryService interface {
+	GetFeeSchedule(shopID string) (*models.DeliveryFeeSchedule, error)
+	SetFeeSchedule(schedule *models.DeliveryFeeSchedule) error
+	QuoteDelivery(shopID string, latitude, longitude, amount float64) (*models.DeliveryQuote, error)
+	QuoteShop(shop *models.Shop, latitude, longitude, amount float64) (*models.DeliveryQuote, error)
+	QuoteNearbyShops(shops []models.Shop, latitude, longitude, amount float64) ([]models.NearbyShop, error)
+}
+
+// deliveryService is an implementation of the DeliveryService interface.
+type deliveryService struct {
+	shopRepo        repository.ShopRepository
+	deliveryFeeRepo repository.DeliveryFeeRepository
+	config          DeliveryConfig
+}
+
+// NewDeliveryService creates a new instance of the deliveryService.
+func NewDeliveryService(shopRepo repository.ShopRepository, deliveryFeeRepo repository.DeliveryFeeRepository, config DeliveryConfig) DeliveryService {
+	if config.RoadFactor <= 0 {
+		config.RoadFactor = 1
+	}
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return &deliveryService{
+		shopRepo:        shopRepo,
+		deliveryFeeRepo: deliveryFeeRepo,
+		config:          config,
+	}
+}
+
+// GetFeeSchedule returns the fee schedule of a shop, falling back to the platform default.
+func (s *deliveryService) GetFeeSchedule(shopID string) (*models.DeliveryFeeSchedule, error) {
+	schedule, err := s.deliveryFeeRepo.FindScheduleByShopID(shopID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule != nil {
+		return schedule, nil
+	}
+
+	defaultSchedule := s.config.DefaultSchedule
+	defaultSchedule.ShopID, _ = primitive.ObjectIDFromHex(shopID)
+	return &defaultSchedule, nil
+}
+
+// SetFeeSchedule stores the fee schedule of a shop.
+func (s *deliveryService) SetFeeSchedule(schedule *models.DeliveryFeeSchedule) error {
+	if err := validateFeeSchedule(schedule); err != nil {
+		return err
+	}
+
+	existingShop, err := s.shopRepo.FindShopByID(schedule.ShopID.Hex())
+	if err != nil {
+		return err
+	}
+	if existingShop == nil {
+		return ErrShopNotFound
+	}
+
+	return s.deliveryFeeRepo.UpsertSchedule(schedule)
+}
+
+// QuoteDelivery estimates the delivery from a shop to the given coordinates for an order amount.
+func (s *deliveryService) QuoteDelivery(shopID string, latitude, longitude, amount float64) (*models.DeliveryQuote, error) {
+	shop, err := s.shopRepo.FindShopByID(shopID)
+	if err != nil {
+		return nil, err
+	}
+	if shop == nil {
+		return nil, ErrShopNotFound
+	}
+
+	return s.QuoteShop(shop, latitude, longitude, amount)
+}
+
+// QuoteShop estimates the delivery from an already loaded shop.
+// Shops whose operating hours were written before they followed the format of parseOperatingHours
+// are quoted with their hours unknown: dispatch is estimated as if the shop were open, and the
+// quote says so.
+func (s *deliveryService) QuoteShop(shop *models.Shop, latitude, longitude, amount float64) (*models.DeliveryQuote, error) {
+	schedule, err := s.GetFeeSchedule(shop.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	hoursKnown := true
+	hours, err := parseOperatingHours(shop.OperatingHours)
+	if err != nil {
+		hours, hoursKnown = nil, false
+	}
+
+	quote := s.quote(shop, schedule, hours, latitude, longitude, amount)
+	quote.HoursUnknown = !hoursKnown
+	return quote, nil
+}
+
+// QuoteNearbyShops attaches a delivery quote to each of the given shops.
+func (s *deliveryService) QuoteNearbyShops(shops []models.Shop, latitude, longitude, amount float64) ([]models.NearbyShop, error) {
+	nearbyShops := make([]models.NearbyShop, 0, len(shops))
+	for i := range shops {
+		quote, err := s.QuoteShop(&shops[i], latitude, longitude, amount)
+		if err != nil {
+			return nil, err
+		}
+		nearbyShops = append(nearbyShops, models.NearbyShop{Shop: shops[i], DeliveryQuote: quote})
+	}
+
+	return nearbyShops, nil
+}
+
+// quote computes the fee and arrival estimate for a single shop.
+func (s *deliveryService) quote(shop *models.Shop, schedule *models.DeliveryFeeSchedule, hours operatingHours, latitude, longitude, amount float64) *models.DeliveryQuote {
+	distanceKm := haversineKm(shop.Latitude, shop.Longitude, latitude, longitude) * s.config.RoadFactor
+
+	quote := &models.DeliveryQuote{
+		ShopID:      shop.ID,
+		Deliverable: schedule.MaxDistanceKm <= 0 || distanceKm <= schedule.MaxDistanceKm,
+		DistanceKm:  roundTo(distanceKm, 2),
+	}
+	if !quote.Deliverable {
+		return quote
+	}
+
+	if schedule.FreeDeliveryThreshold > 0 && amount >= schedule.FreeDeliveryThreshold {
+		quote.FreeDelivery = true
+	} else {
+		quote.Fee = roundTo(deliveryFee(schedule, distanceKm), 2)
+	}
+
+	now := s.config.Now().In(s.config.Location)
+	quote.DispatchAt = hours.dispatchAt(now, time.Duration(schedule.PreparationMinutes)*time.Minute)
+
+	travel := time.Duration(0)
+	if schedule.AverageSpeedKmph > 0 {
+		travel = time.Duration(distanceKm / schedule.AverageSpeedKmph * float64(time.Hour))
+	}
+	quote.EstimatedArrival = quote.DispatchAt.Add(travel).Round(time.Minute)
+	quote.DurationMinutes = int(math.Ceil(quote.EstimatedArrival.Sub(now).Minutes()))
+
+	return quote
+}
+
+// deliveryFee charges the base fee plus each slab's per-km rate for the distance covered by that slab.
+// Distance beyond the last slab is charged at the last slab's rate.
+func deliveryFee(schedule *models.DeliveryFeeSchedule, distanceKm float64) float64 {
+	fee := schedule.BaseFee
+	covered := 0.0
+	for _, slab := range schedule.Slabs {
+		if covered >= distanceKm {
+			break
+		}
+		upTo := math.Min(slab.UpToKm, distanceKm)
+		fee += (upTo - covered) * slab.FeePerKm
+		covered = upTo
+	}
+	if covered < distanceKm && len(schedule.Slabs) > 0 {
+		fee += (distanceKm - covered) * schedule.Slabs[len(schedule.Slabs)-1].FeePerKm
+	}
+
+	return fee
+}
+
+// validateFeeSchedule ensures the fee schedule can be used for quoting.
+func validateFeeSchedule(schedule *models.DeliveryFeeSchedule) error {
+	if schedule.BaseFee < 0 || schedule.FreeDeliveryThreshold < 0 || schedule.MaxDistanceKm < 0 ||
+		schedule.AverageSpeedKmph <= 0 || schedule.PreparationMinutes < 0 {
+		return ErrInvalidFeeSchedule
+	}
+
+	previous := 0.0
+	for _, slab := range schedule.Slabs {
+		if slab.UpToKm <= previous || slab.FeePerKm < 0 {
+			return ErrInvalidFeeSchedule
+		}
+		previous = slab.UpToKm
+	}
+
+	return nil
+}
+
+// roundTo rounds a value to the given number of decimal places.
+func roundTo(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"math"
+	"testing"
+)
+
+func TestDeliveryFee(t *testing.T) {
+	slabs := []models.DeliveryFeeSlab{
+		{UpToKm: 5, FeePerKm: 2},
+		{UpToKm: 10, FeePerKm: 3},
+		{UpToKm: 20, FeePerKm: 4},
+	}
+
+	tests := []struct {
+		name       string
+		schedule   models.DeliveryFeeSchedule
+		distanceKm float64
+		want       float64
+	}{
+		{"no distance", models.DeliveryFeeSchedule{BaseFee: 10, Slabs: slabs}, 0, 10},
+		{"within the first slab", models.DeliveryFeeSchedule{BaseFee: 10, Slabs: slabs}, 3, 16},
+		{"end of the first slab", models.DeliveryFeeSchedule{BaseFee: 10, Slabs: slabs}, 5, 20},
+		{"across slabs", models.DeliveryFeeSchedule{BaseFee: 10, Slabs: slabs}, 7.5, 27.5},
+		{"end of the last slab", models.DeliveryFeeSchedule{BaseFee: 10, Slabs: slabs}, 20, 75},
+		// Distance beyond the last slab is charged at the last slab's rate
+		{"beyond the last slab", models.DeliveryFeeSchedule{BaseFee: 10, Slabs: slabs}, 25, 95},
+		{"no slabs", models.DeliveryFeeSchedule{BaseFee: 10}, 25, 10},
+		{"no base fee", models.DeliveryFeeSchedule{Slabs: slabs}, 1, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deliveryFee(&tt.schedule, tt.distanceKm); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("deliveryFee(%v km) = %v, want %v", tt.distanceKm, got, tt.want)
+			}
+		})
+	}
+}
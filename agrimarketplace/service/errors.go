@@ -11,5 +11,192 @@ var (
 	// ErrShopAlreadyExists is returned when a shop with the same name already exists.
 	ErrShopAlreadyExists = errors.New("shop already exists")
 
+	// ErrInvalidFeeSchedule is returned when a delivery fee schedule is inconsistent.
+	ErrInvalidFeeSchedule = errors.New("invalid delivery fee schedule")
+
+	// ErrInvalidBoundingBox is returned when a map viewport has out-of-range coordinates.
+	ErrInvalidBoundingBox = errors.New("invalid bounding box")
+
+	// ErrInvalidZoom is returned when a map zoom level is out of range.
+	ErrInvalidZoom = errors.New("invalid zoom level")
+
+	// ErrProductNotFound is returned when a product is not found.
+	ErrProductNotFound = errors.New("product not found")
+
+	// ErrInvalidSortKey is returned when results are requested in an unsupported order.
+	ErrInvalidSortKey = errors.New("invalid sort key")
+
+	// ErrEmptySearchQuery is returned when a search query contains no searchable terms.
+	ErrEmptySearchQuery = errors.New("empty search query")
+
+	// ErrInvalidCursor is returned when a pagination cursor is malformed or belongs to another sort order.
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrInvalidProductQuery is returned when catalog filters are out of range.
+	ErrInvalidProductQuery = errors.New("invalid product query")
+
+	// ErrCategoryNotFound is returned when a category is not found.
+	ErrCategoryNotFound = errors.New("category not found")
+
+	// ErrCategoryAlreadyExists is returned when a category with the same name already exists.
+	ErrCategoryAlreadyExists = errors.New("category already exists")
+
+	// ErrInvalidCategory is returned when a category has no name.
+	ErrInvalidCategory = errors.New("invalid category")
+
+	// ErrInvalidUnit is returned when a unit of measure is unknown or cannot be converted.
+	ErrInvalidUnit = errors.New("invalid unit of measure")
+
+	// ErrImageNotFound is returned when an image is not found.
+	ErrImageNotFound = errors.New("image not found")
+
+	// ErrImageOwnerNotFound is returned when the product or shop of an image is not found.
+	ErrImageOwnerNotFound = errors.New("image owner not found")
+
+	// ErrImageTooLarge is returned when an uploaded image exceeds the size limits.
+	ErrImageTooLarge = errors.New("image too large")
+
+	// ErrUnsupportedImageType is returned when an upload is not a JPEG, PNG or GIF image.
+	ErrUnsupportedImageType = errors.New("unsupported image type")
+
+	// ErrInvalidPrice is returned when a selling price is not positive.
+	ErrInvalidPrice = errors.New("invalid price")
+
+	// ErrPriceAboveMRP is returned when a shop price exceeds the catalog maximum retail price.
+	ErrPriceAboveMRP = errors.New("price exceeds maximum retail price")
+
+	// ErrUnsupportedCatalogFormat is returned when a catalog file is neither CSV nor NDJSON.
+	ErrUnsupportedCatalogFormat = errors.New("unsupported catalog format")
+
+	// ErrInvalidCatalogFile is returned when a catalog file cannot be read at all.
+	ErrInvalidCatalogFile = errors.New("invalid catalog file")
+
+	// ErrUserNotFound is returned when a user is not found.
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrInvalidProductStatus is returned when a product is given an unknown lifecycle state.
+	ErrInvalidProductStatus = errors.New("invalid product status")
+
+	// ErrInvalidStatusTransition is returned when a product cannot move from its current lifecycle state to the requested one.
+	ErrInvalidStatusTransition = errors.New("invalid product status transition")
+
+	// ErrInvalidLicenceType is returned when a licence type is not one of the known types.
+	ErrInvalidLicenceType = errors.New("invalid licence type")
+
+	// ErrInvalidLicence is returned when a licence has no number or an inconsistent validity period.
+	ErrInvalidLicence = errors.New("invalid licence")
+
+	// ErrLicenceNotFound is returned when a licence is not found.
+	ErrLicenceNotFound = errors.New("licence not found")
+
+	// ErrLicenceRequired is returned when a shop has no licence for a regulated product.
+	ErrLicenceRequired = errors.New("shop has no licence to sell this product")
+
+	// ErrLicenceExpired is returned when a shop's licence for a regulated product is not currently valid.
+	ErrLicenceExpired = errors.New("shop licence to sell this product is expired")
+
+	// ErrInvalidStockQuantity is returned when a stock quantity is negative.
+	ErrInvalidStockQuantity = errors.New("invalid stock quantity")
+
+	// ErrInvalidLot is returned when a lot has no number, no quantity or inconsistent dates.
+	ErrInvalidLot = errors.New("invalid lot")
+
+	// ErrLotExpired is returned when receiving a lot that has already expired.
+	ErrLotExpired = errors.New("lot has expired")
+
+	// ErrNotServiceable is returned when a shop does not serve a product.
+	ErrNotServiceable = errors.New("product is not serviceable by this shop")
+
+	// ErrInsufficientStock is returned when a shop does not hold enough unexpired stock of a product.
+	ErrInsufficientStock = errors.New("insufficient stock")
+
+	// ErrInvalidPurchase is returned when a purchase has no lines or a line has no product or quantity.
+	ErrInvalidPurchase = errors.New("invalid purchase")
+
+	// ErrPurchaseNotFound is returned when a purchase is not found.
+	ErrPurchaseNotFound = errors.New("purchase not found")
+
+	// ErrInvalidCrop is returned when a crop has no name, sown area or sowing date, or an unknown season.
+	ErrInvalidCrop = errors.New("invalid crop")
+
+	// ErrInvalidCropRule is returned when a crop rule has no crop, stage or products to match, or an inconsistent timeline.
+	ErrInvalidCropRule = errors.New("invalid crop rule")
+
+	// ErrCropRuleNotFound is returned when a crop rule is not found.
+	ErrCropRuleNotFound = errors.New("crop rule not found")
+
+	// ErrUserLocationRequired is returned when a user without a location asks for products near them.
+	ErrUserLocationRequired = errors.New("user location is required")
+
+	// ErrUserAlreadyVerified is returned when asking to verify an account that is already active.
+	ErrUserAlreadyVerified = errors.New("user is already verified")
+
+	// ErrUserNotVerified is returned when a user who has not verified their email address places an order or owns a shop.
+	ErrUserNotVerified = errors.New("user has not verified their email address")
+
+	// ErrInvalidPhone is returned when a phone number cannot be read as an E.164 number.
+	ErrInvalidPhone = errors.New("invalid phone number")
+
+	// ErrInvalidOTP is returned when a one-time password does not match the code sent.
+	ErrInvalidOTP = errors.New("invalid one-time password")
+
+	// ErrOTPExpired is returned when a one-time password is used after its expiry.
+	ErrOTPExpired = errors.New("one-time password expired")
+
+	// ErrOTPAttemptsExceeded is returned when too many wrong codes were entered; a new code must be requested.
+	ErrOTPAttemptsExceeded = errors.New("too many one-time password attempts")
+
+	// ErrOTPThrottled is returned when codes are requested for a phone too soon or too often.
+	ErrOTPThrottled = errors.New("one-time password requested too often")
+
+	// ErrIncorrectPassword is returned when the current password given to change it does not match.
+	ErrIncorrectPassword = errors.New("incorrect password")
+
+	// ErrInvalidCredentials is returned when signing in with an unknown login or a wrong password.
+	ErrInvalidCredentials = errors.New("invalid login or password")
+
+	// ErrTooManyAttempts is returned when an account's password is changed or reset too often.
+	ErrTooManyAttempts = errors.New("too many attempts; try again later")
+
+	// ErrInvalidResetChannel is returned when a password reset link cannot be sent through the channel asked for.
+	ErrInvalidResetChannel = errors.New("invalid password reset channel")
+
+	// ErrInvalidResetToken is returned when a password reset token is unknown, already used or revoked.
+	ErrInvalidResetToken = errors.New("invalid password reset token")
+
+	// ErrResetTokenExpired is returned when a password reset token is used after its expiry.
+	ErrResetTokenExpired = errors.New("password reset token expired")
+
+	// ErrPlotNotFound is returned when a plot is not found.
+	ErrPlotNotFound = errors.New("plot not found")
+
+	// ErrAddressNotFound is returned when an address is not found, or not in the address book of the user using it.
+	ErrAddressNotFound = errors.New("address not found")
+
+	// ErrTooManyAddresses is returned when adding an address to a full address book.
+	ErrTooManyAddresses = errors.New("address book is full")
+
+	// ErrAccountClosed is returned when acting on behalf of a user who has closed their account.
+	ErrAccountClosed = errors.New("account is closed")
+
+	// ErrNotAuthorized is returned when a user's role does not allow what they asked for.
+	ErrNotAuthorized = errors.New("not authorized")
+
+	// ErrInvalidRole is returned when granting a role that does not exist.
+	ErrInvalidRole = errors.New("invalid role")
+
+	// ErrInvalidSearchArea is returned when a search has invalid coordinates or a radius out of range.
+	ErrInvalidSearchArea = errors.New("invalid search area")
+
+	// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired or its session revoked.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+	// ErrRefreshTokenReused is returned when a refresh token that was already replaced is used again;
+	// its session is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reused; session revoked")
+
+	// ErrSessionNotFound is returned when a session is not found among a user's sessions.
+	ErrSessionNotFound = errors.New("session not found")
+
 	// Add more custom error variables as needed for your specific application.
 )
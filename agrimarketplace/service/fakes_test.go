@@ -0,0 +1,239 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The fakes below keep records in memory for service tests. Each embeds the repository interface
+// it fakes, so calling a method a test does not expect panics instead of passing silently.
+
+// fakeUserRepository is an in-memory UserRepository.
+type fakeUserRepository struct {
+	repository.UserRepository
+	users map[primitive.ObjectID]*models.User
+}
+
+func newFakeUserRepository(users ...*models.User) *fakeUserRepository {
+	r := &fakeUserRepository{users: map[primitive.ObjectID]*models.User{}}
+	for _, user := range users {
+		r.users[user.ID] = user
+	}
+	return r
+}
+
+func (r *fakeUserRepository) find(match func(user *models.User) bool) (*models.User, error) {
+	for _, user := range r.users {
+		if user.DeletedAt == nil && match(user) {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepository) FindUserByID(id string) (*models.User, error) {
+	return r.find(func(user *models.User) bool { return user.ID.Hex() == id })
+}
+
+func (r *fakeUserRepository) FindUserByUsername(username string) (*models.User, error) {
+	return r.find(func(user *models.User) bool { return user.Username == username })
+}
+
+func (r *fakeUserRepository) FindUserByEmail(email string) (*models.User, error) {
+	return r.find(func(user *models.User) bool { return user.Email == email })
+}
+
+func (r *fakeUserRepository) FindUserByPhone(phone string) (*models.User, error) {
+	return r.find(func(user *models.User) bool { return user.Phone == phone })
+}
+
+func (r *fakeUserRepository) SetUserPassword(id primitive.ObjectID, passwordHash string) error {
+	if user, ok := r.users[id]; ok {
+		user.Password = passwordHash
+		user.TokenVersion++
+	}
+	return nil
+}
+
+func (r *fakeUserRepository) AnonymizeUser(id primitive.ObjectID, username string, closedAt time.Time) error {
+	if user, ok := r.users[id]; ok && user.DeletedAt == nil {
+		*user = models.User{
+			ID:           user.ID,
+			Username:     username,
+			TokenVersion: user.TokenVersion + 1,
+			Role:         user.Role,
+			Discoverable: user.Discoverable,
+			Status:       models.UserStatusClosed,
+			VerifiedAt:   user.VerifiedAt,
+			ClosedAt:     &closedAt,
+		}
+	}
+	return nil
+}
+
+// fakeSessionRepository is an in-memory SessionRepository.
+type fakeSessionRepository struct {
+	repository.SessionRepository
+	sessions map[primitive.ObjectID]*models.Session
+}
+
+func newFakeSessionRepository() *fakeSessionRepository {
+	return &fakeSessionRepository{sessions: map[primitive.ObjectID]*models.Session{}}
+}
+
+func (r *fakeSessionRepository) InsertSession(session *models.Session) error {
+	stored := *session
+	r.sessions[session.ID] = &stored
+	return nil
+}
+
+func (r *fakeSessionRepository) FindSessionByID(id string) (*models.Session, error) {
+	for _, session := range r.sessions {
+		if session.ID.Hex() == id {
+			found := *session
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeSessionRepository) RevokeUserSessions(userID primitive.ObjectID, reason string, revokedAt time.Time) (int64, error) {
+	var revoked int64
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &revokedAt
+			session.RevokedReason = reason
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+// fakeRateLimitRepository is an in-memory RateLimitRepository whose window never ends.
+type fakeRateLimitRepository struct {
+	hits map[string]int
+}
+
+func newFakeRateLimitRepository() *fakeRateLimitRepository {
+	return &fakeRateLimitRepository{hits: map[string]int{}}
+}
+
+func (r *fakeRateLimitRepository) Hit(key string, window time.Duration, now time.Time) (int, error) {
+	r.hits[key]++
+	return r.hits[key], nil
+}
+
+// fakeProductRepository is a ProductRepository that records the catalog queries it is given.
+type fakeProductRepository struct {
+	repository.ProductRepository
+	queries []models.ProductQuery
+	page    models.ProductPage
+}
+
+func (r *fakeProductRepository) FindProducts(query models.ProductQuery) (*models.ProductPage, error) {
+	r.queries = append(r.queries, query)
+	page := r.page
+	return &page, nil
+}
+
+// fakeAddressRepository is an in-memory AddressRepository.
+type fakeAddressRepository struct {
+	repository.AddressRepository
+	addresses []models.Address
+}
+
+func (r *fakeAddressRepository) DeleteAddressesByUser(userID primitive.ObjectID) (int64, error) {
+	var kept []models.Address
+	for _, address := range r.addresses {
+		if address.UserID != userID {
+			kept = append(kept, address)
+		}
+	}
+	deleted := int64(len(r.addresses) - len(kept))
+	r.addresses = kept
+	return deleted, nil
+}
+
+// fakePlotRepository is an in-memory PlotRepository whose deletions fail with err when it is set.
+type fakePlotRepository struct {
+	repository.PlotRepository
+	plots []models.Plot
+	err   error
+}
+
+func (r *fakePlotRepository) DeletePlotsByUser(userID primitive.ObjectID) (int64, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	var kept []models.Plot
+	for _, plot := range r.plots {
+		if plot.UserID != userID {
+			kept = append(kept, plot)
+		}
+	}
+	deleted := int64(len(r.plots) - len(kept))
+	r.plots = kept
+	return deleted, nil
+}
+
+// fakePurchaseRepository is an in-memory PurchaseRepository.
+type fakePurchaseRepository struct {
+	repository.PurchaseRepository
+	purchases []models.Purchase
+}
+
+func (r *fakePurchaseRepository) RedactDeliveryAddresses(userID primitive.ObjectID) (int64, error) {
+	var redacted int64
+	for i := range r.purchases {
+		address := r.purchases[i].DeliveryAddress
+		if r.purchases[i].UserID != userID || address == nil {
+			continue
+		}
+		address.Line1, address.Line2, address.Landmark = "", "", ""
+		address.Latitude, address.Longitude = 0, 0
+		redacted++
+	}
+	return redacted, nil
+}
+
+// fakeOTPRepository is an in-memory OTPRepository.
+type fakeOTPRepository struct {
+	repository.OTPRepository
+	challenges []models.OTPChallenge
+}
+
+func (r *fakeOTPRepository) FindOTPByPhone(phone string) (*models.OTPChallenge, error) {
+	for _, challenge := range r.challenges {
+		if challenge.Phone == phone {
+			found := challenge
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeOTPRepository) DeleteOTP(id primitive.ObjectID) error {
+	var kept []models.OTPChallenge
+	for _, challenge := range r.challenges {
+		if challenge.ID != id {
+			kept = append(kept, challenge)
+		}
+	}
+	r.challenges = kept
+	return nil
+}
+
+// fakePrivacyAuditRepository is an in-memory PrivacyAuditRepository.
+type fakePrivacyAuditRepository struct {
+	repository.PrivacyAuditRepository
+	entries []models.PrivacyAuditEntry
+}
+
+func (r *fakePrivacyAuditRepository) InsertPrivacyAuditEntry(entry *models.PrivacyAuditEntry) error {
+	r.entries = append(r.entries, *entry)
+	return nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"time"
+)
+
+// allocateFEFO plans taking a quantity from lots first-expiry-first-out.
+// Expired lots are never allocated. The lots must be ordered by expiry, soonest first.
+func allocateFEFO(lots []models.StockLot, quantity int, now time.Time) ([]models.LotAllocation, error) {
+	var allocations []models.LotAllocation
+	remaining := quantity
+	for _, lot := range lots {
+		if remaining == 0 {
+			break
+		}
+		if lot.ExpiredAt(now) || lot.Quantity <= 0 {
+			continue
+		}
+
+		taken := lot.Quantity
+		if taken > remaining {
+			taken = remaining
+		}
+		allocations = append(allocations, models.LotAllocation{
+			LotID:     lot.ID,
+			LotNumber: lot.LotNumber,
+			ExpiresAt: lot.ExpiresAt,
+			Quantity:  taken,
+		})
+		remaining -= taken
+	}
+
+	if remaining > 0 {
+		return nil, ErrInsufficientStock
+	}
+	return allocations, nil
+}
+
+// availableLotQuantity returns the quantity of the lots that can still be sold.
+func availableLotQuantity(lots []models.StockLot, now time.Time) int {
+	quantity := 0
+	for _, lot := range lots {
+		if !lot.ExpiredAt(now) && lot.Quantity > 0 {
+			quantity += lot.Quantity
+		}
+	}
+	return quantity
+}
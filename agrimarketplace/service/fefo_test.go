@@ -0,0 +1,98 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAllocateFEFO(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	lot := func(number string, expiresIn time.Duration, quantity int) models.StockLot {
+		return models.StockLot{ID: primitive.NewObjectID(), LotNumber: number, ExpiresAt: now.Add(expiresIn), Quantity: quantity}
+	}
+
+	// Ordered by expiry, soonest first, as the repository returns them
+	expired := lot("EXP", -day, 50)
+	expiringNow := lot("NOW", 0, 50) // Expires at this very moment, so may no longer be sold
+	soon := lot("SOON", 10*day, 5)
+	empty := lot("EMPTY", 20*day, 0)
+	later := lot("LATER", 90*day, 20)
+	lots := []models.StockLot{expired, expiringNow, soon, empty, later}
+
+	tests := []struct {
+		name     string
+		quantity int
+		want     map[string]int // Quantity taken from each lot, by lot number, in allocation order
+		order    []string
+		wantErr  error
+	}{
+		{"from the soonest lot", 3, map[string]int{"SOON": 3}, []string{"SOON"}, nil},
+		{"soonest lot used up exactly", 5, map[string]int{"SOON": 5}, []string{"SOON"}, nil},
+		{"across lots", 12, map[string]int{"SOON": 5, "LATER": 7}, []string{"SOON", "LATER"}, nil},
+		{"all unexpired stock", 25, map[string]int{"SOON": 5, "LATER": 20}, []string{"SOON", "LATER"}, nil},
+		// Expired lots hold 100 units, but they may not be sold
+		{"more than unexpired stock", 26, nil, nil, ErrInsufficientStock},
+		{"more than total stock", 500, nil, nil, ErrInsufficientStock},
+	}
+
+	for _, tt := range tests {
+		allocations, err := allocateFEFO(lots, tt.quantity, now)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: allocateFEFO(%d) error = %v, want %v", tt.name, tt.quantity, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr != nil {
+			if allocations != nil {
+				t.Errorf("%s: allocateFEFO(%d) = %+v, want no allocations", tt.name, tt.quantity, allocations)
+			}
+			continue
+		}
+
+		var order []string
+		total := 0
+		for _, allocation := range allocations {
+			order = append(order, allocation.LotNumber)
+			if allocation.Quantity != tt.want[allocation.LotNumber] {
+				t.Errorf("%s: took %d from lot %s, want %d", tt.name, allocation.Quantity, allocation.LotNumber, tt.want[allocation.LotNumber])
+			}
+			total += allocation.Quantity
+		}
+		if !reflect.DeepEqual(order, tt.order) {
+			t.Errorf("%s: allocated lots %v, want %v", tt.name, order, tt.order)
+		}
+		if total != tt.quantity {
+			t.Errorf("%s: allocated %d in total, want %d", tt.name, total, tt.quantity)
+		}
+	}
+}
+
+func TestAllocateFEFOKeepsLotDetails(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	lot := models.StockLot{ID: primitive.NewObjectID(), LotNumber: "B-17", ExpiresAt: now.Add(time.Hour), Quantity: 4}
+
+	allocations, err := allocateFEFO([]models.StockLot{lot}, 4, now)
+	want := []models.LotAllocation{{LotID: lot.ID, LotNumber: "B-17", ExpiresAt: lot.ExpiresAt, Quantity: 4}}
+	if err != nil || !reflect.DeepEqual(allocations, want) {
+		t.Errorf("allocateFEFO() = %+v, %v, want %+v", allocations, err, want)
+	}
+}
+
+func TestAvailableLotQuantity(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	lots := []models.StockLot{
+		{ExpiresAt: now.Add(-time.Hour), Quantity: 10},
+		{ExpiresAt: now, Quantity: 10},
+		{ExpiresAt: now.Add(time.Hour), Quantity: 3},
+		{ExpiresAt: now.Add(48 * time.Hour), Quantity: 4},
+	}
+
+	if got := availableLotQuantity(lots, now); got != 7 {
+		t.Errorf("availableLotQuantity() = %d, want 7", got)
+	}
+}
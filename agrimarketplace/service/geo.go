@@ -0,0 +1,116 @@
+package service
+
+import "math"
+
+// Square metres in the units of land area.
+const (
+	sqMPerHectare = 10000.0
+	sqMPerAcre    = 4046.8564224
+)
+
+// earthRadiusKm is the mean radius of the earth used for great-circle distances.
+const earthRadiusKm = 6371.0
+
+// haversineKm returns the great-circle distance in kilometres between two coordinates.
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// wrapLongitude brings a longitude into the range [-180, 180).
+func wrapLongitude(longitude float64) float64 {
+	return math.Mod(math.Mod(longitude+180, 360)+360, 360) - 180
+}
+
+// ringAreaSqM returns the area in square metres enclosed by a closed ring of [longitude, latitude]
+// positions on the sphere, whatever the ring's winding order.
+func ringAreaSqM(ring [][]float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	// Sum of the spherical excess of each edge; positions are closed, so the last repeats the first
+	n := len(ring) - 1
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		previous, current, next := ring[(i+n-1)%n], ring[i], ring[(i+1)%n]
+		sum += (toRad(next[0]) - toRad(previous[0])) * math.Sin(toRad(current[1]))
+	}
+
+	radius := earthRadiusKm * 1000
+	return math.Abs(sum * radius * radius / 2)
+}
+
+// ringSelfIntersects reports whether any two edges of a closed ring of [longitude, latitude] positions
+// cross, touch or overlap, other than consecutive edges meeting at the position they share.
+// Plots are small enough for their edges to be treated as straight lines on the plane.
+func ringSelfIntersects(ring [][]float64) bool {
+	// Repeated positions add edges of no length, which would touch the edges on either side
+	var points [][]float64
+	for _, position := range ring {
+		if last := len(points) - 1; last < 0 || position[0] != points[last][0] || position[1] != points[last][1] {
+			points = append(points, position)
+		}
+	}
+
+	edges := len(points) - 1 // The last position repeats the first
+	for i := 0; i < edges; i++ {
+		for j := i + 1; j < edges; j++ {
+			switch {
+			case j == i+1:
+				if foldsBack(points[j], points[i], points[j+1]) {
+					return true
+				}
+			case i == 0 && j == edges-1:
+				// The last edge ends where the first starts
+				if foldsBack(points[0], points[1], points[j]) {
+					return true
+				}
+			case segmentsIntersect(points[i], points[i+1], points[j], points[j+1]):
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// foldsBack reports whether the edges from shared to p and from shared to q overlap.
+func foldsBack(shared, p, q []float64) bool {
+	return orientation(shared, p, q) == 0 &&
+		(p[0]-shared[0])*(q[0]-shared[0])+(p[1]-shared[1])*(q[1]-shared[1]) > 0
+}
+
+// segmentsIntersect reports whether the segments ab and cd have any point in common.
+func segmentsIntersect(a, b, c, d []float64) bool {
+	o1, o2 := orientation(a, b, c), orientation(a, b, d)
+	o3, o4 := orientation(c, d, a), orientation(c, d, b)
+	if o1*o2 < 0 && o3*o4 < 0 {
+		return true
+	}
+	return (o1 == 0 && withinBox(a, b, c)) || (o2 == 0 && withinBox(a, b, d)) ||
+		(o3 == 0 && withinBox(c, d, a)) || (o4 == 0 && withinBox(c, d, b))
+}
+
+// orientation returns 1 when r lies left of the line from p to q, -1 when it lies right of it
+// and 0 when the three positions are collinear.
+func orientation(p, q, r []float64) int {
+	cross := (q[0]-p[0])*(r[1]-p[1]) - (q[1]-p[1])*(r[0]-p[0])
+	switch {
+	case cross > 0:
+		return 1
+	case cross < 0:
+		return -1
+	}
+	return 0
+}
+
+// withinBox reports whether r lies in the bounding box of p and q.
+func withinBox(p, q, r []float64) bool {
+	return math.Min(p[0], q[0]) <= r[0] && r[0] <= math.Max(p[0], q[0]) &&
+		math.Min(p[1], q[1]) <= r[1] && r[1] <= math.Max(p[1], q[1])
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"math"
+	"testing"
+)
+
+// rectangle returns the closed ring of a rectangle of the given size in metres, with its south-west
+// corner at a position, wound counter-clockwise.
+func rectangle(longitude, latitude, widthM, heightM float64) [][]float64 {
+	metresPerDegree := earthRadiusKm * 1000 * math.Pi / 180
+	east := longitude + widthM/(metresPerDegree*math.Cos(latitude*math.Pi/180))
+	north := latitude + heightM/metresPerDegree
+	return [][]float64{{longitude, latitude}, {east, latitude}, {east, north}, {longitude, north}, {longitude, latitude}}
+}
+
+// reversed returns a ring wound the other way.
+func reversed(ring [][]float64) [][]float64 {
+	out := make([][]float64, len(ring))
+	for i, position := range ring {
+		out[len(ring)-1-i] = position
+	}
+	return out
+}
+
+func TestRingAreaSqM(t *testing.T) {
+	// Area between two meridians and two parallels on the sphere
+	radius := earthRadiusKm * 1000
+	degree := math.Pi / 180
+	oneDegreeCell := radius * radius * degree * (math.Sin(degree) - math.Sin(0))
+	corner := rectangle(78, 17, 100, 100)
+
+	tests := []struct {
+		name      string
+		ring      [][]float64
+		want      float64
+		tolerance float64 // Relative
+	}{
+		{"hectare at the equator", rectangle(0, 0, 100, 100), 10000, 0.001},
+		{"two hectares in Punjab", rectangle(75.85, 30.9, 200, 100), 20000, 0.001},
+		{"acre in Karnataka", rectangle(76.6, 12.3, 63.614907, 63.614907), sqMPerAcre, 0.001},
+		{"wound clockwise", reversed(rectangle(75.85, 30.9, 200, 100)), 20000, 0.001},
+		{"right triangle", [][]float64{corner[0], corner[1], corner[3], corner[0]}, 5000, 0.001},
+		{"one degree cell", [][]float64{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}, oneDegreeCell, 0.001},
+		{"no area", [][]float64{{78, 17}, {78.001, 17}, {78.002, 17}, {78, 17}}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		got := ringAreaSqM(tt.ring)
+		if math.Abs(got-tt.want) > tt.tolerance*tt.want+1e-6 {
+			t.Errorf("%s: ringAreaSqM() = %.2f, want %.2f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRingSelfIntersects(t *testing.T) {
+	tests := []struct {
+		name string
+		ring [][]float64
+		want bool
+	}{
+		{"square", [][]float64{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}, false},
+		{"triangle", [][]float64{{0, 0}, {2, 0}, {0, 2}, {0, 0}}, false},
+		{"concave", [][]float64{{0, 0}, {3, 0}, {3, 1}, {1, 1}, {1, 3}, {0, 3}, {0, 0}}, false},
+		{"collinear positions", [][]float64{{0, 0}, {1, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}, false},
+		{"repeated position", [][]float64{{0, 0}, {2, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}, false},
+		{"bowtie", [][]float64{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}, true},
+		{"edges crossing far apart", [][]float64{{0, 0}, {4, 0}, {4, 4}, {1, 4}, {1, -1}, {0, -1}, {0, 0}}, true},
+		{"touching at a position", [][]float64{{0, 0}, {2, 0}, {1, 1}, {2, 2}, {0, 2}, {1, 1}, {0, 0}}, true},
+		{"position on another edge", [][]float64{{0, 0}, {4, 0}, {4, 2}, {2, 0}, {0, 2}, {0, 0}}, true},
+		{"spike folding back", [][]float64{{0, 0}, {2, 0}, {2, 2}, {2, 1}, {0, 2}, {0, 0}}, true},
+		{"last edge folding back over the first", [][]float64{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {1, 0}, {0, 0}}, true},
+		{"overlapping edges", [][]float64{{0, 0}, {3, 0}, {3, 1}, {1, 0}, {2, 0}, {0, 1}, {0, 0}}, true},
+	}
+
+	for _, tt := range tests {
+		if got := ringSelfIntersects(tt.ring); got != tt.want {
+			t.Errorf("%s: ringSelfIntersects() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import "strings"
+
+// geohashAlphabet is the base-32 alphabet of geohashes.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// geohashCell returns the geohash of the given length containing a coordinate, with the centre of its cell.
+// Each character narrows the cell; five characters give cells of about 5 km, six of about 1 km.
+func geohashCell(latitude, longitude float64, precision int) (hash string, centerLat, centerLon float64) {
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+
+	var builder strings.Builder
+	bits, index := 0, 0
+	evenBit := true // Bits alternate between longitude and latitude, starting with longitude
+	for builder.Len() < precision {
+		if evenBit {
+			mid := (minLon + maxLon) / 2
+			index <<= 1
+			if longitude >= mid {
+				index |= 1
+				minLon = mid
+			} else {
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			index <<= 1
+			if latitude >= mid {
+				index |= 1
+				minLat = mid
+			} else {
+				maxLat = mid
+			}
+		}
+		evenBit = !evenBit
+
+		if bits++; bits == 5 {
+			builder.WriteByte(geohashAlphabet[index])
+			bits, index = 0, 0
+		}
+	}
+
+	return builder.String(), (minLat + maxLat) / 2, (minLon + maxLon) / 2
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxAllocationAttempts is the number of times an allocation is retried when lots change concurrently.
+const maxAllocationAttempts = 3
+
+// InventoryService defines the interface for working with the stock lots held by shops.
+type InventoryService interface {
+	ReceiveLot(shopID, productID string, lot *models.StockLot) error
+	GetLots(shopID, productID string) ([]models.StockLot, error)
+	RecordSale(shopID, productID string, quantity int) ([]models.LotAllocation, error)
+	AllocateStock(shopID, productID primitive.ObjectID, quantity int) ([]models.LotAllocation, error)
+	ReleaseStock(shopID, productID primitive.ObjectID, quantity int, allocations []models.LotAllocation) error
+	FindNearExpiryLots(shopID string, within time.Duration) ([]models.NearExpiryLot, error)
+	RefreshExpiredStock(since time.Time) (int, error)
+}
+
+// inventoryService is an implementation of the InventoryService interface.
+type inventoryService struct {
+	stockLotRepo           repository.StockLotRepository
+	serviceableProductRepo repository.ServiceableProductRepository
+	shopRepo               repository.ShopRepository
+	productRepo            repository.ProductRepository
+	licenceService         LicenceService
+}
+
+// NewInventoryService creates a new instance of the inventoryService.
+func NewInventoryService(stockLotRepo repository.StockLotRepository, serviceableProductRepo repository.ServiceableProductRepository, shopRepo repository.ShopRepository, productRepo repository.ProductRepository, licenceService LicenceService) InventoryService {
+	return &inventoryService{
+		stockLotRepo:           stockLotRepo,
+		serviceableProductRepo: serviceableProductRepo,
+		shopRepo:               shopRepo,
+		productRepo:            productRepo,
+		licenceService:         licenceService,
+	}
+}
+
+// ReceiveLot records a lot received by a shop and adds it to the shop's stock of the product.
+// The first lot received switches the listing to lot tracking, after which its stock is the
+// unexpired quantity of its lots.
+func (s *inventoryService) ReceiveLot(shopID, productID string, lot *models.StockLot) error {
+	lot.LotNumber = strings.TrimSpace(lot.LotNumber)
+	if lot.LotNumber == "" || lot.Quantity <= 0 || lot.ExpiresAt.IsZero() ||
+		(!lot.ManufacturedAt.IsZero() && !lot.ManufacturedAt.Before(lot.ExpiresAt)) {
+		return ErrInvalidLot
+	}
+
+	now := time.Now()
+	if lot.ExpiredAt(now) {
+		return ErrLotExpired
+	}
+
+	shop, product, err := s.findShopProduct(shopID, productID)
+	if err != nil {
+		return err
+	}
+	if err := s.licenceService.CheckShopLicence(shop.ID, product); err != nil {
+		return err
+	}
+
+	// Generate an ObjectID for the lot
+	lot.ID = primitive.NewObjectID()
+	lot.ShopID = shop.ID
+	lot.ProductID = product.ID
+	lot.ReceivedAt = now
+
+	if err := s.stockLotRepo.InsertLot(lot); err != nil {
+		return err
+	}
+
+	return s.refreshListingStock(shop.ID, product.ID)
+}
+
+// GetLots retrieves the lots of a product held by a shop, in the order sales are allocated from them.
+func (s *inventoryService) GetLots(shopID, productID string) ([]models.StockLot, error) {
+	shop, product, err := s.findShopProduct(shopID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.stockLotRepo.FindLotsByShopProduct(shop.ID, product.ID)
+}
+
+// RecordSale records a sale made by a shop outside of the marketplace, such as over the counter.
+func (s *inventoryService) RecordSale(shopID, productID string, quantity int) ([]models.LotAllocation, error) {
+	shop, product, err := s.findShopProduct(shopID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.licenceService.CheckShopLicence(shop.ID, product); err != nil {
+		return nil, err
+	}
+
+	return s.AllocateStock(shop.ID, product.ID, quantity)
+}
+
+// AllocateStock takes a quantity of a product out of a shop's stock.
+// Listings tracked by lot are allocated first-expiry-first-out from their unexpired lots, and the
+// allocations are returned; other listings are simply decremented.
+func (s *inventoryService) AllocateStock(shopID, productID primitive.ObjectID, quantity int) ([]models.LotAllocation, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidStockQuantity
+	}
+
+	listing, err := s.serviceableProductRepo.FindServiceableProduct(shopID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if listing == nil || !listing.IsServiceable {
+		return nil, ErrNotServiceable
+	}
+
+	if !listing.LotTracked {
+		allocated, err := s.serviceableProductRepo.AdjustServiceableProductStock(shopID, productID, -quantity)
+		if err != nil {
+			return nil, err
+		}
+		if !allocated {
+			return nil, ErrInsufficientStock
+		}
+		return nil, nil
+	}
+
+	for attempt := 0; attempt < maxAllocationAttempts; attempt++ {
+		lots, err := s.stockLotRepo.FindLotsByShopProduct(shopID, productID)
+		if err != nil {
+			return nil, err
+		}
+
+		allocations, err := allocateFEFO(lots, quantity, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		applied, err := s.applyAllocations(allocations)
+		if err != nil {
+			return nil, err
+		}
+		if !applied {
+			continue // Another sale took from the same lots; plan again from their current quantities
+		}
+
+		return allocations, s.refreshListingStock(shopID, productID)
+	}
+
+	return nil, ErrInsufficientStock
+}
+
+// ReleaseStock returns stock taken by AllocateStock, for instance when an order cannot be completed.
+func (s *inventoryService) ReleaseStock(shopID, productID primitive.ObjectID, quantity int, allocations []models.LotAllocation) error {
+	if len(allocations) == 0 {
+		_, err := s.serviceableProductRepo.AdjustServiceableProductStock(shopID, productID, quantity)
+		return err
+	}
+
+	for _, allocation := range allocations {
+		if _, err := s.stockLotRepo.AdjustLotQuantity(allocation.LotID, allocation.Quantity); err != nil {
+			return err
+		}
+	}
+
+	return s.refreshListingStock(shopID, productID)
+}
+
+// FindNearExpiryLots lists the lots of a shop that expire within the given period, soonest first,
+// together with the expired lots still in stock, which must be returned or destroyed.
+func (s *inventoryService) FindNearExpiryLots(shopID string, within time.Duration) ([]models.NearExpiryLot, error) {
+	shop, err := s.shopRepo.FindShopByID(shopID)
+	if err != nil {
+		return nil, err
+	}
+	if shop == nil {
+		return nil, ErrShopNotFound
+	}
+
+	now := time.Now()
+	lots, err := s.stockLotRepo.FindLotsExpiringBetween(shop.ID, time.Time{}, now.Add(within))
+	if err != nil {
+		return nil, err
+	}
+
+	var productIDs []primitive.ObjectID
+	for _, lot := range lots {
+		productIDs = append(productIDs, lot.ProductID)
+	}
+	productNames := map[primitive.ObjectID]string{}
+	if len(productIDs) > 0 {
+		products, err := s.productRepo.FindProductsByIDs(productIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range products {
+			productNames[product.ID] = product.ProductName
+		}
+	}
+
+	report := make([]models.NearExpiryLot, 0, len(lots))
+	for _, lot := range lots {
+		report = append(report, models.NearExpiryLot{
+			StockLot:      lot,
+			ProductName:   productNames[lot.ProductID],
+			DaysRemaining: int(math.Ceil(lot.ExpiresAt.Sub(now).Hours() / 24)),
+			Expired:       lot.ExpiredAt(now),
+		})
+	}
+
+	return report, nil
+}
+
+// RefreshExpiredStock removes the lots that expired since the given time from the stock of their listings.
+// It returns the number of listings updated.
+func (s *inventoryService) RefreshExpiredStock(since time.Time) (int, error) {
+	lots, err := s.stockLotRepo.FindLotsExpiringBetween(primitive.NilObjectID, since, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	type listingKey struct{ shopID, productID primitive.ObjectID }
+	refreshed := map[listingKey]bool{}
+	for _, lot := range lots {
+		key := listingKey{lot.ShopID, lot.ProductID}
+		if refreshed[key] {
+			continue
+		}
+		if err := s.refreshListingStock(lot.ShopID, lot.ProductID); err != nil {
+			return len(refreshed), err
+		}
+		refreshed[key] = true
+	}
+
+	return len(refreshed), nil
+}
+
+// applyAllocations takes the allocated quantities out of their lots. When a lot no longer holds
+// enough stock, the allocations already applied are undone and false is returned.
+func (s *inventoryService) applyAllocations(allocations []models.LotAllocation) (bool, error) {
+	for i, allocation := range allocations {
+		applied, err := s.stockLotRepo.AdjustLotQuantity(allocation.LotID, -allocation.Quantity)
+		if err == nil && applied {
+			continue
+		}
+
+		for _, done := range allocations[:i] {
+			if _, undoErr := s.stockLotRepo.AdjustLotQuantity(done.LotID, done.Quantity); undoErr != nil {
+				log.Printf("Error returning %d units to lot %s: %v", done.Quantity, done.LotNumber, undoErr)
+			}
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// refreshListingStock sets the stock of a listing to the unexpired quantity of its lots.
+func (s *inventoryService) refreshListingStock(shopID, productID primitive.ObjectID) error {
+	lots, err := s.stockLotRepo.FindLotsByShopProduct(shopID, productID)
+	if err != nil {
+		return err
+	}
+
+	return s.serviceableProductRepo.SetServiceableProductLotStock(shopID, productID, availableLotQuantity(lots, time.Now()))
+}
+
+// findShopProduct loads a shop and a product, checking that both exist.
+func (s *inventoryService) findShopProduct(shopID, productID string) (*models.Shop, *models.Product, error) {
+	shop, err := s.shopRepo.FindShopByID(shopID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if shop == nil {
+		return nil, nil, ErrShopNotFound
+	}
+
+	product, err := s.productRepo.FindProductByID(productID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if product == nil {
+		return nil, nil, ErrProductNotFound
+	}
+
+	return shop, product, nil
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LicenceService defines the interface for working with shop licences for regulated products.
+type LicenceService interface {
+	RegisterLicence(shopID string, licence *models.ShopLicence) error
+	GetShopLicences(shopID string) ([]models.ShopLicence, error)
+	DeleteLicence(shopID, licenceID string) error
+	FindExpiringLicences(within time.Duration) ([]models.ExpiringLicence, error)
+	RequiredLicence(product *models.Product) (string, error)
+	CheckShopLicence(shopID primitive.ObjectID, product *models.Product) error
+	LicensedShops(product *models.Product, shopIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, error)
+	FilterLicensedListings(listings []models.ServiceableProduct) ([]models.ServiceableProduct, error)
+}
+
+// licenceService is an implementation of the LicenceService interface.
+type licenceService struct {
+	licenceRepo  repository.LicenceRepository
+	shopRepo     repository.ShopRepository
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+}
+
+// NewLicenceService creates a new instance of the licenceService.
+func NewLicenceService(licenceRepo repository.LicenceRepository, shopRepo repository.ShopRepository, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository) LicenceService {
+	return &licenceService{
+		licenceRepo:  licenceRepo,
+		shopRepo:     shopRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// validLicenceType reports whether a licence type is one shops can register.
+func validLicenceType(licenceType string) bool {
+	switch licenceType {
+	case models.LicenceTypeSeed, models.LicenceTypeFertilizer, models.LicenceTypePesticide:
+		return true
+	}
+	return false
+}
+
+// RegisterLicence records a licence held by a shop.
+func (s *licenceService) RegisterLicence(shopID string, licence *models.ShopLicence) error {
+	shop, err := s.shopRepo.FindShopByID(shopID)
+	if err != nil {
+		return err
+	}
+	if shop == nil {
+		return ErrShopNotFound
+	}
+
+	licence.LicenceNumber = strings.TrimSpace(licence.LicenceNumber)
+	licence.IssuingState = strings.TrimSpace(licence.IssuingState)
+	if !validLicenceType(licence.LicenceType) {
+		return ErrInvalidLicenceType
+	}
+	if licence.LicenceNumber == "" || licence.ValidUntil.IsZero() || !licence.ValidUntil.After(licence.ValidFrom) {
+		return ErrInvalidLicence
+	}
+
+	// Generate an ObjectID for the licence
+	licence.ID = primitive.NewObjectID()
+	licence.ShopID = shop.ID
+	licence.CreatedAt = time.Now()
+
+	return s.licenceRepo.InsertLicence(licence)
+}
+
+// GetShopLicences retrieves the licences of a shop, latest expiry first.
+func (s *licenceService) GetShopLicences(shopID string) ([]models.ShopLicence, error) {
+	shop, err := s.shopRepo.FindShopByID(shopID)
+	if err != nil {
+		return nil, err
+	}
+	if shop == nil {
+		return nil, ErrShopNotFound
+	}
+
+	return s.licenceRepo.FindLicencesByShops([]primitive.ObjectID{shop.ID})
+}
+
+// DeleteLicence removes a licence from a shop.
+func (s *licenceService) DeleteLicence(shopID, licenceID string) error {
+	licence, err := s.licenceRepo.FindLicenceByID(licenceID)
+	if err != nil {
+		return err
+	}
+	if licence == nil || licence.ShopID.Hex() != shopID {
+		return ErrLicenceNotFound
+	}
+
+	return s.licenceRepo.DeleteLicence(licence.ID)
+}
+
+// FindExpiringLicences lists the licences that expire within the given period, soonest first,
+// so that shops can renew them before their regulated products are blocked.
+func (s *licenceService) FindExpiringLicences(within time.Duration) ([]models.ExpiringLicence, error) {
+	now := time.Now()
+	licences, err := s.licenceRepo.FindLicencesExpiringBetween(now, now.Add(within))
+	if err != nil {
+		return nil, err
+	}
+
+	shopNames := map[primitive.ObjectID]string{}
+	report := []models.ExpiringLicence{}
+	for _, licence := range licences {
+		name, ok := shopNames[licence.ShopID]
+		if !ok {
+			shop, err := s.shopRepo.FindShopByID(licence.ShopID.Hex())
+			if err != nil {
+				return nil, err
+			}
+			if shop != nil {
+				name = shop.ShopName
+			}
+			shopNames[licence.ShopID] = name
+		}
+		if name == "" {
+			continue // The shop was deleted
+		}
+
+		report = append(report, models.ExpiringLicence{
+			ShopLicence:   licence,
+			ShopName:      name,
+			DaysRemaining: int(math.Ceil(licence.ValidUntil.Sub(now).Hours() / 24)),
+		})
+	}
+
+	return report, nil
+}
+
+// RequiredLicence returns the licence type needed to sell a product, or "" for unregulated products.
+// A licence set on the product takes precedence over the one of its category.
+func (s *licenceService) RequiredLicence(product *models.Product) (string, error) {
+	return s.requiredLicence(product, map[primitive.ObjectID]string{})
+}
+
+// requiredLicence is RequiredLicence with the categories' licences cached across products.
+func (s *licenceService) requiredLicence(product *models.Product, categoryLicences map[primitive.ObjectID]string) (string, error) {
+	if product.RequiredLicence != "" || product.CategoryID.IsZero() {
+		return product.RequiredLicence, nil
+	}
+
+	licenceType, ok := categoryLicences[product.CategoryID]
+	if !ok {
+		category, err := s.categoryRepo.FindCategoryByID(product.CategoryID.Hex())
+		if err != nil {
+			return "", err
+		}
+		if category != nil {
+			licenceType = category.RequiredLicence
+		}
+		categoryLicences[product.CategoryID] = licenceType
+	}
+
+	return licenceType, nil
+}
+
+// CheckShopLicence checks that a shop may currently sell a product.
+func (s *licenceService) CheckShopLicence(shopID primitive.ObjectID, product *models.Product) error {
+	licenceType, err := s.RequiredLicence(product)
+	if err != nil || licenceType == "" {
+		return err
+	}
+
+	licences, err := s.licenceRepo.FindLicencesByShops([]primitive.ObjectID{shopID})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	held := false
+	for _, licence := range licences {
+		if licence.LicenceType != licenceType {
+			continue
+		}
+		if licence.ValidAt(now) {
+			return nil
+		}
+		held = true
+	}
+	if held {
+		return ErrLicenceExpired
+	}
+	return ErrLicenceRequired
+}
+
+// LicensedShops reports which of the given shops may currently sell a product.
+func (s *licenceService) LicensedShops(product *models.Product, shopIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	licenceType, err := s.RequiredLicence(product)
+	if err != nil {
+		return nil, err
+	}
+
+	licensed := make(map[primitive.ObjectID]bool, len(shopIDs))
+	if licenceType == "" {
+		for _, shopID := range shopIDs {
+			licensed[shopID] = true
+		}
+		return licensed, nil
+	}
+
+	valid, err := s.validLicences(shopIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, shopID := range shopIDs {
+		licensed[shopID] = valid[shopID][licenceType]
+	}
+
+	return licensed, nil
+}
+
+// FilterLicensedListings drops the listings of regulated products by shops without a valid licence for them.
+func (s *licenceService) FilterLicensedListings(listings []models.ServiceableProduct) ([]models.ServiceableProduct, error) {
+	if len(listings) == 0 {
+		return listings, nil
+	}
+
+	var productIDs, shopIDs []primitive.ObjectID
+	seenProducts := map[primitive.ObjectID]bool{}
+	seenShops := map[primitive.ObjectID]bool{}
+	for _, listing := range listings {
+		if !seenProducts[listing.ProductID] {
+			seenProducts[listing.ProductID] = true
+			productIDs = append(productIDs, listing.ProductID)
+		}
+		if !seenShops[listing.ShopID] {
+			seenShops[listing.ShopID] = true
+			shopIDs = append(shopIDs, listing.ShopID)
+		}
+	}
+
+	products, err := s.productRepo.FindProductsByIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryLicences := map[primitive.ObjectID]string{}
+	requiredLicences := make(map[primitive.ObjectID]string, len(products))
+	regulated := false
+	for i := range products {
+		licenceType, err := s.requiredLicence(&products[i], categoryLicences)
+		if err != nil {
+			return nil, err
+		}
+		requiredLicences[products[i].ID] = licenceType
+		regulated = regulated || licenceType != ""
+	}
+	if !regulated {
+		return listings, nil
+	}
+
+	valid, err := s.validLicences(shopIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.ServiceableProduct, 0, len(listings))
+	for _, listing := range listings {
+		licenceType := requiredLicences[listing.ProductID]
+		if licenceType == "" || valid[listing.ShopID][licenceType] {
+			filtered = append(filtered, listing)
+		}
+	}
+
+	return filtered, nil
+}
+
+// validLicences returns the licence types each shop currently holds a valid licence for.
+func (s *licenceService) validLicences(shopIDs []primitive.ObjectID) (map[primitive.ObjectID]map[string]bool, error) {
+	licences, err := s.licenceRepo.FindLicencesByShops(shopIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	valid := map[primitive.ObjectID]map[string]bool{}
+	for _, licence := range licences {
+		if !licence.ValidAt(now) {
+			continue
+		}
+		if valid[licence.ShopID] == nil {
+			valid[licence.ShopID] = map[string]bool{}
+		}
+		valid[licence.ShopID][licence.LicenceType] = true
+	}
+
+	return valid, nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"agrimarketplace/models"
+)
+
+// productStatusTransitions lists the lifecycle states a product may move to from each state.
+// Archived products can only be reworked as drafts, so that they are reviewed before being listed again.
+var productStatusTransitions = map[string][]string{
+	models.ProductStatusDraft:        {models.ProductStatusActive, models.ProductStatusArchived},
+	models.ProductStatusActive:       {models.ProductStatusDiscontinued, models.ProductStatusArchived},
+	models.ProductStatusDiscontinued: {models.ProductStatusActive, models.ProductStatusArchived},
+	models.ProductStatusArchived:     {models.ProductStatusDraft},
+}
+
+// applyProductStatus defaults the lifecycle state of a product being saved and checks it against
+// the state of the existing product, which is nil for new products.
+func applyProductStatus(product, existing *models.Product) error {
+	if existing == nil {
+		if product.Status == "" {
+			product.Status = models.ProductStatusActive
+		}
+		// New products are either being prepared or ready to sell
+		if product.Status != models.ProductStatusDraft && product.Status != models.ProductStatusActive {
+			return ErrInvalidProductStatus
+		}
+		return nil
+	}
+
+	current := existing.Status
+	if current == "" {
+		current = models.ProductStatusActive
+	}
+	if product.Status == "" || product.Status == current {
+		product.Status = current
+		return nil
+	}
+
+	if _, ok := productStatusTransitions[product.Status]; !ok {
+		return ErrInvalidProductStatus
+	}
+	for _, next := range productStatusTransitions[current] {
+		if next == product.Status {
+			return nil
+		}
+	}
+	return ErrInvalidStatusTransition
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"agrimarketplace/storage"
+	"bytes"
+	"image"
+	_ "image/gif" // Register the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxImagePixels bounds the decoded size of uploads so that small, highly
+// compressed files cannot exhaust memory when they are decoded.
+const maxImagePixels = 40 * 1000 * 1000
+
+// imageExtensions maps the accepted image content types to their file extension.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// MediaConfig holds the settings for uploaded images.
+type MediaConfig struct {
+	// MaxUploadBytes is the largest accepted image file.
+	MaxUploadBytes int64
+	// ThumbnailSize is the largest width and height of generated thumbnails.
+	ThumbnailSize int
+}
+
+// MediaService defines the interface for working with product and shop images.
+type MediaService interface {
+	UploadImage(ownerType, ownerID string, r io.Reader) (*models.Image, error)
+	GetImage(id string) (*models.Image, error)
+	GetImages(ownerType, ownerID string) ([]models.Image, error)
+	OpenImage(image *models.Image, thumbnail bool) (io.ReadCloser, error)
+	DeleteImage(id string) error
+	DeleteImagesByOwner(ownerType string, ownerID primitive.ObjectID) error
+	DeleteOrphanedImages() (int, error)
+}
+
+// mediaService is an implementation of the MediaService interface.
+type mediaService struct {
+	imageRepo   repository.ImageRepository
+	productRepo repository.ProductRepository
+	shopRepo    repository.ShopRepository
+	blobStore   storage.BlobStore
+	config      MediaConfig
+}
+
+// NewMediaService creates a new instance of the mediaService.
+func NewMediaService(imageRepo repository.ImageRepository, productRepo repository.ProductRepository, shopRepo repository.ShopRepository, blobStore storage.BlobStore, config MediaConfig) MediaService {
+	if config.MaxUploadBytes <= 0 {
+		config.MaxUploadBytes = 5 << 20
+	}
+	if config.ThumbnailSize <= 0 {
+		config.ThumbnailSize = 256
+	}
+
+	return &mediaService{
+		imageRepo:   imageRepo,
+		productRepo: productRepo,
+		shopRepo:    shopRepo,
+		blobStore:   blobStore,
+		config:      config,
+	}
+}
+
+// UploadImage validates an uploaded image, stores it with a thumbnail and attaches it to a product or shop.
+func (s *mediaService) UploadImage(ownerType, ownerID string, r io.Reader) (*models.Image, error) {
+	owner, err := s.findOwner(ownerType, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read one byte past the limit to detect oversized uploads
+	data, err := io.ReadAll(io.LimitReader(r, s.config.MaxUploadBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.config.MaxUploadBytes {
+		return nil, ErrImageTooLarge
+	}
+
+	// Trust the content, not the client-supplied content type
+	contentType := http.DetectContentType(data)
+	extension, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, ErrUnsupportedImageType
+	}
+
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImageType
+	}
+	if imageConfig.Width*imageConfig.Height > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImageType
+	}
+
+	thumb := thumbnail(src, s.config.ThumbnailSize)
+	var thumbData bytes.Buffer
+	thumbType, thumbExtension := "image/jpeg", ".jpg"
+	if contentType == "image/png" {
+		// Keep transparency, which JPEG cannot represent
+		thumbType, thumbExtension = "image/png", ".png"
+		err = png.Encode(&thumbData, thumb)
+	} else {
+		err = jpeg.Encode(&thumbData, thumb, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	img := &models.Image{
+		ID:              primitive.NewObjectID(),
+		OwnerType:       ownerType,
+		OwnerID:         owner,
+		ContentType:     contentType,
+		Size:            int64(len(data)),
+		Width:           imageConfig.Width,
+		Height:          imageConfig.Height,
+		ThumbnailType:   thumbType,
+		ThumbnailWidth:  thumb.Bounds().Dx(),
+		ThumbnailHeight: thumb.Bounds().Dy(),
+		CreatedAt:       time.Now(),
+	}
+	prefix := ownerType + "s/" + owner.Hex() + "/" + img.ID.Hex()
+	img.Key = prefix + extension
+	img.ThumbnailKey = prefix + "_thumb" + thumbExtension
+
+	if err := s.blobStore.Put(img.Key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := s.blobStore.Put(img.ThumbnailKey, &thumbData); err != nil {
+		s.deleteBlobs(img)
+		return nil, err
+	}
+	if err := s.imageRepo.InsertImage(img); err != nil {
+		s.deleteBlobs(img)
+		return nil, err
+	}
+
+	return img, nil
+}
+
+// GetImage retrieves image metadata by its ID.
+func (s *mediaService) GetImage(id string) (*models.Image, error) {
+	return s.imageRepo.FindImageByID(id)
+}
+
+// GetImages retrieves the images of a product or shop.
+func (s *mediaService) GetImages(ownerType, ownerID string) ([]models.Image, error) {
+	owner, err := s.findOwner(ownerType, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.imageRepo.FindImagesByOwner(ownerType, owner)
+}
+
+// OpenImage opens the content of an image or of its thumbnail.
+func (s *mediaService) OpenImage(image *models.Image, thumbnail bool) (io.ReadCloser, error) {
+	key := image.Key
+	if thumbnail {
+		key = image.ThumbnailKey
+	}
+
+	return s.blobStore.Get(key)
+}
+
+// DeleteImage deletes an image and its thumbnail.
+func (s *mediaService) DeleteImage(id string) error {
+	img, err := s.imageRepo.FindImageByID(id)
+	if err != nil {
+		return err
+	}
+	if img == nil {
+		return ErrImageNotFound
+	}
+
+	if err := s.imageRepo.DeleteImage(img.ID); err != nil {
+		return err
+	}
+	s.deleteBlobs(img)
+
+	return nil
+}
+
+// DeleteImagesByOwner deletes every image of a product or shop.
+func (s *mediaService) DeleteImagesByOwner(ownerType string, ownerID primitive.ObjectID) error {
+	images, err := s.imageRepo.FindImagesByOwner(ownerType, ownerID)
+	if err != nil {
+		return err
+	}
+
+	for i := range images {
+		if err := s.imageRepo.DeleteImage(images[i].ID); err != nil {
+			return err
+		}
+		s.deleteBlobs(&images[i])
+	}
+
+	return nil
+}
+
+// DeleteOrphanedImages deletes the images whose product or shop no longer exists.
+// It catches images left behind when cleanup failed during an owner's purge.
+// Images of soft-deleted owners are kept so that restoring the owner restores them too.
+func (s *mediaService) DeleteOrphanedImages() (int, error) {
+	deleted := 0
+	for _, ownerType := range []string{models.ImageOwnerProduct, models.ImageOwnerShop} {
+		ownerIDs, err := s.imageRepo.FindImageOwners(ownerType)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, ownerID := range ownerIDs {
+			if _, err := s.findOwner(ownerType, ownerID.Hex()); err != ErrImageOwnerNotFound {
+				if err != nil {
+					return deleted, err
+				}
+				continue
+			}
+			softDeleted, err := s.ownerSoftDeleted(ownerType, ownerID.Hex())
+			if err != nil {
+				return deleted, err
+			}
+			if softDeleted {
+				continue
+			}
+
+			images, err := s.imageRepo.FindImagesByOwner(ownerType, ownerID)
+			if err != nil {
+				return deleted, err
+			}
+			if err := s.DeleteImagesByOwner(ownerType, ownerID); err != nil {
+				return deleted, err
+			}
+			deleted += len(images)
+		}
+	}
+
+	return deleted, nil
+}
+
+// findOwner checks that the product or shop an image belongs to exists and returns its ID.
+func (s *mediaService) findOwner(ownerType, ownerID string) (primitive.ObjectID, error) {
+	var exists bool
+	switch ownerType {
+	case models.ImageOwnerProduct:
+		product, err := s.productRepo.FindProductByID(ownerID)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		exists = product != nil
+	case models.ImageOwnerShop:
+		shop, err := s.shopRepo.FindShopByID(ownerID)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		exists = shop != nil
+	default:
+		return primitive.NilObjectID, ErrImageOwnerNotFound
+	}
+
+	id, err := primitive.ObjectIDFromHex(ownerID)
+	if !exists || err != nil {
+		return primitive.NilObjectID, ErrImageOwnerNotFound
+	}
+
+	return id, nil
+}
+
+// ownerSoftDeleted reports whether the product or shop an image belongs to is soft-deleted.
+func (s *mediaService) ownerSoftDeleted(ownerType, ownerID string) (bool, error) {
+	switch ownerType {
+	case models.ImageOwnerProduct:
+		product, err := s.productRepo.FindDeletedProductByID(ownerID)
+		return product != nil, err
+	case models.ImageOwnerShop:
+		shop, err := s.shopRepo.FindDeletedShopByID(ownerID)
+		return shop != nil, err
+	}
+	return false, nil
+}
+
+// deleteBlobs removes the stored content of an image. Failures only leave unreferenced blobs behind.
+func (s *mediaService) deleteBlobs(img *models.Image) {
+	for _, key := range []string{img.Key, img.ThumbnailKey} {
+		if err := s.blobStore.Delete(key); err != nil {
+			log.Printf("Error deleting blob %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// timeWindow is an opening window expressed in minutes since midnight.
+type timeWindow struct {
+	open  int
+	close int
+}
+
+// operatingHours holds the opening windows of a shop for each day of the week.
+// A nil value means the shop is always open.
+type operatingHours map[time.Weekday][]timeWindow
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// parseOperatingHours parses the free-form operating hours stored on a shop.
+//
+// Clauses are separated by ";" and consist of an optional day or day range
+// followed by comma separated time ranges, for example
+// "Mon-Sat 09:00-13:00,15:00-19:00; Sun 10:00-12:00". A clause without days,
+// such as "09:00 - 18:00", applies to every day. An empty string means the
+// shop is always open.
+func parseOperatingHours(value string) (operatingHours, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	hours := operatingHours{}
+	for _, clause := range strings.Split(value, ";") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+
+		days := []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+		fields := strings.Fields(clause)
+		if unicode.IsLetter([]rune(fields[0])[0]) {
+			parsedDays, err := parseDays(fields[0])
+			if err != nil {
+				return nil, err
+			}
+			days = parsedDays
+			fields = fields[1:]
+		}
+		ranges := strings.Join(fields, "")
+
+		for _, r := range strings.Split(ranges, ",") {
+			window, err := parseTimeWindow(r)
+			if err != nil {
+				return nil, err
+			}
+			for _, day := range days {
+				hours[day] = append(hours[day], window)
+			}
+		}
+	}
+
+	for _, windows := range hours {
+		sort.Slice(windows, func(i, j int) bool { return windows[i].open < windows[j].open })
+	}
+
+	return hours, nil
+}
+
+// parseDays parses a single day ("Mon") or a day range ("Mon-Sat").
+func parseDays(value string) ([]time.Weekday, error) {
+	parts := strings.SplitN(strings.ToLower(value), "-", 2)
+
+	first, ok := weekdayNames[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("invalid day %q in operating hours", parts[0])
+	}
+	if len(parts) == 1 {
+		return []time.Weekday{first}, nil
+	}
+
+	last, ok := weekdayNames[parts[1]]
+	if !ok {
+		return nil, fmt.Errorf("invalid day %q in operating hours", parts[1])
+	}
+
+	var days []time.Weekday
+	for day := first; ; day = (day + 1) % 7 {
+		days = append(days, day)
+		if day == last {
+			break
+		}
+	}
+
+	return days, nil
+}
+
+// parseTimeWindow parses a range such as "09:00-18:00".
+func parseTimeWindow(value string) (timeWindow, error) {
+	parts := strings.SplitN(strings.TrimSpace(value), "-", 2)
+	if len(parts) != 2 {
+		return timeWindow{}, fmt.Errorf("invalid time range %q in operating hours", value)
+	}
+
+	open, err := parseClock(parts[0])
+	if err != nil {
+		return timeWindow{}, err
+	}
+	close, err := parseClock(parts[1])
+	if err != nil {
+		return timeWindow{}, err
+	}
+	if close <= open {
+		return timeWindow{}, fmt.Errorf("time range %q in operating hours must close after it opens", value)
+	}
+
+	return timeWindow{open: open, close: close}, nil
+}
+
+// parseClock parses "HH:MM" into minutes since midnight.
+func parseClock(value string) (int, error) {
+	parts := strings.SplitN(strings.TrimSpace(value), ":", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q in operating hours", value)
+	}
+
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("invalid time %q in operating hours", value)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("invalid time %q in operating hours", value)
+	}
+
+	return hour*60 + minute, nil
+}
+
+// nextOpen returns the earliest moment at or after t when the shop is open.
+// It returns t itself when the shop is open at t or has no opening hours.
+func (h operatingHours) nextOpen(t time.Time) time.Time {
+	if h == nil {
+		return t
+	}
+
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for offset := 0; offset < 8; offset++ {
+		day := midnight.AddDate(0, 0, offset)
+		for _, window := range h[day.Weekday()] {
+			open := day.Add(time.Duration(window.open) * time.Minute)
+			close := day.Add(time.Duration(window.close) * time.Minute)
+			if !t.Before(close) {
+				continue
+			}
+			if t.After(open) {
+				return t
+			}
+			return open
+		}
+	}
+
+	return t // No opening windows at all; do not block the estimate
+}
+
+// dispatchAt returns when an order received at t and taking the given preparation time can leave
+// the shop. Preparation starts once the shop is open and must finish before the window it started
+// in closes, so an order that does not fit in what is left of a window waits for the next window
+// long enough to prepare it. When no window is that long, preparation starts at the next opening.
+func (h operatingHours) dispatchAt(t time.Time, preparation time.Duration) time.Time {
+	if h == nil {
+		return t.Add(preparation)
+	}
+
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for offset := 0; offset < 8; offset++ {
+		day := midnight.AddDate(0, 0, offset)
+		for _, window := range h[day.Weekday()] {
+			start := day.Add(time.Duration(window.open) * time.Minute)
+			close := day.Add(time.Duration(window.close) * time.Minute)
+			if t.After(start) {
+				start = t
+			}
+			if ready := start.Add(preparation); !ready.After(close) {
+				return ready
+			}
+		}
+	}
+
+	return h.nextOpen(t).Add(preparation)
+}
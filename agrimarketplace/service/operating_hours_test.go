@@ -0,0 +1,113 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseOperatingHours(t *testing.T) {
+	everyDay := func(windows ...timeWindow) operatingHours {
+		hours := operatingHours{}
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			hours[day] = windows
+		}
+		return hours
+	}
+
+	tests := []struct {
+		value   string
+		want    operatingHours
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "   ", want: nil},
+		{value: "09:00-18:00", want: everyDay(timeWindow{540, 1080})},
+		{value: "09:00 - 18:00", want: everyDay(timeWindow{540, 1080})},
+		{value: "09:00 - 13:00, 15:00 - 19:00", want: everyDay(timeWindow{540, 780}, timeWindow{900, 1140})},
+		{value: "00:00-24:00", want: everyDay(timeWindow{0, 1440})},
+		{value: "Mon 09:00-18:00", want: operatingHours{time.Monday: {{540, 1080}}}},
+		{
+			value: "Mon-Wed 09:00-13:00,15:00-19:00; Sun 10:00 - 12:00",
+			want: operatingHours{
+				time.Monday:    {{540, 780}, {900, 1140}},
+				time.Tuesday:   {{540, 780}, {900, 1140}},
+				time.Wednesday: {{540, 780}, {900, 1140}},
+				time.Sunday:    {{600, 720}},
+			},
+		},
+		// Day ranges wrap around the end of the week
+		{
+			value: "FRI-mon 10:00-12:00",
+			want: operatingHours{
+				time.Friday:   {{600, 720}},
+				time.Saturday: {{600, 720}},
+				time.Sunday:   {{600, 720}},
+				time.Monday:   {{600, 720}},
+			},
+		},
+		// Windows are sorted whatever order they are written in
+		{value: "Tue 15:00-19:00; Tue 09:00-13:00", want: operatingHours{time.Tuesday: {{540, 780}, {900, 1140}}}},
+		{value: "Mon", wantErr: true},
+		{value: "Monday 09:00-18:00", wantErr: true},
+		{value: "Mon-Xyz 09:00-18:00", wantErr: true},
+		{value: "09:00", wantErr: true},
+		{value: "9am-6pm", wantErr: true},
+		{value: "18:00-09:00", wantErr: true},
+		{value: "09:00-09:00", wantErr: true},
+		{value: "09:60-18:00", wantErr: true},
+		{value: "09:00-24:30", wantErr: true},
+		{value: "Open every day", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseOperatingHours(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseOperatingHours(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseOperatingHours(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestOperatingHoursDispatchAt(t *testing.T) {
+	hours, err := parseOperatingHours("Mon-Fri 09:00-13:00,15:00-19:00; Sat 10:00-11:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC) // 19 October 2026 is a Monday
+	}
+
+	tests := []struct {
+		name        string
+		hours       operatingHours
+		now         time.Time
+		preparation time.Duration
+		want        time.Time
+	}{
+		{"always open", nil, at(19, 22, 0), 30 * time.Minute, at(19, 22, 30)},
+		{"open with time to prepare", hours, at(19, 10, 0), 30 * time.Minute, at(19, 10, 30)},
+		{"ready exactly at closing", hours, at(19, 12, 30), 30 * time.Minute, at(19, 13, 0)},
+		{"before opening", hours, at(19, 7, 0), 30 * time.Minute, at(19, 9, 30)},
+		{"between windows", hours, at(19, 14, 0), 30 * time.Minute, at(19, 15, 30)},
+		// Preparing from now would run past closing, so the order waits for the next window
+		{"too close to the midday closing", hours, at(19, 12, 45), 30 * time.Minute, at(19, 15, 30)},
+		{"too close to the evening closing", hours, at(19, 18, 45), 30 * time.Minute, at(20, 9, 30)},
+		{"after closing on Friday", hours, at(23, 20, 0), 30 * time.Minute, at(24, 10, 30)},
+		// Saturday's window is too short, so the order waits until Monday
+		{"window shorter than the preparation", hours, at(23, 20, 0), 90 * time.Minute, at(26, 10, 30)},
+		// No window is long enough, so preparation starts at the next opening
+		{"longer than every window", hours, at(19, 14, 0), 5 * time.Hour, at(19, 20, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hours.dispatchAt(tt.now, tt.preparation); !got.Equal(tt.want) {
+				t.Errorf("dispatchAt(%v, %v) = %v, want %v", tt.now, tt.preparation, got, tt.want)
+			}
+		})
+	}
+}
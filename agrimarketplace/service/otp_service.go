@@ -0,0 +1,203 @@
+package service
+
+import (
+	"agrimarketplace/auth"
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"agrimarketplace/sms"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OTPConfig holds the settings of one-time password sign-in.
+type OTPConfig struct {
+	// Digits is the length of the codes sent.
+	Digits int
+	// TTL is how long a code remains valid.
+	TTL time.Duration
+	// MaxAttempts is the number of wrong codes accepted before a new code must be requested.
+	MaxAttempts int
+	// ResendInterval is the minimum time between two codes sent to the same phone.
+	ResendInterval time.Duration
+	// MaxSends is the number of codes sent to the same phone within SendWindow.
+	MaxSends   int
+	SendWindow time.Duration
+}
+
+// OTPService defines the interface for signing in and registering with a one-time password sent by SMS.
+type OTPService interface {
+	RequestOTP(phone string) error
+	VerifyOTP(phone, code string, profile *models.User, device models.DeviceInfo) (*models.Login, error)
+}
+
+// otpService is an implementation of the OTPService interface.
+type otpService struct {
+	otpRepo        repository.OTPRepository
+	userRepo       repository.UserRepository
+	userService    UserService
+	sessionService SessionService
+	smsSender      sms.SMSSender
+	signer         *auth.Signer
+	config         OTPConfig
+}
+
+// NewOTPService creates a new instance of the otpService.
+func NewOTPService(otpRepo repository.OTPRepository, userRepo repository.UserRepository, userService UserService, sessionService SessionService, smsSender sms.SMSSender, signer *auth.Signer, config OTPConfig) OTPService {
+	return &otpService{
+		otpRepo:        otpRepo,
+		userRepo:       userRepo,
+		userService:    userService,
+		sessionService: sessionService,
+		smsSender:      smsSender,
+		signer:         signer,
+		config:         config,
+	}
+}
+
+// RequestOTP sends a new code to a phone number, replacing any code sent before.
+// The same flow serves existing users signing in and new users registering, so the
+// response does not reveal whether the number belongs to an account.
+func (s *otpService) RequestOTP(phone string) error {
+	phone, err := normalizePhone(phone)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.otpRepo.FindOTPByPhone(phone)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	challenge := &models.OTPChallenge{
+		Phone:           phone,
+		SentAt:          now,
+		WindowStartedAt: now,
+		SendCount:       1,
+	}
+	if existing != nil {
+		if now.Before(existing.SentAt.Add(s.config.ResendInterval)) {
+			return ErrOTPThrottled
+		}
+		if now.Before(existing.WindowStartedAt.Add(s.config.SendWindow)) {
+			if existing.SendCount >= s.config.MaxSends {
+				return ErrOTPThrottled
+			}
+			challenge.WindowStartedAt = existing.WindowStartedAt
+			challenge.SendCount = existing.SendCount + 1
+		}
+	}
+
+	code, err := auth.GenerateOTP(s.config.Digits)
+	if err != nil {
+		return err
+	}
+	challenge.CodeHash = s.signer.Digest(phone + ":" + code)
+	challenge.ExpiresAt = now.Add(s.config.TTL)
+
+	// The send counters must outlive the code to throttle resends
+	challenge.PurgeAt = challenge.WindowStartedAt.Add(s.config.SendWindow)
+	if challenge.PurgeAt.Before(challenge.ExpiresAt) {
+		challenge.PurgeAt = challenge.ExpiresAt
+	}
+
+	if err := s.otpRepo.SaveOTP(challenge); err != nil {
+		return err
+	}
+
+	return s.smsSender.Send(phone, fmt.Sprintf("Your Agri Marketplace code is %s. It expires in %d minutes. Do not share it with anyone.",
+		code, int(s.config.TTL.Minutes())))
+}
+
+// VerifyOTP checks a code sent to a phone number and signs in the user with that number,
+// starting a session on their device. When no user has the number yet, one is registered
+// from the profile, which may be nil; the username defaults to the phone number.
+func (s *otpService) VerifyOTP(phone, code string, profile *models.User, device models.DeviceInfo) (*models.Login, error) {
+	phone, err := normalizePhone(phone)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.otpRepo.FindOTPByPhone(phone)
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil || challenge.CodeHash == "" {
+		return nil, ErrInvalidOTP
+	}
+	if !time.Now().Before(challenge.ExpiresAt) {
+		return nil, ErrOTPExpired
+	}
+
+	allowed, err := s.otpRepo.RecordOTPAttempt(challenge.ID, s.config.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrOTPAttemptsExceeded
+	}
+	if !s.signer.DigestEqual(challenge.CodeHash, phone+":"+strings.TrimSpace(code)) {
+		return nil, ErrInvalidOTP
+	}
+
+	// Codes are single-use
+	if err := s.otpRepo.DeleteOTP(challenge.ID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindUserByPhone(phone)
+	if err != nil {
+		return nil, err
+	}
+
+	registered := false
+	if user == nil {
+		if user, err = s.registerPhoneUser(phone, profile); err != nil {
+			return nil, err
+		}
+		registered = true
+	}
+
+	login, err := s.sessionService.StartSession(user, device)
+	if err != nil {
+		return nil, err
+	}
+
+	login.Registered = registered
+	return login, nil
+}
+
+// registerPhoneUser creates an active user identified by a verified phone number. The profile's
+// email address is ignored, as only the phone number was verified; users add an email address by
+// updating their profile, which sends it a verification link.
+func (s *otpService) registerPhoneUser(phone string, profile *models.User) (*models.User, error) {
+	user := &models.User{}
+	if profile != nil {
+		user.Username = strings.TrimSpace(profile.Username)
+		user.FirstName = profile.FirstName
+		user.LastName = profile.LastName
+		user.Location = profile.Location
+		user.Latitude = profile.Latitude
+		user.Longitude = profile.Longitude
+		user.Crops = profile.Crops
+	}
+	if user.Username == "" {
+		user.Username = phone
+	}
+
+	// The phone number was just verified, so the account is active straight away
+	now := time.Now()
+	user.ID = primitive.NewObjectID()
+	user.Phone = phone
+	user.Status = models.UserStatusActive
+	user.VerifiedAt = &now
+
+	if err := s.userService.InsertUser(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
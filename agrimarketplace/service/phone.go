@@ -0,0 +1,41 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+)
+
+// defaultCountryCode is the calling code assumed for phone numbers entered without one.
+const defaultCountryCode = "91"
+
+// e164Pattern matches a phone number in E.164 format: a plus sign and up to 15 digits.
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// normalizePhone converts a phone number as typed by a user to E.164 format.
+// Spaces, dashes, dots and parentheses are ignored, a leading 00 stands for the plus sign,
+// and ten-digit numbers, optionally with a trunk 0, are taken to be in the default country.
+func normalizePhone(phone string) (string, error) {
+	phone = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, phone)
+
+	switch {
+	case strings.HasPrefix(phone, "+"):
+	case strings.HasPrefix(phone, "00"):
+		phone = "+" + phone[2:]
+	case len(phone) == 11 && strings.HasPrefix(phone, "0"):
+		phone = "+" + defaultCountryCode + phone[1:]
+	case len(phone) == 10:
+		phone = "+" + defaultCountryCode + phone
+	}
+
+	if !e164Pattern.MatchString(phone) {
+		return "", ErrInvalidPhone
+	}
+
+	return phone, nil
+}
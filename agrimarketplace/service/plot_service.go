@@ -0,0 +1,233 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"agrimarketplace/validation"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxBoundaryPositions is the number of positions a plot boundary may have over all its rings.
+const maxBoundaryPositions = 2000
+
+// PlotService defines the interface for managing the plots of land held by farmers.
+type PlotService interface {
+	AddPlot(userID string, plot *models.Plot) error
+	GetPlotByID(id string) (*models.Plot, error)
+	GetUserPlots(userID string) ([]models.Plot, error)
+	UpdatePlot(plot *models.Plot) error
+	DeletePlot(id string) error
+	GetCropAreas(userID string) ([]models.CropArea, error)
+}
+
+// plotService is an implementation of the PlotService interface.
+type plotService struct {
+	plotRepo repository.PlotRepository
+	userRepo repository.UserRepository
+}
+
+// NewPlotService creates a new instance of the plotService.
+func NewPlotService(plotRepo repository.PlotRepository, userRepo repository.UserRepository) PlotService {
+	return &plotService{
+		plotRepo: plotRepo,
+		userRepo: userRepo,
+	}
+}
+
+// AddPlot adds a plot to the land held by a user, computing its area from its boundary.
+func (s *plotService) AddPlot(userID string, plot *models.Plot) error {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.IsClosed() {
+		return ErrAccountClosed
+	}
+
+	if err := preparePlot(plot); err != nil {
+		return err
+	}
+
+	// Generate an ObjectID for the plot
+	plot.ID = primitive.NewObjectID()
+	plot.UserID = user.ID
+	plot.CreatedAt = time.Now()
+	plot.UpdatedAt = plot.CreatedAt
+
+	return s.plotRepo.InsertPlot(plot)
+}
+
+// GetPlotByID retrieves a plot by its ID.
+func (s *plotService) GetPlotByID(id string) (*models.Plot, error) {
+	plot, err := s.plotRepo.FindPlotByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if plot == nil {
+		return nil, ErrPlotNotFound
+	}
+
+	return plot, nil
+}
+
+// GetUserPlots retrieves the plots of a user, by name.
+func (s *plotService) GetUserPlots(userID string) ([]models.Plot, error) {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	plots, err := s.plotRepo.FindPlotsByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if plots == nil {
+		plots = []models.Plot{}
+	}
+
+	return plots, nil
+}
+
+// UpdatePlot updates a plot, recomputing its area. Plots stay with the user who added them.
+func (s *plotService) UpdatePlot(plot *models.Plot) error {
+	existingPlot, err := s.plotRepo.FindPlotByID(plot.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if existingPlot == nil {
+		return ErrPlotNotFound
+	}
+
+	if err := preparePlot(plot); err != nil {
+		return err
+	}
+
+	plot.UserID = existingPlot.UserID
+	plot.CreatedAt = existingPlot.CreatedAt
+	plot.UpdatedAt = time.Now()
+
+	return s.plotRepo.UpdatePlot(plot)
+}
+
+// DeletePlot deletes a plot by its ID.
+func (s *plotService) DeletePlot(id string) error {
+	plot, err := s.plotRepo.FindPlotByID(id)
+	if err != nil {
+		return err
+	}
+	if plot == nil {
+		return ErrPlotNotFound
+	}
+
+	return s.plotRepo.DeletePlot(plot.ID)
+}
+
+// GetCropAreas totals the area a user cultivates with each crop, over all their plots.
+func (s *plotService) GetCropAreas(userID string) ([]models.CropArea, error) {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	areas, err := s.plotRepo.FindCropAreasByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if areas == nil {
+		areas = []models.CropArea{}
+	}
+
+	// Sums of rounded areas are rounded again so they read like the plots' own
+	for i := range areas {
+		areas[i].AreaHectares = roundArea(areas[i].AreaHectares)
+		areas[i].AreaAcres = roundArea(areas[i].AreaAcres)
+	}
+
+	return areas, nil
+}
+
+// preparePlot normalizes and validates a plot, then computes its area from its boundary.
+func preparePlot(plot *models.Plot) error {
+	plot.Name = strings.TrimSpace(plot.Name)
+	plot.SoilType = strings.ToLower(strings.TrimSpace(plot.SoilType))
+	plot.IrrigationSource = strings.ToLower(strings.TrimSpace(plot.IrrigationSource))
+	plot.CurrentCrop = normalizeCropName(plot.CurrentCrop)
+
+	errs := validation.Errors{}
+	plotSchema.Check(plot, errs)
+	if message := checkBoundary(plot.Boundary); message != "" {
+		errs.Add("boundary", message)
+	}
+	if err := errs.Err(); err != nil {
+		return err
+	}
+
+	// Holes are not cultivated, so their area is taken off the boundary's
+	areaSqM := ringAreaSqM(plot.Boundary.Coordinates[0])
+	for _, hole := range plot.Boundary.Coordinates[1:] {
+		areaSqM -= ringAreaSqM(hole)
+	}
+	if areaSqM <= 0 {
+		return (validation.Errors{"boundary": "must enclose an area"}).Err()
+	}
+
+	plot.AreaHectares = roundArea(areaSqM / sqMPerHectare)
+	plot.AreaAcres = roundArea(areaSqM / sqMPerAcre)
+	return nil
+}
+
+// checkBoundary returns why a plot boundary is not a valid GeoJSON polygon, or an empty string.
+func checkBoundary(boundary models.GeoPolygon) string {
+	if boundary.Type != "Polygon" {
+		return `must be a GeoJSON geometry of type "Polygon"`
+	}
+	if len(boundary.Coordinates) == 0 {
+		return "must have at least one ring"
+	}
+
+	positions := 0
+	for _, ring := range boundary.Coordinates {
+		if len(ring) < 4 {
+			return "rings must have at least four positions"
+		}
+		positions += len(ring)
+		if positions > maxBoundaryPositions {
+			return "has too many positions"
+		}
+
+		for _, position := range ring {
+			if len(position) != 2 || position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+				return "positions must be [longitude, latitude] pairs"
+			}
+		}
+
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return "rings must be closed"
+		}
+		// The area of a ring crossing itself is that of its lobes cancelling each other out
+		if ringSelfIntersects(ring) {
+			return "rings must not cross themselves"
+		}
+	}
+
+	return ""
+}
+
+// roundArea rounds an area to four decimal places, finer than any land record.
+func roundArea(area float64) float64 {
+	return math.Round(area*10000) / 10000
+}
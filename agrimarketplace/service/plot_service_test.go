@@ -0,0 +1,68 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/validation"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPreparePlotArea(t *testing.T) {
+	outer := rectangle(75.85, 30.9, 200, 100)
+	hole := reversed(rectangle(75.8505, 30.9002, 50, 40))
+
+	tests := []struct {
+		name     string
+		rings    [][][]float64
+		hectares float64
+	}{
+		{"boundary", [][][]float64{outer}, 2},
+		{"wound clockwise", [][][]float64{reversed(outer)}, 2},
+		// Holes are not cultivated
+		{"with a hole", [][][]float64{outer, hole}, 1.8},
+	}
+
+	for _, tt := range tests {
+		plot := models.Plot{Name: " North field ", Boundary: models.GeoPolygon{Type: "Polygon", Coordinates: tt.rings}}
+		if err := preparePlot(&plot); err != nil {
+			t.Errorf("%s: preparePlot() error = %v", tt.name, err)
+			continue
+		}
+		if math.Abs(plot.AreaHectares-tt.hectares) > 0.002 {
+			t.Errorf("%s: preparePlot() area = %v ha, want %v ha", tt.name, plot.AreaHectares, tt.hectares)
+		}
+		if math.Abs(plot.AreaAcres-plot.AreaHectares*sqMPerHectare/sqMPerAcre) > 0.0002 {
+			t.Errorf("%s: preparePlot() area = %v acres, want %v ha in acres", tt.name, plot.AreaAcres, plot.AreaHectares)
+		}
+		if plot.Name != "North field" {
+			t.Errorf("%s: preparePlot() name = %q, want it trimmed", tt.name, plot.Name)
+		}
+	}
+}
+
+func TestPreparePlotRejectsInvalidBoundaries(t *testing.T) {
+	tests := []struct {
+		name     string
+		boundary models.GeoPolygon
+		want     string
+	}{
+		{"point", models.GeoPolygon{Type: "Point"}, `must be a GeoJSON geometry of type "Polygon"`},
+		{"no rings", models.GeoPolygon{Type: "Polygon"}, "must have at least one ring"},
+		{"too few positions", models.GeoPolygon{Type: "Polygon", Coordinates: [][][]float64{{{78, 17}, {78.001, 17}, {78, 17}}}}, "rings must have at least four positions"},
+		{"open", models.GeoPolygon{Type: "Polygon", Coordinates: [][][]float64{{{78, 17}, {78.001, 17}, {78.001, 17.001}, {78, 17.001}}}}, "rings must be closed"},
+		{"latitude first", models.GeoPolygon{Type: "Polygon", Coordinates: [][][]float64{{{17, 78}, {17, 178}, {17.001, 178}, {17, 78}}}}, "positions must be [longitude, latitude] pairs"},
+		{"bowtie", models.GeoPolygon{Type: "Polygon", Coordinates: [][][]float64{{{78, 17}, {78.001, 17.001}, {78.001, 17}, {78, 17.001}, {78, 17}}}}, "rings must not cross themselves"},
+		{"no area", models.GeoPolygon{Type: "Polygon", Coordinates: [][][]float64{{{78, 17}, {78.001, 17}, {78.002, 17}, {78, 17}}}}, "rings must not cross themselves"},
+		// A hole as large as the boundary leaves nothing to cultivate
+		{"filled by a hole", models.GeoPolygon{Type: "Polygon", Coordinates: [][][]float64{rectangle(78, 17, 100, 100), rectangle(78, 17, 100, 100)}}, "must enclose an area"},
+	}
+
+	for _, tt := range tests {
+		plot := models.Plot{Name: "North field", Boundary: tt.boundary}
+		var fieldErr *validation.Error
+		if err := preparePlot(&plot); !errors.As(err, &fieldErr) || fieldErr.Fields["boundary"] != tt.want {
+			t.Errorf("%s: preparePlot() error = %v, want boundary %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PricingConfig holds the platform-wide rules for shop selling prices.
+type PricingConfig struct {
+	// EnforceMRP rejects shop prices above the catalog price, which is treated as the maximum retail price.
+	EnforceMRP bool
+}
+
+// PricingService defines the interface for working with shop-specific selling prices.
+type PricingService interface {
+	SetShopPrice(shopID, productID string, price float64, changedBy primitive.ObjectID) (*models.PriceChange, error)
+	GetPriceHistory(shopID, productID string) ([]models.PriceChange, error)
+}
+
+// pricingService is an implementation of the PricingService interface.
+type pricingService struct {
+	serviceableProductRepo repository.ServiceableProductRepository
+	priceHistoryRepo       repository.PriceHistoryRepository
+	shopRepo               repository.ShopRepository
+	productRepo            repository.ProductRepository
+	licenceService         LicenceService
+	config                 PricingConfig
+}
+
+// NewPricingService creates a new instance of the pricingService.
+func NewPricingService(serviceableProductRepo repository.ServiceableProductRepository, priceHistoryRepo repository.PriceHistoryRepository, shopRepo repository.ShopRepository, productRepo repository.ProductRepository, licenceService LicenceService, config PricingConfig) PricingService {
+	return &pricingService{
+		serviceableProductRepo: serviceableProductRepo,
+		priceHistoryRepo:       priceHistoryRepo,
+		shopRepo:               shopRepo,
+		productRepo:            productRepo,
+		licenceService:         licenceService,
+		config:                 config,
+	}
+}
+
+// SetShopPrice overrides a shop's selling price for a product and records the change.
+func (s *pricingService) SetShopPrice(shopID, productID string, price float64, changedBy primitive.ObjectID) (*models.PriceChange, error) {
+	if price <= 0 {
+		return nil, ErrInvalidPrice
+	}
+
+	shop, product, err := s.findShopProduct(shopID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.EnforceMRP && product.Price > 0 && price > product.Price {
+		return nil, ErrPriceAboveMRP
+	}
+
+	// Pricing lists the product in the shop, which regulated products require a licence for
+	if err := s.licenceService.CheckShopLicence(shop.ID, product); err != nil {
+		return nil, err
+	}
+
+	listing, err := s.serviceableProductRepo.FindServiceableProduct(shop.ID, product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &models.PriceChange{
+		ID:        primitive.NewObjectID(),
+		ShopID:    shop.ID,
+		ProductID: product.ID,
+		NewPrice:  price,
+		ChangedBy: changedBy,
+		ChangedAt: time.Now(),
+	}
+	if listing != nil {
+		change.OldPrice = listing.Price
+	}
+
+	if err := s.serviceableProductRepo.SetServiceableProductPrice(shop.ID, product.ID, price); err != nil {
+		return nil, err
+	}
+	if err := s.priceHistoryRepo.InsertPriceChange(change); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// GetPriceHistory retrieves the price changes of a product in a shop, most recent first.
+func (s *pricingService) GetPriceHistory(shopID, productID string) ([]models.PriceChange, error) {
+	shop, product, err := s.findShopProduct(shopID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.priceHistoryRepo.FindPriceHistory(shop.ID, product.ID)
+}
+
+// findShopProduct loads a shop and a product, checking that both exist.
+func (s *pricingService) findShopProduct(shopID, productID string) (*models.Shop, *models.Product, error) {
+	shop, err := s.shopRepo.FindShopByID(shopID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if shop == nil {
+		return nil, nil, ErrShopNotFound
+	}
+
+	product, err := s.productRepo.FindProductByID(productID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if product == nil {
+		return nil, nil, ErrProductNotFound
+	}
+
+	return shop, product, nil
+}
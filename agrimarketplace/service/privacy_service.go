@@ -0,0 +1,229 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PrivacyService defines the interface for honouring users' requests to access and erase their personal data.
+// Every request is recorded in the privacy audit log, whether or not it succeeds.
+type PrivacyService interface {
+	ExportUserData(userID string, requester *models.User, address string) (*models.UserDataExport, error)
+	WriteUserDataArchive(w io.Writer, export *models.UserDataExport) error
+	CloseAccount(userID string, requester *models.User, address string) error
+	GetAuditLog(userID string) ([]models.PrivacyAuditEntry, error)
+}
+
+// privacyService is an implementation of the PrivacyService interface.
+type privacyService struct {
+	userRepo     repository.UserRepository
+	shopRepo     repository.ShopRepository
+	purchaseRepo repository.PurchaseRepository
+	addressRepo  repository.AddressRepository
+	plotRepo     repository.PlotRepository
+	otpRepo      repository.OTPRepository
+	auditRepo    repository.PrivacyAuditRepository
+}
+
+// NewPrivacyService creates a new instance of the privacyService.
+func NewPrivacyService(userRepo repository.UserRepository, shopRepo repository.ShopRepository, purchaseRepo repository.PurchaseRepository, addressRepo repository.AddressRepository, plotRepo repository.PlotRepository, otpRepo repository.OTPRepository, auditRepo repository.PrivacyAuditRepository) PrivacyService {
+	return &privacyService{
+		userRepo:     userRepo,
+		shopRepo:     shopRepo,
+		purchaseRepo: purchaseRepo,
+		addressRepo:  addressRepo,
+		plotRepo:     plotRepo,
+		otpRepo:      otpRepo,
+		auditRepo:    auditRepo,
+	}
+}
+
+// ExportUserData gathers the personal data kept about a user, with their address book, plots,
+// purchases and shops. The password hash is left out. The requester is the signed-in user who asked
+// for the export from the network address.
+func (s *privacyService) ExportUserData(userID string, requester *models.User, address string) (*models.UserDataExport, error) {
+	export, err := s.exportUserData(userID)
+	s.record(userID, models.PrivacyActionExport, requester, address, err, "")
+	return export, err
+}
+
+// exportUserData gathers the data of ExportUserData.
+func (s *privacyService) exportUserData(userID string) (*models.UserDataExport, error) {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	user.Password = ""
+
+	export := &models.UserDataExport{User: *user, GeneratedAt: time.Now()}
+	if export.Addresses, err = s.addressRepo.FindAddressesByUser(user.ID); err != nil {
+		return nil, err
+	}
+	if export.Plots, err = s.plotRepo.FindPlotsByUser(user.ID); err != nil {
+		return nil, err
+	}
+	if export.Purchases, err = s.purchaseRepo.FindPurchasesByUser(user.ID); err != nil {
+		return nil, err
+	}
+	if export.Shops, err = s.shopRepo.FindShopsByOwner(user.ID); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// WriteUserDataArchive writes an export as a ZIP archive holding a JSON file for each kind of record.
+func (s *privacyService) WriteUserDataArchive(w io.Writer, export *models.UserDataExport) error {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.User},
+		{"addresses.json", emptyIfNil(export.Addresses)},
+		{"plots.json", emptyIfNil(export.Plots)},
+		{"purchases.json", emptyIfNil(export.Purchases)},
+		{"shops.json", emptyIfNil(export.Shops)},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		writer, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.GeneratedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// CloseAccount closes a user's account and anonymizes their personal data. The user's name, email
+// address, phone number, location and crops are cleared, their address book and plots are deleted,
+// and the street address and coordinates are removed from their purchases. The user, their purchases
+// and their shops are kept, as shops and their customers rely on them.
+func (s *privacyService) CloseAccount(userID string, requester *models.User, address string) error {
+	detail, err := s.closeAccount(userID)
+	s.record(userID, models.PrivacyActionClosure, requester, address, err, detail)
+	return err
+}
+
+// closeAccount anonymizes the data of CloseAccount and describes what was changed.
+func (s *privacyService) closeAccount(userID string) (string, error) {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", ErrUserNotFound
+	}
+	if user.IsClosed() {
+		return "", ErrAccountClosed
+	}
+
+	// The user is anonymized last, so that a closure failing part way can be retried
+	addresses, err := s.addressRepo.DeleteAddressesByUser(user.ID)
+	if err != nil {
+		return "", err
+	}
+	plots, err := s.plotRepo.DeletePlotsByUser(user.ID)
+	if err != nil {
+		return "", err
+	}
+	purchases, err := s.purchaseRepo.RedactDeliveryAddresses(user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if user.Phone != "" {
+		challenge, err := s.otpRepo.FindOTPByPhone(user.Phone)
+		if err != nil {
+			return "", err
+		}
+		if challenge != nil {
+			if err := s.otpRepo.DeleteOTP(challenge.ID); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if err := s.userRepo.AnonymizeUser(user.ID, "closed-"+user.ID.Hex(), time.Now()); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("anonymized profile, deleted %d addresses and %d plots, redacted %d delivery addresses", addresses, plots, purchases), nil
+}
+
+// GetAuditLog retrieves the actions taken on a user's personal data, oldest first.
+func (s *privacyService) GetAuditLog(userID string) ([]models.PrivacyAuditEntry, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	entries, err := s.auditRepo.FindPrivacyAuditEntriesByUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []models.PrivacyAuditEntry{}
+	}
+
+	return entries, nil
+}
+
+// record adds an action to the privacy audit log and the server log.
+// The log is kept even for users who were not found, as the request itself is worth recording.
+func (s *privacyService) record(userID, action string, requester *models.User, address string, actionErr error, detail string) {
+	log.Printf("Privacy %s of user %s requested by %s from %s: error=%v %s", action, userID, requester.ID.Hex(), address, actionErr, detail)
+
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return
+	}
+
+	entry := &models.PrivacyAuditEntry{
+		ID:          primitive.NewObjectID(),
+		UserID:      id,
+		Action:      action,
+		RequesterID: requester.ID,
+		Requester:   address,
+		Succeeded:   actionErr == nil,
+		Detail:      detail,
+		At:          time.Now(),
+	}
+	if actionErr != nil {
+		entry.Detail = actionErr.Error()
+	}
+
+	if err := s.auditRepo.InsertPrivacyAuditEntry(entry); err != nil {
+		log.Printf("Error recording privacy %s of user %s: %v", action, userID, err)
+	}
+}
+
+// emptyIfNil returns an empty slice in place of nil, so that it is exported as an empty list.
+func emptyIfNil[T any](records []T) []T {
+	if records == nil {
+		return []T{}
+	}
+	return records
+}
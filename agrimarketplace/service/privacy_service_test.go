@@ -0,0 +1,175 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// closureFixture holds a farmer with personal data in every repository touched by account closure,
+// and another user whose data must be left alone.
+type closureFixture struct {
+	farmer, neighbour *models.User
+	users             *fakeUserRepository
+	addresses         *fakeAddressRepository
+	plots             *fakePlotRepository
+	purchases         *fakePurchaseRepository
+	otps              *fakeOTPRepository
+	audit             *fakePrivacyAuditRepository
+	privacy           PrivacyService
+}
+
+func newClosureFixture() *closureFixture {
+	f := &closureFixture{
+		farmer: &models.User{
+			ID: primitive.NewObjectID(), Username: "ravi", Password: "hash", Email: "ravi@example.com", Phone: "+919876543210",
+			FirstName: "Ravi", LastName: "Kumar", Location: "Mandya", Latitude: 12.52, Longitude: 76.9,
+			Crops: []models.CropPlanting{{Crop: "paddy"}}, Status: models.UserStatusActive,
+		},
+		neighbour: &models.User{ID: primitive.NewObjectID(), Username: "meena", Email: "meena@example.com", Status: models.UserStatusActive},
+	}
+	snapshot := func() *models.AddressSnapshot {
+		return &models.AddressSnapshot{Label: "Home", PostalAddress: models.PostalAddress{
+			Line1: "12 Temple Road", Line2: "Near the tank", Locality: "Mandya", District: "Mandya", State: "Karnataka",
+			PostalCode: "571401", Landmark: "Opposite the school", Latitude: 12.52, Longitude: 76.9,
+		}}
+	}
+
+	f.users = newFakeUserRepository(f.farmer, f.neighbour)
+	f.addresses = &fakeAddressRepository{addresses: []models.Address{
+		{ID: primitive.NewObjectID(), UserID: f.farmer.ID, Label: "Home"},
+		{ID: primitive.NewObjectID(), UserID: f.farmer.ID, Label: "Field"},
+		{ID: primitive.NewObjectID(), UserID: f.neighbour.ID, Label: "Home"},
+	}}
+	f.plots = &fakePlotRepository{plots: []models.Plot{
+		{ID: primitive.NewObjectID(), UserID: f.farmer.ID, Name: "North field"},
+		{ID: primitive.NewObjectID(), UserID: f.neighbour.ID, Name: "Orchard"},
+	}}
+	f.purchases = &fakePurchaseRepository{purchases: []models.Purchase{
+		{ID: primitive.NewObjectID(), UserID: f.farmer.ID, DeliveryAddress: snapshot(), Total: 540},
+		{ID: primitive.NewObjectID(), UserID: f.farmer.ID, Total: 120}, // Placed without an address
+		{ID: primitive.NewObjectID(), UserID: f.neighbour.ID, DeliveryAddress: snapshot(), Total: 80},
+	}}
+	f.otps = &fakeOTPRepository{challenges: []models.OTPChallenge{{ID: primitive.NewObjectID(), Phone: f.farmer.Phone}}}
+	f.audit = &fakePrivacyAuditRepository{}
+	f.privacy = NewPrivacyService(f.users, nil, f.purchases, f.addresses, f.plots, f.otps, f.audit)
+	return f
+}
+
+func TestCloseAccount(t *testing.T) {
+	f := newClosureFixture()
+
+	if err := f.privacy.CloseAccount(f.farmer.ID.Hex(), f.farmer, "203.0.113.7"); err != nil {
+		t.Fatalf("CloseAccount() error = %v", err)
+	}
+
+	closed := f.users.users[f.farmer.ID]
+	if closed.Status != models.UserStatusClosed || closed.ClosedAt == nil || closed.Username != "closed-"+f.farmer.ID.Hex() {
+		t.Errorf("CloseAccount() left user %+v, want them closed under a placeholder username", closed)
+	}
+	if closed.Password != "" || closed.Email != "" || closed.Phone != "" || closed.FirstName != "" || closed.LastName != "" ||
+		closed.Location != "" || closed.Latitude != 0 || closed.Longitude != 0 || closed.Crops != nil {
+		t.Errorf("CloseAccount() left personal data on user %+v", closed)
+	}
+	if closed.TokenVersion == 0 {
+		t.Error("CloseAccount() kept the user's tokens valid")
+	}
+
+	for _, address := range f.addresses.addresses {
+		if address.UserID == f.farmer.ID {
+			t.Errorf("CloseAccount() kept address %q", address.Label)
+		}
+	}
+	for _, plot := range f.plots.plots {
+		if plot.UserID == f.farmer.ID {
+			t.Errorf("CloseAccount() kept plot %q", plot.Name)
+		}
+	}
+	if len(f.addresses.addresses) != 1 || len(f.plots.plots) != 1 {
+		t.Errorf("CloseAccount() left %d addresses and %d plots, want only the neighbour's", len(f.addresses.addresses), len(f.plots.plots))
+	}
+	if len(f.otps.challenges) != 0 {
+		t.Errorf("CloseAccount() kept the one-time password of the user's phone")
+	}
+
+	// Purchases are kept for the shops, with only the part of the address their records need
+	if len(f.purchases.purchases) != 3 {
+		t.Fatalf("CloseAccount() left %d purchases, want all 3", len(f.purchases.purchases))
+	}
+	redacted := f.purchases.purchases[0].DeliveryAddress
+	if redacted.Line1 != "" || redacted.Line2 != "" || redacted.Landmark != "" || redacted.Latitude != 0 || redacted.Longitude != 0 {
+		t.Errorf("CloseAccount() left the street address or coordinates on purchase: %+v", *redacted)
+	}
+	if redacted.Locality != "Mandya" || redacted.District != "Mandya" || redacted.State != "Karnataka" || redacted.PostalCode != "571401" {
+		t.Errorf("CloseAccount() removed the locality or PIN code from purchase: %+v", *redacted)
+	}
+	if kept := f.purchases.purchases[2].DeliveryAddress; kept.Line1 == "" || kept.Latitude == 0 {
+		t.Errorf("CloseAccount() redacted the neighbour's purchase: %+v", *kept)
+	}
+
+	if neighbour := f.users.users[f.neighbour.ID]; neighbour.Email != "meena@example.com" || neighbour.IsClosed() {
+		t.Errorf("CloseAccount() changed the neighbour: %+v", neighbour)
+	}
+
+	if len(f.audit.entries) != 1 {
+		t.Fatalf("CloseAccount() recorded %d audit entries, want 1", len(f.audit.entries))
+	}
+	entry := f.audit.entries[0]
+	want := "anonymized profile, deleted 2 addresses and 1 plots, redacted 1 delivery addresses"
+	if entry.Action != models.PrivacyActionClosure || !entry.Succeeded || entry.UserID != f.farmer.ID ||
+		entry.RequesterID != f.farmer.ID || entry.Requester != "203.0.113.7" || entry.Detail != want {
+		t.Errorf("CloseAccount() recorded %+v, want a successful closure detailing %q", entry, want)
+	}
+
+	// Closing the account again is refused, and the refusal is recorded too
+	if err := f.privacy.CloseAccount(f.farmer.ID.Hex(), f.farmer, "203.0.113.7"); !errors.Is(err, ErrAccountClosed) {
+		t.Errorf("CloseAccount() of a closed account error = %v, want %v", err, ErrAccountClosed)
+	}
+	if len(f.audit.entries) != 2 || f.audit.entries[1].Succeeded {
+		t.Errorf("CloseAccount() of a closed account recorded %+v, want a failed closure", f.audit.entries)
+	}
+}
+
+func TestCloseAccountFailingPartWayCanBeRetried(t *testing.T) {
+	f := newClosureFixture()
+	f.plots.err = errors.New("connection reset")
+
+	if err := f.privacy.CloseAccount(f.farmer.ID.Hex(), f.farmer, "203.0.113.7"); err == nil {
+		t.Fatal("CloseAccount() error = nil, want the plot repository's error")
+	}
+	// The user is anonymized last, so they can still ask again
+	if user := f.users.users[f.farmer.ID]; user.IsClosed() || user.Email == "" {
+		t.Fatalf("CloseAccount() anonymized the user although their plots were kept: %+v", user)
+	}
+	if len(f.audit.entries) != 1 || f.audit.entries[0].Succeeded || f.audit.entries[0].Detail != "connection reset" {
+		t.Errorf("CloseAccount() recorded %+v, want a failed closure with its reason", f.audit.entries)
+	}
+
+	f.plots.err = nil
+	if err := f.privacy.CloseAccount(f.farmer.ID.Hex(), f.farmer, "203.0.113.7"); err != nil {
+		t.Fatalf("CloseAccount() retry error = %v", err)
+	}
+	if !f.users.users[f.farmer.ID].IsClosed() || len(f.plots.plots) != 1 {
+		t.Errorf("CloseAccount() retry left the user open or their plots in place")
+	}
+	// Addresses deleted by the failed attempt are not counted again
+	if detail := f.audit.entries[1].Detail; detail != "anonymized profile, deleted 0 addresses and 1 plots, redacted 1 delivery addresses" {
+		t.Errorf("CloseAccount() retry recorded %q", detail)
+	}
+}
+
+func TestCloseAccountOfUnknownUser(t *testing.T) {
+	f := newClosureFixture()
+
+	if err := f.privacy.CloseAccount(primitive.NewObjectID().Hex(), f.farmer, "203.0.113.7"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("CloseAccount() of an unknown user error = %v, want %v", err, ErrUserNotFound)
+	}
+	if len(f.addresses.addresses) != 3 || len(f.plots.plots) != 2 {
+		t.Error("CloseAccount() of an unknown user deleted records")
+	}
+	if len(f.audit.entries) != 1 || f.audit.entries[0].Succeeded {
+		t.Errorf("CloseAccount() of an unknown user recorded %+v, want a failed closure", f.audit.entries)
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"encoding/base64"
+	"encoding/json"
+)
+
+// encodeProductCursor returns the opaque cursor pointing after a product in the given sort order.
+func encodeProductCursor(product models.Product, sort string) string {
+	cursor := models.ProductCursor{Sort: sort, ID: product.ID}
+	switch sort {
+	case models.ProductSortPriceAsc, models.ProductSortPriceDesc:
+		cursor.Number = product.Price
+	case models.ProductSortPopularity:
+		cursor.Number = float64(product.Popularity)
+	case models.ProductSortNewest:
+		cursor.Time = product.CreatedAt
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeProductCursor parses an opaque cursor and checks that it was issued for the given sort order.
+func decodeProductCursor(value string, sort string) (*models.ProductCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor models.ProductCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != sort || cursor.ID.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestProductCursorRoundTrip(t *testing.T) {
+	product := models.Product{
+		ID:         primitive.NewObjectID(),
+		Price:      450,
+		Popularity: 12,
+		CreatedAt:  time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		sort string
+		want models.ProductCursor
+	}{
+		{models.ProductSortPriceAsc, models.ProductCursor{Sort: models.ProductSortPriceAsc, ID: product.ID, Number: 450}},
+		{models.ProductSortPriceDesc, models.ProductCursor{Sort: models.ProductSortPriceDesc, ID: product.ID, Number: 450}},
+		{models.ProductSortPopularity, models.ProductCursor{Sort: models.ProductSortPopularity, ID: product.ID, Number: 12}},
+		{models.ProductSortNewest, models.ProductCursor{Sort: models.ProductSortNewest, ID: product.ID, Time: product.CreatedAt}},
+	}
+
+	for _, tt := range tests {
+		got, err := decodeProductCursor(encodeProductCursor(product, tt.sort), tt.sort)
+		if err != nil {
+			t.Errorf("decodeProductCursor() for %s error = %v", tt.sort, err)
+			continue
+		}
+		if got.Sort != tt.want.Sort || got.ID != tt.want.ID || got.Number != tt.want.Number || !got.Time.Equal(tt.want.Time) {
+			t.Errorf("decodeProductCursor() for %s = %+v, want %+v", tt.sort, got, tt.want)
+		}
+	}
+}
+
+func TestListProductsRejectsCursorOfAnotherSort(t *testing.T) {
+	product := models.Product{ID: primitive.NewObjectID(), Price: 450, Popularity: 12}
+	repo := &fakeProductRepository{}
+	products := NewProductService(repo, nil, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   string
+	}{
+		{"price cursor sorted by popularity", encodeProductCursor(product, models.ProductSortPriceAsc), models.ProductSortPopularity},
+		{"ascending price cursor sorted by descending price", encodeProductCursor(product, models.ProductSortPriceAsc), models.ProductSortPriceDesc},
+		// The default sort order is popularity
+		{"newest cursor without a sort", encodeProductCursor(product, models.ProductSortNewest), ""},
+		{"malformed cursor", "not a cursor", models.ProductSortPopularity},
+	}
+
+	for _, tt := range tests {
+		if _, err := products.ListProducts(models.ProductQuery{Sort: tt.sort, Cursor: tt.cursor}); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: ListProducts() error = %v, want %v", tt.name, err, ErrInvalidCursor)
+		}
+	}
+	if len(repo.queries) != 0 {
+		t.Errorf("ListProducts() queried the repository %d times, want none", len(repo.queries))
+	}
+
+	// The same cursor is accepted with the sort order it was issued for
+	cursor := encodeProductCursor(product, models.ProductSortPopularity)
+	if _, err := products.ListProducts(models.ProductQuery{Cursor: cursor}); err != nil {
+		t.Fatalf("ListProducts() error = %v", err)
+	}
+	if len(repo.queries) != 1 || repo.queries[0].After == nil || repo.queries[0].After.ID != product.ID || repo.queries[0].After.Number != 12 {
+		t.Errorf("ListProducts() queried %+v, want a query after the cursor's product", repo.queries)
+	}
+}
@@ -3,26 +3,66 @@ package service
 import (
 	"agrimarketplace/models"
 	"agrimarketplace/repository"
+	"agrimarketplace/search"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// defaultSearchLimit is the number of search results returned when no limit is requested.
+	defaultSearchLimit = 20
+	// defaultPageSize is the number of catalog products returned when no limit is requested.
+	defaultPageSize = 20
+	// maxPageSize is the largest number of catalog products returned in a single page.
+	maxPageSize = 100
 )
 
 // ProductService defines the interface for working with products.
 type ProductService interface {
 	GetProductByID(id string) (*models.Product, error)
 	GetProductsByCategory(categoryID string) ([]models.Product, error)
+	ListProducts(query models.ProductQuery) (*models.ProductPage, error)
+	GetVariantUnitPrices(productID string, unit string) ([]models.VariantUnitPrice, error)
+	SearchProducts(text string, limit int) ([]models.ProductSearchResult, error)
+	ValidateProduct(product *models.Product) error
 	CreateProduct(product *models.Product) error
 	UpdateProduct(product *models.Product) error
 	DeleteProduct(id string) error
+	SetProductStatus(id string, status string) (*models.Product, error)
+	RestoreProduct(id string) (*models.Product, error)
+	PurgeDeletedProducts(deletedBefore time.Time) (int, error)
 }
 
 // productService is an implementation of the ProductService interface.
 type productService struct {
-	productRepo repository.ProductRepository
+	productRepo            repository.ProductRepository
+	categoryRepo           repository.CategoryRepository
+	shopRepo               repository.ShopRepository
+	serviceableProductRepo repository.ServiceableProductRepository
+	mediaService           MediaService
+	licenceService         LicenceService
+	synonyms               search.Synonyms
+
+	// searchIndex is the in-memory full-text index used when the repository has none.
+	// It is built from the catalog on the first search and kept up to date on writes.
+	searchIndexMu sync.Mutex
+	searchIndex   *search.Index
 }
 
 // NewProductService creates a new instance of the productService.
-func NewProductService(productRepo repository.ProductRepository) ProductService {
+func NewProductService(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, shopRepo repository.ShopRepository, serviceableProductRepo repository.ServiceableProductRepository, mediaService MediaService, licenceService LicenceService, synonyms search.Synonyms) ProductService {
 	return &productService{
-		productRepo: productRepo,
+		productRepo:            productRepo,
+		categoryRepo:           categoryRepo,
+		shopRepo:               shopRepo,
+		serviceableProductRepo: serviceableProductRepo,
+		mediaService:           mediaService,
+		licenceService:         licenceService,
+		synonyms:               synonyms,
 	}
 }
 
@@ -36,17 +76,365 @@ func (s *productService) GetProductsByCategory(categoryID string) ([]models.Prod
 	return s.productRepo.FindProductsByCategory(categoryID)
 }
 
+// GetVariantUnitPrices returns the price of each variant of a product per unit, cheapest first.
+func (s *productService) GetVariantUnitPrices(productID string, unit string) ([]models.VariantUnitPrice, error) {
+	product, err := s.productRepo.FindProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	prices, err := variantUnitPrices(product.Variants, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(prices, func(i, j int) bool { return prices[i].UnitPrice < prices[j].UnitPrice })
+	return prices, nil
+}
+
+// ListProducts lists a page of the catalog matching the query's filters, in the requested order.
+func (s *productService) ListProducts(query models.ProductQuery) (*models.ProductPage, error) {
+	if query.Sort == "" {
+		query.Sort = models.ProductSortPopularity
+	}
+	switch query.Sort {
+	case models.ProductSortPriceAsc, models.ProductSortPriceDesc, models.ProductSortPopularity, models.ProductSortNewest:
+	default:
+		return nil, ErrInvalidSortKey
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = defaultPageSize
+	}
+	if query.Limit > maxPageSize {
+		query.Limit = maxPageSize
+	}
+	if (query.MinPrice != nil && *query.MinPrice < 0) || (query.MaxPrice != nil && *query.MaxPrice < 0) ||
+		(query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice) ||
+		(query.MinRating != nil && (*query.MinRating < 0 || *query.MinRating > 5)) {
+		return nil, ErrInvalidProductQuery
+	}
+
+	for _, status := range query.Statuses {
+		if _, ok := productStatusTransitions[status]; !ok {
+			return nil, ErrInvalidProductQuery
+		}
+	}
+
+	for name := range query.Attributes {
+		if !attributeNamePattern.MatchString(name) {
+			return nil, ErrInvalidProductQuery
+		}
+	}
+	for _, bounds := range []map[string]float64{query.AttributeMin, query.AttributeMax} {
+		for name := range bounds {
+			if !attributeNamePattern.MatchString(name) {
+				return nil, ErrInvalidProductQuery
+			}
+		}
+	}
+
+	if query.Cursor != "" {
+		after, err := decodeProductCursor(query.Cursor, query.Sort)
+		if err != nil {
+			return nil, err
+		}
+		query.After = after
+	}
+
+	if query.InStockNear != nil {
+		productIDs, err := productsInStockNear(s.shopRepo, s.serviceableProductRepo, s.licenceService, *query.InStockNear)
+		if err != nil {
+			return nil, err
+		}
+		query.ProductIDs = productIDs
+	}
+
+	page, err := s.productRepo.FindProducts(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.HasMore && len(page.Products) > 0 {
+		page.NextCursor = encodeProductCursor(page.Products[len(page.Products)-1], query.Sort)
+	}
+
+	return page, nil
+}
+
+// SearchProducts finds the products whose name or description match the text, most relevant first.
+// Terms are matched with typo tolerance and expanded with the configured synonyms.
+func (s *productService) SearchProducts(text string, limit int) ([]models.ProductSearchResult, error) {
+	query := search.ParseQuery(text, s.synonyms)
+	if query.Empty() {
+		return nil, ErrEmptySearchQuery
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	if searcher, ok := s.productRepo.(repository.ProductTextSearcher); ok {
+		return searcher.SearchProducts(query, limit)
+	}
+
+	index, err := s.localSearchIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.ProductSearchResult
+	for _, match := range index.Search(query, limit) {
+		product, err := s.productRepo.FindProductByID(match.ID)
+		if err != nil {
+			return nil, err
+		}
+		if product == nil || !product.IsListed() {
+			continue // Deleted or unlisted by another instance since the index was built
+		}
+		results = append(results, models.ProductSearchResult{Product: *product, Score: match.Score})
+	}
+
+	return results, nil
+}
+
+// ValidateProduct checks a product as CreateProduct and UpdateProduct would, without saving it.
+func (s *productService) ValidateProduct(product *models.Product) error {
+	var existingProduct *models.Product
+	if !product.ID.IsZero() {
+		var err error
+		existingProduct, err = s.productRepo.FindProductByID(product.ID.Hex())
+		if err != nil {
+			return err
+		}
+		if existingProduct == nil {
+			return ErrProductNotFound
+		}
+	}
+
+	if err := applyProductStatus(product, existingProduct); err != nil {
+		return err
+	}
+	return s.validateProduct(product)
+}
+
 // CreateProduct creates a new product.
 func (s *productService) CreateProduct(product *models.Product) error {
-	return s.productRepo.InsertProduct(product)
+	if err := applyProductStatus(product, nil); err != nil {
+		return err
+	}
+	if err := s.validateProduct(product); err != nil {
+		return err
+	}
+
+	// Generate an ObjectID for the product
+	product.ID = primitive.NewObjectID()
+	product.CreatedAt = time.Now()
+	product.DeletedAt = nil
+
+	if err := s.productRepo.InsertProduct(product); err != nil {
+		return err
+	}
+
+	s.indexProduct(product)
+	return nil
 }
 
 // UpdateProduct updates an existing product.
 func (s *productService) UpdateProduct(product *models.Product) error {
-	return s.productRepo.UpdateProduct(product)
+	existingProduct, err := s.productRepo.FindProductByID(product.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if existingProduct == nil {
+		return ErrProductNotFound
+	}
+
+	// The creation time is managed by the service and keeps the catalog order stable
+	product.CreatedAt = existingProduct.CreatedAt
+	product.DeletedAt = nil
+
+	if err := applyProductStatus(product, existingProduct); err != nil {
+		return err
+	}
+	if err := s.validateProduct(product); err != nil {
+		return err
+	}
+
+	if err := s.productRepo.UpdateProduct(product); err != nil {
+		return err
+	}
+
+	s.indexProduct(product)
+	return nil
 }
 
-// DeleteProduct deletes a product by its ID.
+// DeleteProduct soft-deletes a product by its ID.
+// The product stays referenced by past orders and can be restored until it is purged.
 func (s *productService) DeleteProduct(id string) error {
-	return s.productRepo.DeleteProduct(id)
+	existingProduct, err := s.productRepo.FindProductByID(id)
+	if err != nil {
+		return err
+	}
+	if existingProduct == nil {
+		return ErrProductNotFound
+	}
+
+	if err := s.productRepo.DeleteProduct(id); err != nil {
+		return err
+	}
+
+	s.unindexProduct(existingProduct.ID)
+	return nil
+}
+
+// SetProductStatus moves a product to another lifecycle state.
+func (s *productService) SetProductStatus(id string, status string) (*models.Product, error) {
+	product, err := s.productRepo.FindProductByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	existingProduct := *product
+	product.Status = status
+	if err := applyProductStatus(product, &existingProduct); err != nil {
+		return nil, err
+	}
+
+	if err := s.productRepo.UpdateProduct(product); err != nil {
+		return nil, err
+	}
+
+	s.indexProduct(product)
+	return product, nil
+}
+
+// RestoreProduct undoes the soft deletion of a product.
+func (s *productService) RestoreProduct(id string) (*models.Product, error) {
+	product, err := s.productRepo.FindDeletedProductByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	if err := s.productRepo.RestoreProduct(id); err != nil {
+		return nil, err
+	}
+	product.DeletedAt = nil
+
+	s.indexProduct(product)
+	return product, nil
+}
+
+// PurgeDeletedProducts permanently removes the products soft-deleted before the given time, with their images.
+// It returns the number of products removed.
+func (s *productService) PurgeDeletedProducts(deletedBefore time.Time) (int, error) {
+	productIDs, err := s.productRepo.PurgeDeletedProducts(deletedBefore)
+
+	// Images left behind on failure are removed by the orphaned image cleanup
+	for _, productID := range productIDs {
+		if err := s.mediaService.DeleteImagesByOwner(models.ImageOwnerProduct, productID); err != nil {
+			log.Printf("Error deleting images of product %s: %v", productID.Hex(), err)
+		}
+	}
+
+	return len(productIDs), err
+}
+
+// validateProduct checks the product's variants and its attributes against the schema of its category.
+func (s *productService) validateProduct(product *models.Product) error {
+	if err := productSchema.Validate(product); err != nil {
+		return err
+	}
+	if err := validateVariants(product.Variants); err != nil {
+		return err
+	}
+	if product.RequiredLicence != "" && !validLicenceType(product.RequiredLicence) {
+		return ErrInvalidLicenceType
+	}
+
+	// Products sold only in packs are listed in the catalog from their cheapest pack
+	if product.Price == 0 {
+		for i, variant := range product.Variants {
+			if i == 0 || variant.Price < product.Price {
+				product.Price = variant.Price
+			}
+		}
+	}
+
+	if product.CategoryID.IsZero() {
+		if len(product.Attributes) > 0 {
+			return &AttributeValidationError{Fields: map[string]string{"category_id": "is required for products with attributes"}}
+		}
+		return nil
+	}
+
+	category, err := s.categoryRepo.FindCategoryByID(product.CategoryID.Hex())
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return ErrCategoryNotFound
+	}
+
+	if product.Attributes == nil {
+		product.Attributes = map[string]interface{}{}
+	}
+	return validateAttributes(category.Attributes, product.Attributes)
+}
+
+// localSearchIndex returns the in-memory search index, building it from the catalog on first use.
+func (s *productService) localSearchIndex() (*search.Index, error) {
+	s.searchIndexMu.Lock()
+	defer s.searchIndexMu.Unlock()
+
+	if s.searchIndex != nil {
+		return s.searchIndex, nil
+	}
+
+	products, err := s.productRepo.FindAllProducts()
+	if err != nil {
+		return nil, err
+	}
+
+	index := search.NewIndex()
+	for _, product := range products {
+		if product.IsListed() {
+			index.Add(repository.ProductDocument(product))
+		}
+	}
+	s.searchIndex = index
+
+	return index, nil
+}
+
+// indexProduct refreshes a product in the in-memory search index once it has been built.
+// Products that are not listed in the catalog are removed from it.
+func (s *productService) indexProduct(product *models.Product) {
+	if !product.IsListed() {
+		s.unindexProduct(product.ID)
+		return
+	}
+
+	s.searchIndexMu.Lock()
+	defer s.searchIndexMu.Unlock()
+	if s.searchIndex != nil {
+		s.searchIndex.Add(repository.ProductDocument(*product))
+	}
+}
+
+// unindexProduct removes a product from the in-memory search index once it has been built.
+func (s *productService) unindexProduct(productID primitive.ObjectID) {
+	s.searchIndexMu.Lock()
+	defer s.searchIndexMu.Unlock()
+	if s.searchIndex != nil {
+		s.searchIndex.Remove(productID.Hex())
+	}
 }
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PurchaseService defines the interface for placing and retrieving purchases.
+type PurchaseService interface {
+	PlacePurchase(purchase *models.Purchase) error
+	GetPurchaseByID(id string) (*models.Purchase, error)
+	GetUserPurchases(userID string) ([]models.Purchase, error)
+}
+
+// purchaseService is an implementation of the PurchaseService interface.
+type purchaseService struct {
+	purchaseRepo           repository.PurchaseRepository
+	userRepo               repository.UserRepository
+	addressRepo            repository.AddressRepository
+	shopRepo               repository.ShopRepository
+	productRepo            repository.ProductRepository
+	serviceableProductRepo repository.ServiceableProductRepository
+	licenceService         LicenceService
+	inventoryService       InventoryService
+	recommendationService  RecommendationService
+}
+
+// NewPurchaseService creates a new instance of the purchaseService.
+func NewPurchaseService(purchaseRepo repository.PurchaseRepository, userRepo repository.UserRepository, addressRepo repository.AddressRepository, shopRepo repository.ShopRepository, productRepo repository.ProductRepository, serviceableProductRepo repository.ServiceableProductRepository, licenceService LicenceService, inventoryService InventoryService, recommendationService RecommendationService) PurchaseService {
+	return &purchaseService{
+		purchaseRepo:           purchaseRepo,
+		userRepo:               userRepo,
+		addressRepo:            addressRepo,
+		shopRepo:               shopRepo,
+		productRepo:            productRepo,
+		serviceableProductRepo: serviceableProductRepo,
+		licenceService:         licenceService,
+		inventoryService:       inventoryService,
+		recommendationService:  recommendationService,
+	}
+}
+
+// PlacePurchase records a user's purchase from a shop. Each line is priced at the shop's current
+// selling price and its quantity is taken out of the shop's stock; if any line cannot be served,
+// the stock already taken is returned and nothing is recorded. The delivery address is copied
+// into the purchase, so later edits to the address book leave it unchanged.
+func (s *purchaseService) PlacePurchase(purchase *models.Purchase) error {
+	lines, err := mergePurchaseLines(purchase.Lines)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindUserByID(purchase.UserID.Hex())
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.IsClosed() {
+		return ErrAccountClosed
+	}
+	if !user.IsVerified() {
+		return ErrUserNotVerified
+	}
+
+	deliveryAddress, err := s.deliveryAddress(user.ID, purchase.AddressID)
+	if err != nil {
+		return err
+	}
+
+	shop, err := s.shopRepo.FindShopByID(purchase.ShopID.Hex())
+	if err != nil {
+		return err
+	}
+	if shop == nil {
+		return ErrShopNotFound
+	}
+
+	var allocated []models.PurchaseLine
+	release := func() {
+		for _, line := range allocated {
+			if err := s.inventoryService.ReleaseStock(shop.ID, line.ProductID, line.Quantity, line.Allocations); err != nil {
+				log.Printf("Error returning stock of product %s to shop %s: %v", line.ProductID.Hex(), shop.ID.Hex(), err)
+			}
+		}
+	}
+
+	total := 0.0
+	for _, line := range lines {
+		priced, err := s.priceLine(shop.ID, line)
+		if err != nil {
+			release()
+			return err
+		}
+
+		priced.Allocations, err = s.inventoryService.AllocateStock(shop.ID, priced.ProductID, priced.Quantity)
+		if err != nil {
+			release()
+			return err
+		}
+
+		allocated = append(allocated, priced)
+		total += priced.UnitPrice * float64(priced.Quantity)
+	}
+
+	// Generate an ObjectID for the purchase
+	purchase.ID = primitive.NewObjectID()
+	purchase.UserID = user.ID
+	purchase.ShopID = shop.ID
+	purchase.DeliveryAddress = deliveryAddress
+	purchase.Lines = allocated
+	purchase.Total = total
+	purchase.PurchasedAt = time.Now()
+
+	if err := s.purchaseRepo.InsertPurchase(purchase); err != nil {
+		release()
+		return err
+	}
+
+	// Recommendations catch up on the next rebuild if the incremental update fails
+	if err := s.recommendationService.RecordPurchase(purchase); err != nil {
+		log.Printf("Error recording purchase %s for recommendations: %v", purchase.ID.Hex(), err)
+	}
+
+	return nil
+}
+
+// GetPurchaseByID retrieves a purchase by its ID.
+func (s *purchaseService) GetPurchaseByID(id string) (*models.Purchase, error) {
+	purchase, err := s.purchaseRepo.FindPurchaseByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if purchase == nil {
+		return nil, ErrPurchaseNotFound
+	}
+
+	return purchase, nil
+}
+
+// GetUserPurchases retrieves the purchases of a user, most recent first.
+func (s *purchaseService) GetUserPurchases(userID string) ([]models.Purchase, error) {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return s.purchaseRepo.FindPurchasesByUser(user.ID)
+}
+
+// deliveryAddress snapshots the address a purchase is delivered to: the address chosen from the
+// user's address book, or else their default address. It returns nil when the user has no address.
+func (s *purchaseService) deliveryAddress(userID, addressID primitive.ObjectID) (*models.AddressSnapshot, error) {
+	var address *models.Address
+	var err error
+	if addressID.IsZero() {
+		address, err = s.addressRepo.FindDefaultAddress(userID)
+	} else {
+		address, err = s.addressRepo.FindAddressByID(addressID.Hex())
+		if err == nil && (address == nil || address.UserID != userID) {
+			return nil, ErrAddressNotFound
+		}
+	}
+	if err != nil || address == nil {
+		return nil, err
+	}
+
+	return addressSnapshot(address), nil
+}
+
+// priceLine checks that the shop may sell the product of a line and sets its unit price.
+func (s *purchaseService) priceLine(shopID primitive.ObjectID, line models.PurchaseLine) (models.PurchaseLine, error) {
+	product, err := s.productRepo.FindProductByID(line.ProductID.Hex())
+	if err != nil {
+		return line, err
+	}
+	if product == nil || !product.IsListed() {
+		return line, ErrProductNotFound
+	}
+
+	if err := s.licenceService.CheckShopLicence(shopID, product); err != nil {
+		return line, err
+	}
+
+	listing, err := s.serviceableProductRepo.FindServiceableProduct(shopID, product.ID)
+	if err != nil {
+		return line, err
+	}
+	if listing == nil || !listing.IsServiceable {
+		return line, ErrNotServiceable
+	}
+
+	line.ProductID = product.ID
+	line.UnitPrice = listing.EffectivePrice(product.Price)
+	line.Allocations = nil
+	return line, nil
+}
+
+// mergePurchaseLines combines lines for the same product, keeping the order in which products first appear.
+func mergePurchaseLines(lines []models.PurchaseLine) ([]models.PurchaseLine, error) {
+	if len(lines) == 0 {
+		return nil, ErrInvalidPurchase
+	}
+
+	index := map[primitive.ObjectID]int{}
+	var merged []models.PurchaseLine
+	for _, line := range lines {
+		if line.ProductID.IsZero() || line.Quantity <= 0 {
+			return nil, ErrInvalidPurchase
+		}
+		if i, ok := index[line.ProductID]; ok {
+			merged[i].Quantity += line.Quantity
+			continue
+		}
+		index[line.ProductID] = len(merged)
+		merged = append(merged, models.PurchaseLine{ProductID: line.ProductID, Quantity: line.Quantity})
+	}
+
+	return merged, nil
+}
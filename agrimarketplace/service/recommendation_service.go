@@ -0,0 +1,213 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"math"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// minTimesBoughtTogether is the number of purchases a pair of products must share before
+	// one is recommended with the other, so that a single basket does not drive recommendations.
+	minTimesBoughtTogether = 2
+
+	// defaultRelatedProductsLimit is the number of related products returned when no limit is requested.
+	defaultRelatedProductsLimit = 10
+
+	// maxRelatedProductsLimit is the largest number of related products returned.
+	maxRelatedProductsLimit = 50
+)
+
+// RecommendationService defines the interface for recommending products from purchase history.
+type RecommendationService interface {
+	RecordPurchase(purchase *models.Purchase) error
+	RebuildCooccurrences() (int, error)
+	FindRelatedProducts(productID string, area models.GeoFilter, limit int) ([]models.RelatedProduct, error)
+}
+
+// recommendationService is an implementation of the RecommendationService interface.
+type recommendationService struct {
+	cooccurrenceRepo       repository.CooccurrenceRepository
+	purchaseRepo           repository.PurchaseRepository
+	productRepo            repository.ProductRepository
+	shopRepo               repository.ShopRepository
+	serviceableProductRepo repository.ServiceableProductRepository
+	licenceService         LicenceService
+}
+
+// NewRecommendationService creates a new instance of the recommendationService.
+func NewRecommendationService(cooccurrenceRepo repository.CooccurrenceRepository, purchaseRepo repository.PurchaseRepository, productRepo repository.ProductRepository, shopRepo repository.ShopRepository, serviceableProductRepo repository.ServiceableProductRepository, licenceService LicenceService) RecommendationService {
+	return &recommendationService{
+		cooccurrenceRepo:       cooccurrenceRepo,
+		purchaseRepo:           purchaseRepo,
+		productRepo:            productRepo,
+		shopRepo:               shopRepo,
+		serviceableProductRepo: serviceableProductRepo,
+		licenceService:         licenceService,
+	}
+}
+
+// RecordPurchase adds a purchase to the co-occurrence counts as soon as it is placed.
+func (s *recommendationService) RecordPurchase(purchase *models.Purchase) error {
+	counts := map[productPair]int{}
+	addBasket(counts, purchase)
+	if len(counts) == 0 {
+		return nil
+	}
+
+	return s.cooccurrenceRepo.IncrementCooccurrences(cooccurrences(counts))
+}
+
+// RebuildCooccurrences recomputes the co-occurrence counts from every recorded purchase, correcting
+// any drift left by purchases whose incremental update failed. It returns the number of pairs stored.
+func (s *recommendationService) RebuildCooccurrences() (int, error) {
+	counts := map[productPair]int{}
+	err := s.purchaseRepo.StreamPurchases(func(purchase models.Purchase) error {
+		addBasket(counts, &purchase)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.cooccurrenceRepo.ReplaceCooccurrences(cooccurrences(counts)); err != nil {
+		return 0, err
+	}
+
+	return len(counts), nil
+}
+
+// FindRelatedProducts lists the products most often bought together with a product, restricted to
+// the products in stock at shops within the area. Products are ranked by how strongly their purchases
+// overlap, so that best-sellers bought with everything do not crowd out genuine companions.
+func (s *recommendationService) FindRelatedProducts(productID string, area models.GeoFilter, limit int) ([]models.RelatedProduct, error) {
+	if limit <= 0 {
+		limit = defaultRelatedProductsLimit
+	}
+	if limit > maxRelatedProductsLimit {
+		limit = maxRelatedProductsLimit
+	}
+
+	product, err := s.productRepo.FindProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	pairs, err := s.cooccurrenceRepo.FindCooccurrences(product.ID, minTimesBoughtTogether)
+	if err != nil {
+		return nil, err
+	}
+	if len(pairs) == 0 {
+		return []models.RelatedProduct{}, nil
+	}
+
+	inStock, err := productsInStockNear(s.shopRepo, s.serviceableProductRepo, s.licenceService, area)
+	if err != nil {
+		return nil, err
+	}
+	serviceable := make(map[primitive.ObjectID]bool, len(inStock))
+	for _, id := range inStock {
+		serviceable[id] = true
+	}
+
+	timesBoughtTogether := map[primitive.ObjectID]int{}
+	var relatedIDs []primitive.ObjectID
+	for _, pair := range pairs {
+		if serviceable[pair.RelatedID] {
+			timesBoughtTogether[pair.RelatedID] = pair.Count
+			relatedIDs = append(relatedIDs, pair.RelatedID)
+		}
+	}
+	if len(relatedIDs) == 0 {
+		return []models.RelatedProduct{}, nil
+	}
+
+	purchaseCounts, err := s.cooccurrenceRepo.FindPurchaseCounts(append(relatedIDs, product.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.productRepo.FindProductsByIDs(relatedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	related := make([]models.RelatedProduct, 0, len(products))
+	for _, candidate := range products {
+		if !candidate.IsListed() {
+			continue
+		}
+		together := timesBoughtTogether[candidate.ID]
+		related = append(related, models.RelatedProduct{
+			Product:             candidate,
+			TimesBoughtTogether: together,
+			Score:               cosineScore(together, purchaseCounts[product.ID], purchaseCounts[candidate.ID]),
+		})
+	}
+
+	sort.SliceStable(related, func(i, j int) bool {
+		if related[i].Score != related[j].Score {
+			return related[i].Score > related[j].Score
+		}
+		return related[i].TimesBoughtTogether > related[j].TimesBoughtTogether
+	})
+	if len(related) > limit {
+		related = related[:limit]
+	}
+
+	return related, nil
+}
+
+// productPair identifies an ordered pair of products.
+type productPair struct {
+	productID, relatedID primitive.ObjectID
+}
+
+// addBasket counts every ordered pair of distinct products in a purchase, together with each
+// product paired with itself, which counts the purchases containing it.
+func addBasket(counts map[productPair]int, purchase *models.Purchase) {
+	seen := map[primitive.ObjectID]bool{}
+	var productIDs []primitive.ObjectID
+	for _, line := range purchase.Lines {
+		if !seen[line.ProductID] {
+			seen[line.ProductID] = true
+			productIDs = append(productIDs, line.ProductID)
+		}
+	}
+
+	for _, a := range productIDs {
+		for _, b := range productIDs {
+			counts[productPair{a, b}]++
+		}
+	}
+}
+
+// cooccurrences converts pair counts to the records stored by the repository.
+func cooccurrences(counts map[productPair]int) []models.ProductCooccurrence {
+	records := make([]models.ProductCooccurrence, 0, len(counts))
+	for pair, count := range counts {
+		records = append(records, models.ProductCooccurrence{
+			ProductID: pair.productID,
+			RelatedID: pair.relatedID,
+			Count:     count,
+		})
+	}
+
+	return records
+}
+
+// cosineScore measures how strongly two products are associated, from the number of purchases
+// containing both and the number containing each.
+func cosineScore(together, purchasesA, purchasesB int) float64 {
+	if purchasesA <= 0 || purchasesB <= 0 {
+		return 0
+	}
+
+	return math.Min(1, float64(together)/math.Sqrt(float64(purchasesA)*float64(purchasesB)))
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/validation"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// usernamePattern restricts usernames, which are stored lower-cased, to characters that are safe in URLs.
+// Users registered by phone have their E.164 number as username.
+var usernamePattern = regexp.MustCompile(`^\+?[a-z0-9._-]+$`)
+
+// postalCodePattern matches Indian PIN codes, which have six digits and do not start with zero.
+var postalCodePattern = regexp.MustCompile(`^[1-9][0-9]{5}$`)
+
+// userSchema declares the rules every user must satisfy. Field names are those of the stored document.
+var userSchema = validation.New(
+	validation.Field("username", func(u *models.User) string { return u.Username },
+		validation.Required(), validation.MaxLength(64), validation.Pattern(usernamePattern, "may only contain letters, digits, dots, dashes and underscores")),
+	validation.Field("email", func(u *models.User) string { return u.Email },
+		validation.MaxLength(254), validation.Email()),
+	validation.Field("first_name", func(u *models.User) string { return u.FirstName }, validation.MaxLength(100)),
+	validation.Field("last_name", func(u *models.User) string { return u.LastName }, validation.MaxLength(100)),
+	validation.Field("location", func(u *models.User) string { return u.Location }, validation.MaxLength(200)),
+	validation.Field("latitude", func(u *models.User) float64 { return u.Latitude }, validation.Range(-90.0, 90.0)),
+	validation.Field("longitude", func(u *models.User) float64 { return u.Longitude }, validation.Range(-180.0, 180.0)),
+)
+
+// shopSchema declares the rules every shop must satisfy.
+var shopSchema = validation.New(
+	validation.Field("shop_name", func(s *models.Shop) string { return s.ShopName },
+		validation.Required(), validation.MaxLength(120)),
+	validation.Field("owner_id", func(s *models.Shop) primitive.ObjectID { return s.OwnerID },
+		validation.NotZero[primitive.ObjectID]()),
+	validation.Field("location", func(s *models.Shop) string { return s.Location }, validation.MaxLength(200)),
+	validation.Field("operating_hours", func(s *models.Shop) string { return s.OperatingHours }, validation.MaxLength(200)),
+	validation.Field("latitude", func(s *models.Shop) float64 { return s.Latitude }, validation.Range(-90.0, 90.0)),
+	validation.Field("longitude", func(s *models.Shop) float64 { return s.Longitude }, validation.Range(-180.0, 180.0)),
+)
+
+// plotSchema declares the rules every plot must satisfy. The boundary is checked by the plot service.
+var plotSchema = validation.New(
+	validation.Field("name", func(p *models.Plot) string { return p.Name },
+		validation.Required(), validation.MaxLength(100)),
+	validation.Field("soil_type", func(p *models.Plot) string { return p.SoilType },
+		validation.OneOf(models.SoilAlluvial, models.SoilBlack, models.SoilRed, models.SoilLaterite,
+			models.SoilArid, models.SoilSaline, models.SoilPeaty, models.SoilForest)),
+	validation.Field("irrigation_source", func(p *models.Plot) string { return p.IrrigationSource },
+		validation.OneOf(models.IrrigationRainfed, models.IrrigationCanal, models.IrrigationTubewell, models.IrrigationWell,
+			models.IrrigationTank, models.IrrigationDrip, models.IrrigationSprinkler)),
+	validation.Field("current_crop", func(p *models.Plot) string { return p.CurrentCrop }, validation.MaxLength(100)),
+)
+
+// addressSchema declares the rules every address in an address book must satisfy.
+var addressSchema = validation.New(
+	validation.Field("label", func(a *models.Address) string { return a.Label },
+		validation.Required(), validation.MaxLength(50)),
+	validation.Field("line1", func(a *models.Address) string { return a.Line1 },
+		validation.Required(), validation.MaxLength(200)),
+	validation.Field("line2", func(a *models.Address) string { return a.Line2 }, validation.MaxLength(200)),
+	validation.Field("locality", func(a *models.Address) string { return a.Locality }, validation.MaxLength(100)),
+	validation.Field("district", func(a *models.Address) string { return a.District }, validation.MaxLength(100)),
+	validation.Field("state", func(a *models.Address) string { return a.State }, validation.MaxLength(100)),
+	validation.Field("postal_code", func(a *models.Address) string { return a.PostalCode },
+		validation.Pattern(postalCodePattern, "must be a six-digit PIN code")),
+	validation.Field("landmark", func(a *models.Address) string { return a.Landmark }, validation.MaxLength(200)),
+	validation.Field("latitude", func(a *models.Address) float64 { return a.Latitude }, validation.Range(-90.0, 90.0)),
+	validation.Field("longitude", func(a *models.Address) float64 { return a.Longitude }, validation.Range(-180.0, 180.0)),
+)
+
+// productSchema declares the rules every product must satisfy. Category attributes and variants
+// depend on the product's category and are checked by the product service afterwards.
+var productSchema = validation.New(
+	validation.Field("sku", func(p *models.Product) string { return p.SKU }, validation.MaxLength(64)),
+	validation.Field("product_name", func(p *models.Product) string { return p.ProductName },
+		validation.Required(), validation.MaxLength(200)),
+	validation.Field("description", func(p *models.Product) string { return p.Description }, validation.MaxLength(5000)),
+	validation.Field("brand", func(p *models.Product) string { return p.Brand }, validation.MaxLength(100)),
+	validation.Field("price", func(p *models.Product) float64 { return p.Price }, validation.Min(0.0)),
+	validation.Field("stock_quantity", func(p *models.Product) int { return p.StockQuantity }, validation.Min(0)),
+	validation.Field("rating", func(p *models.Product) float64 { return p.Rating }, validation.Range(0.0, 5.0)),
+	validation.Field("popularity", func(p *models.Product) int { return p.Popularity }, validation.Min(0)),
+)
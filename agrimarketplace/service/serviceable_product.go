@@ -3,22 +3,40 @@ package service
 import (
 	"agrimarketplace/models"
 	"agrimarketplace/repository"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Sort keys accepted when listing the shops that sell a product.
+const (
+	SortByDistance = "distance"
+	SortByPrice    = "price"
+	SortByQuantity = "quantity"
 )
 
 // ServiceableProductService defines the interface for working with serviceable products.
 type ServiceableProductService interface {
 	FindServiceableProducts() ([]models.ServiceableProduct, error)
+	FindShopsSellingProduct(productID string, latitude, longitude float64, radiusInMeters float64, sortBy string) ([]models.ProductAvailability, error)
+	SetShopListing(shopID, productID string, isServiceable bool, stockQuantity int) (*models.ServiceableProduct, error)
 }
 
 // serviceableProductService is an implementation of the ServiceableProductService interface.
 type serviceableProductService struct {
 	serviceableProductRepo repository.ServiceableProductRepository
+	shopRepo               repository.ShopRepository
+	productRepo            repository.ProductRepository
+	licenceService         LicenceService
 }
 
 // NewServiceableProductService creates a new instance of the serviceableProductService.
-func NewServiceableProductService(serviceableProductRepo repository.ServiceableProductRepository) ServiceableProductService {
+func NewServiceableProductService(serviceableProductRepo repository.ServiceableProductRepository, shopRepo repository.ShopRepository, productRepo repository.ProductRepository, licenceService LicenceService) ServiceableProductService {
 	return &serviceableProductService{
 		serviceableProductRepo: serviceableProductRepo,
+		shopRepo:               shopRepo,
+		productRepo:            productRepo,
+		licenceService:         licenceService,
 	}
 }
 
@@ -30,6 +48,165 @@ func (s *serviceableProductService) FindServiceableProducts() ([]models.Servicea
 		return nil, err
 	}
 
-	// You can implement additional logic here if needed
-	return serviceableProducts, nil
+	// Regulated products are only serviceable by shops holding a valid licence for them
+	return s.licenceService.FilterLicensedListings(serviceableProducts)
+}
+
+// FindShopsSellingProduct finds the nearby shops that currently have a product in stock,
+// sorted by distance, price or available quantity.
+func (s *serviceableProductService) FindShopsSellingProduct(productID string, latitude, longitude float64, radiusInMeters float64, sortBy string) ([]models.ProductAvailability, error) {
+	if sortBy == "" {
+		sortBy = SortByDistance
+	}
+	if sortBy != SortByDistance && sortBy != SortByPrice && sortBy != SortByQuantity {
+		return nil, ErrInvalidSortKey
+	}
+
+	product, err := s.productRepo.FindProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	var availability []models.ProductAvailability
+	if aggregator, ok := s.serviceableProductRepo.(repository.ProductAvailabilityAggregator); ok {
+		availability, err = aggregator.AggregateProductAvailability(productID, latitude, longitude, radiusInMeters)
+	} else {
+		availability, err = s.joinProductAvailability(productID, latitude, longitude, radiusInMeters)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Regulated products are only offered by shops holding a valid licence for them
+	shopIDs := make([]primitive.ObjectID, 0, len(availability))
+	for _, item := range availability {
+		shopIDs = append(shopIDs, item.Shop.ID)
+	}
+	licensed, err := s.licenceService.LicensedShops(product, shopIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	offered := availability[:0]
+	for _, item := range availability {
+		if !licensed[item.Shop.ID] {
+			continue
+		}
+		// Shops that have not set their own price sell at the catalog price
+		if item.Price <= 0 {
+			item.Price = product.Price
+		}
+		offered = append(offered, item)
+	}
+	availability = offered
+
+	sortProductAvailability(availability, sortBy)
+
+	return availability, nil
+}
+
+// SetShopListing sets whether a shop serves a product and the quantity it holds.
+// Shops cannot serve regulated products without a valid licence, but may always stop serving them.
+// The quantity of listings tracked by lot is managed through their lots and left unchanged.
+func (s *serviceableProductService) SetShopListing(shopID, productID string, isServiceable bool, stockQuantity int) (*models.ServiceableProduct, error) {
+	if stockQuantity < 0 {
+		return nil, ErrInvalidStockQuantity
+	}
+
+	shop, err := s.shopRepo.FindShopByID(shopID)
+	if err != nil {
+		return nil, err
+	}
+	if shop == nil {
+		return nil, ErrShopNotFound
+	}
+
+	product, err := s.productRepo.FindProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	if isServiceable {
+		if err := s.licenceService.CheckShopLicence(shop.ID, product); err != nil {
+			return nil, err
+		}
+	}
+
+	// The stock of listings tracked by lot is the unexpired quantity of their lots
+	listing, err := s.serviceableProductRepo.FindServiceableProduct(shop.ID, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	if listing != nil && listing.LotTracked {
+		stockQuantity = listing.StockQuantity
+	}
+
+	if err := s.serviceableProductRepo.SetServiceableProductStock(shop.ID, product.ID, isServiceable, stockQuantity); err != nil {
+		return nil, err
+	}
+
+	return s.serviceableProductRepo.FindServiceableProduct(shop.ID, product.ID)
+}
+
+// joinProductAvailability joins nearby shops with the product's listings in memory.
+// It is used for backends that cannot perform the join themselves.
+func (s *serviceableProductService) joinProductAvailability(productID string, latitude, longitude float64, radiusInMeters float64) ([]models.ProductAvailability, error) {
+	listings, err := s.serviceableProductRepo.FindServiceableProductsByProduct(productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(listings) == 0 {
+		return nil, nil
+	}
+
+	stockByShop := make(map[string]models.ServiceableProduct, len(listings))
+	for _, listing := range listings {
+		stockByShop[listing.ShopID.Hex()] = listing
+	}
+
+	shops, err := s.shopRepo.FindNearbyShops(latitude, longitude, radiusInMeters)
+	if err != nil {
+		return nil, err
+	}
+
+	var availability []models.ProductAvailability
+	for _, shop := range shops {
+		listing, ok := stockByShop[shop.ID.Hex()]
+		if !ok {
+			continue
+		}
+		availability = append(availability, models.ProductAvailability{
+			Shop:              shop,
+			ProductID:         listing.ProductID,
+			Price:             listing.Price,
+			AvailableQuantity: listing.StockQuantity,
+			DistanceMeters:    haversineKm(latitude, longitude, shop.Latitude, shop.Longitude) * 1000,
+		})
+	}
+
+	return availability, nil
+}
+
+// sortProductAvailability orders shops by the requested key, breaking ties by distance.
+func sortProductAvailability(availability []models.ProductAvailability, sortBy string) {
+	sort.SliceStable(availability, func(i, j int) bool {
+		a, b := availability[i], availability[j]
+		switch sortBy {
+		case SortByPrice:
+			if a.Price != b.Price {
+				return a.Price < b.Price
+			}
+		case SortByQuantity:
+			if a.AvailableQuantity != b.AvailableQuantity {
+				return a.AvailableQuantity > b.AvailableQuantity
+			}
+		}
+		return a.DistanceMeters < b.DistanceMeters
+	})
 }
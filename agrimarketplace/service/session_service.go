@@ -0,0 +1,275 @@
+package service
+
+import (
+	"agrimarketplace/auth"
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// accessTokenPurpose is the purpose of the tokens authenticating a signed-in user's requests.
+const accessTokenPurpose = "access"
+
+// SessionConfig holds the settings of sign-in sessions.
+type SessionConfig struct {
+	// AccessTokenTTL is how long an access token remains valid; clients refresh it with their refresh token.
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL is how long a session stays signed in without being used.
+	RefreshTokenTTL time.Duration
+	// MaxRotatedHashes is the number of replaced refresh tokens remembered per session to detect their reuse.
+	MaxRotatedHashes int
+}
+
+// SessionService defines the interface for managing users' sign-in sessions and the tokens issued in them.
+type SessionService interface {
+	StartSession(user *models.User, device models.DeviceInfo) (*models.Login, error)
+	Refresh(refreshToken string, device models.DeviceInfo) (*models.Login, error)
+	Authenticate(accessToken string) (*models.User, error)
+	SignOut(accessToken string) error
+	GetUserSessions(userID string) ([]models.Session, error)
+	RevokeSession(userID, sessionID string) error
+	RevokeAllSessions(userID, reason string) (int64, error)
+}
+
+// sessionService is an implementation of the SessionService interface.
+type sessionService struct {
+	sessionRepo repository.SessionRepository
+	userRepo    repository.UserRepository
+	signer      *auth.Signer
+	config      SessionConfig
+}
+
+// NewSessionService creates a new instance of the sessionService.
+func NewSessionService(sessionRepo repository.SessionRepository, userRepo repository.UserRepository, signer *auth.Signer, config SessionConfig) SessionService {
+	return &sessionService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		signer:      signer,
+		config:      config,
+	}
+}
+
+// StartSession signs in a user on a device, issuing an access token and a refresh token.
+func (s *sessionService) StartSession(user *models.User, device models.DeviceInfo) (*models.Login, error) {
+	refreshToken, err := auth.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		ID:         primitive.NewObjectID(),
+		UserID:     user.ID,
+		TokenHash:  s.signer.Digest(refreshToken),
+		Device:     device,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.config.RefreshTokenTTL),
+	}
+	if err := s.sessionRepo.InsertSession(session); err != nil {
+		return nil, err
+	}
+
+	return s.login(user, session, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token, which replaces
+// it. A refresh token that was already replaced is treated as stolen: the session it belongs to is
+// revoked, signing out both the thief and the user.
+func (s *sessionService) Refresh(refreshToken string, device models.DeviceInfo) (*models.Login, error) {
+	tokenHash := s.signer.Digest(refreshToken)
+	session, err := s.sessionRepo.FindSessionByTokenHash(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, s.checkReuse(tokenHash)
+	}
+
+	now := time.Now()
+	if session.RevokedAt != nil || !now.Before(session.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.FindUserByID(session.UserID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.IsClosed() {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	newRefreshToken, err := auth.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	session.Device = mergeDeviceInfo(session.Device, device)
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.config.RefreshTokenTTL)
+	rotated, err := s.sessionRepo.RotateSessionToken(session, s.signer.Digest(newRefreshToken), s.config.MaxRotatedHashes)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// Another request replaced the token first, so it was used twice
+		return nil, s.checkReuse(tokenHash)
+	}
+
+	session.TokenHash = s.signer.Digest(newRefreshToken)
+	return s.login(user, session, newRefreshToken)
+}
+
+// checkReuse revokes the session a replaced refresh token belongs to, if any, and returns the error to report.
+func (s *sessionService) checkReuse(tokenHash string) error {
+	session, err := s.sessionRepo.FindSessionByRotatedHash(tokenHash)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return ErrInvalidRefreshToken
+	}
+
+	revoked, err := s.sessionRepo.RevokeSession(session.ID, models.SessionRevokedTokenReuse, time.Now())
+	if err != nil {
+		return err
+	}
+	if revoked {
+		log.Printf("Refresh token reused in session %s of user %s; session revoked", session.ID.Hex(), session.UserID.Hex())
+	}
+
+	return ErrRefreshTokenReused
+}
+
+// login issues an access token in a session and describes the sign-in.
+func (s *sessionService) login(user *models.User, session *models.Session, refreshToken string) (*models.Login, error) {
+	accessToken, err := s.signer.SignInSession(user.ID.Hex(), session.ID.Hex(), accessTokenPurpose, user.TokenVersion, s.config.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Password = ""
+	return &models.Login{
+		User:             user,
+		SessionID:        session.ID,
+		AccessToken:      accessToken,
+		ExpiresAt:        time.Now().Add(s.config.AccessTokenTTL),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// Authenticate returns the user an access token was issued to. Tokens issued in a session that was
+// revoked, or before the user's password was changed or their account closed, are rejected.
+func (s *sessionService) Authenticate(accessToken string) (*models.User, error) {
+	user, _, err := s.authenticate(accessToken)
+	return user, err
+}
+
+// authenticate checks an access token like Authenticate and also returns its session.
+func (s *sessionService) authenticate(accessToken string) (*models.User, *models.Session, error) {
+	claims, err := s.signer.Verify(accessToken, accessTokenPurpose)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session, err := s.sessionRepo.FindSessionByID(claims.Session)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session == nil || session.RevokedAt != nil || session.UserID.Hex() != claims.Subject {
+		return nil, nil, auth.ErrInvalidToken
+	}
+
+	user, err := s.userRepo.FindUserByID(claims.Subject)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil || user.IsClosed() || claims.Version != user.TokenVersion {
+		return nil, nil, auth.ErrInvalidToken
+	}
+
+	return user, session, nil
+}
+
+// SignOut revokes the session an access token was issued in.
+func (s *sessionService) SignOut(accessToken string) error {
+	_, session, err := s.authenticate(accessToken)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.sessionRepo.RevokeSession(session.ID, models.SessionRevokedSignOut, time.Now())
+	return err
+}
+
+// GetUserSessions retrieves the sessions a user is signed in with, most recently used first.
+func (s *sessionService) GetUserSessions(userID string) ([]models.Session, error) {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	sessions, err := s.sessionRepo.FindActiveSessionsByUser(user.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if sessions == nil {
+		sessions = []models.Session{}
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession signs a user out of one of their sessions.
+func (s *sessionService) RevokeSession(userID, sessionID string) error {
+	session, err := s.sessionRepo.FindSessionByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID.Hex() != userID {
+		return ErrSessionNotFound
+	}
+
+	_, err = s.sessionRepo.RevokeSession(session.ID, models.SessionRevokedByUser, time.Now())
+	return err
+}
+
+// RevokeAllSessions signs a user out everywhere and returns the number of sessions revoked.
+func (s *sessionService) RevokeAllSessions(userID, reason string) (int64, error) {
+	user, err := s.userRepo.FindUserByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		return 0, ErrUserNotFound
+	}
+
+	return s.sessionRepo.RevokeUserSessions(user.ID, reason, time.Now())
+}
+
+// mergeDeviceInfo updates what is known of a session's device with what a refresh request tells.
+func mergeDeviceInfo(known, reported models.DeviceInfo) models.DeviceInfo {
+	if reported.Name != "" {
+		known.Name = reported.Name
+	}
+	if reported.Platform != "" {
+		known.Platform = reported.Platform
+	}
+	if reported.AppVersion != "" {
+		known.AppVersion = reported.AppVersion
+	}
+	if reported.UserAgent != "" {
+		known.UserAgent = reported.UserAgent
+	}
+	if reported.IPAddress != "" {
+		known.IPAddress = reported.IPAddress
+	}
+	return known
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"agrimarketplace/models"
 	"agrimarketplace/repository"
+	"log"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -13,23 +15,34 @@ type ShopService interface {
 	FindShopByID(id string) (*models.Shop, error)
 	UpdateShop(shop *models.Shop) error
 	DeleteShop(id string) error
+	RestoreShop(id string) (*models.Shop, error)
+	PurgeDeletedShops(deletedBefore time.Time) (int, error)
 	FindNearbyShops(latitude, longitude float64, radiusInMeters float64) ([]models.Shop, error)
+	FindShopsInViewport(box models.BoundingBox, zoom int) (*models.ShopViewport, error)
 }
 
 // shopService is an implementation of the ShopService interface.
 type shopService struct {
-	shopRepo repository.ShopRepository
+	shopRepo     repository.ShopRepository
+	userRepo     repository.UserRepository
+	mediaService MediaService
 }
 
 // NewShopService creates a new instance of the shopService.
-func NewShopService(shopRepo repository.ShopRepository) ShopService {
+func NewShopService(shopRepo repository.ShopRepository, userRepo repository.UserRepository, mediaService MediaService) ShopService {
 	return &shopService{
-		shopRepo: shopRepo,
+		shopRepo:     shopRepo,
+		userRepo:     userRepo,
+		mediaService: mediaService,
 	}
 }
 
 // CreateShop creates a new shop.
 func (s *shopService) CreateShop(shop *models.Shop) (*models.Shop, error) {
+	if err := shopSchema.Validate(shop); err != nil {
+		return nil, err
+	}
+
 	// Implement the logic to create a shop, e.g., validate input, generate ID, etc.
 	// You can also add additional business logic here.
 
@@ -42,8 +55,13 @@ func (s *shopService) CreateShop(shop *models.Shop) (*models.Shop, error) {
 		return nil, ErrShopAlreadyExists
 	}
 
+	if err := s.checkOwner(shop.OwnerID); err != nil {
+		return nil, err
+	}
+
 	// Generate an ObjectID for the shop
 	shop.ID = primitive.NewObjectID()
+	shop.DeletedAt = nil
 
 	// Call the repository to insert the shop into the database
 	if err := s.shopRepo.InsertShop(shop); err != nil {
@@ -64,6 +82,10 @@ func (s *shopService) FindShopByID(id string) (*models.Shop, error) {
 
 // UpdateShop updates an existing shop.
 func (s *shopService) UpdateShop(shop *models.Shop) error {
+	if err := shopSchema.Validate(shop); err != nil {
+		return err
+	}
+
 	// Implement the logic to update a shop, e.g., validate input, handle errors, etc.
 	// You can also add additional business logic here.
 
@@ -76,6 +98,16 @@ func (s *shopService) UpdateShop(shop *models.Shop) error {
 		return ErrShopNotFound
 	}
 
+	// Shops can only be handed over to verified users
+	if shop.OwnerID != existingShop.OwnerID {
+		if err := s.checkOwner(shop.OwnerID); err != nil {
+			return err
+		}
+	}
+
+	// Deletion is only managed through DeleteShop and RestoreShop
+	shop.DeletedAt = nil
+
 	// Call the repository to update the shop in the database
 	if err := s.shopRepo.UpdateShop(shop); err != nil {
 		return err
@@ -84,11 +116,9 @@ func (s *shopService) UpdateShop(shop *models.Shop) error {
 	return nil
 }
 
-// DeleteShop deletes a shop by its ID.
+// DeleteShop soft-deletes a shop by its ID.
+// The shop stays referenced by past orders and can be restored until it is purged.
 func (s *shopService) DeleteShop(id string) error {
-	// Implement the logic to delete a shop, e.g., handle errors, etc.
-	// You can also add additional business logic here.
-
 	// Ensure that the shop to be deleted exists
 	existingShop, err := s.shopRepo.FindShopByID(id)
 	if err != nil {
@@ -106,6 +136,39 @@ func (s *shopService) DeleteShop(id string) error {
 	return nil
 }
 
+// RestoreShop undoes the soft deletion of a shop.
+func (s *shopService) RestoreShop(id string) (*models.Shop, error) {
+	shop, err := s.shopRepo.FindDeletedShopByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if shop == nil {
+		return nil, ErrShopNotFound
+	}
+
+	if err := s.shopRepo.RestoreShop(id); err != nil {
+		return nil, err
+	}
+	shop.DeletedAt = nil
+
+	return shop, nil
+}
+
+// PurgeDeletedShops permanently removes the shops soft-deleted before the given time, with their images.
+// It returns the number of shops removed.
+func (s *shopService) PurgeDeletedShops(deletedBefore time.Time) (int, error) {
+	shopIDs, err := s.shopRepo.PurgeDeletedShops(deletedBefore)
+
+	// Images left behind on failure are removed by the orphaned image cleanup
+	for _, shopID := range shopIDs {
+		if err := s.mediaService.DeleteImagesByOwner(models.ImageOwnerShop, shopID); err != nil {
+			log.Printf("Error deleting images of shop %s: %v", shopID.Hex(), err)
+		}
+	}
+
+	return len(shopIDs), err
+}
+
 // FindNearbyShops finds nearby shops based on latitude and longitude within a specified radius.
 func (s *shopService) FindNearbyShops(latitude, longitude float64, radiusInMeters float64) ([]models.Shop, error) {
 	// Implement the logic to find nearby shops based on coordinates and radius.
@@ -118,3 +181,40 @@ func (s *shopService) FindNearbyShops(latitude, longitude float64, radiusInMeter
 
 	return nearbyShops, nil
 }
+
+// FindShopsInViewport finds the shops inside a map viewport, clustering them at low zoom levels.
+func (s *shopService) FindShopsInViewport(box models.BoundingBox, zoom int) (*models.ShopViewport, error) {
+	if box.MinLatitude < -90 || box.MaxLatitude > 90 || box.MinLatitude > box.MaxLatitude ||
+		box.MinLongitude < -180 || box.MinLongitude > 180 || box.MaxLongitude < -180 || box.MaxLongitude > 180 {
+		return nil, ErrInvalidBoundingBox
+	}
+	if zoom < 0 || zoom > maxZoom {
+		return nil, ErrInvalidZoom
+	}
+
+	shops, err := s.shopRepo.FindShopsInBoundingBox(box)
+	if err != nil {
+		return nil, err
+	}
+
+	viewport := &models.ShopViewport{Zoom: zoom}
+	viewport.Shops, viewport.Clusters = clusterShops(shops, zoom)
+
+	return viewport, nil
+}
+
+// checkOwner checks that the owner of a shop is a user who has verified their email address.
+func (s *shopService) checkOwner(ownerID primitive.ObjectID) error {
+	owner, err := s.userRepo.FindUserByID(ownerID.Hex())
+	if err != nil {
+		return err
+	}
+	if owner == nil {
+		return ErrUserNotFound
+	}
+	if !owner.IsVerified() {
+		return ErrUserNotVerified
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"image"
+	"image/draw"
+)
+
+// thumbnail scales an image down to fit within size×size pixels, preserving its aspect ratio.
+// Each thumbnail pixel is the average of the source pixels it covers, which avoids the
+// aliasing of nearest-neighbour sampling. Images that already fit are returned unchanged.
+func thumbnail(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return src
+	}
+
+	dstWidth, dstHeight := size, size
+	if width > height {
+		dstHeight = maxInt(1, height*size/width)
+	} else {
+		dstWidth = maxInt(1, width*size/height)
+	}
+
+	// Work on RGBA pixels directly rather than through the slow image.Image interface
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0, y1 := y*height/dstHeight, (y+1)*height/dstHeight
+		for x := 0; x < dstWidth; x++ {
+			x0, x1 := x*width/dstWidth, (x+1)*width/dstWidth
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				offset := rgba.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(rgba.Pix[offset])
+					g += uint64(rgba.Pix[offset+1])
+					b += uint64(rgba.Pix[offset+2])
+					a += uint64(rgba.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/repository"
+	"errors"
+	"strings"
+)
+
+// ConflictError is returned when a user would share a username, email address or phone number
+// with another user. Field names the offending field.
+type ConflictError struct {
+	Field string
+}
+
+// Error implements the error interface.
+func (e *ConflictError) Error() string {
+	return e.Field + " is already taken"
+}
+
+// normalizeUserIdentity puts the fields identifying a user in the form in which they are stored:
+// usernames and email addresses are trimmed and lower-cased, so that they compare ignoring case.
+func normalizeUserIdentity(user *models.User) {
+	user.Username = strings.ToLower(strings.TrimSpace(user.Username))
+	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+}
+
+// checkUserConflicts reports a ConflictError when another user already has the username, email
+// address or phone number of a user. The repository enforces the same rule on write; checking
+// first gives the same answer on backends that cannot enforce it atomically.
+func checkUserConflicts(userRepo repository.UserRepository, user *models.User) error {
+	lookups := []struct {
+		field string
+		value string
+		find  func(string) (*models.User, error)
+	}{
+		{"username", user.Username, userRepo.FindUserByUsername},
+		{"email", user.Email, userRepo.FindUserByEmail},
+		{"phone", user.Phone, userRepo.FindUserByPhone},
+	}
+
+	for _, lookup := range lookups {
+		if lookup.value == "" {
+			continue
+		}
+		existingUser, err := lookup.find(lookup.value)
+		if err != nil {
+			return err
+		}
+		if existingUser != nil && existingUser.ID != user.ID {
+			return &ConflictError{Field: lookup.field}
+		}
+	}
+
+	return nil
+}
+
+// conflictError converts a duplicate key reported by the repository to a ConflictError.
+func conflictError(err error) error {
+	var duplicate *repository.DuplicateKeyError
+	if errors.As(err, &duplicate) {
+		return &ConflictError{Field: duplicate.Field}
+	}
+	return err
+}
@@ -3,8 +3,37 @@ package service
 import (
 	"agrimarketplace/models"
 	"agrimarketplace/repository"
+	"math"
+	"sort"
+	"strings"
+	"time"
 )
 
+// distanceBuckets are the approximate distances, in kilometres, reported by nearby-users searches.
+var distanceBuckets = []struct {
+	upToKm float64
+	label  string
+}{
+	{1, "under 1 km"},
+	{5, "1-5 km"},
+	{10, "5-10 km"},
+	{25, "10-25 km"},
+	{50, "25-50 km"},
+	{math.Inf(1), "over 50 km"},
+}
+
+// NearbyUsersConfig holds the settings of nearby-users searches.
+type NearbyUsersConfig struct {
+	// AllowedRoles are the roles of the users who may search.
+	AllowedRoles []string
+	// GeohashPrecision is the length of the geohash cells users are located by; 5 gives cells of about 5 km.
+	GeohashPrecision int
+	// MaxRadius is the largest search radius, in metres.
+	MaxRadius float64
+	// MaxResults is the number of users returned, nearest first.
+	MaxResults int
+}
+
 // UserService defines the interface for working with user data.
 type UserService interface {
 	FindUserByID(id string) (*models.User, error)
@@ -12,18 +41,23 @@ type UserService interface {
 	InsertUser(user *models.User) error
 	UpdateUser(user *models.User) error
 	DeleteUser(id string) error
-	FindNearbyUsers(latitude, longitude float64, radiusInMeters float64) ([]models.User, error)
+	RestoreUser(id string) (*models.User, error)
+	PurgeDeletedUsers(deletedBefore time.Time) (int, error)
+	SetUserRole(id, role string) (*models.User, error)
+	FindNearbyUsers(requester *models.User, latitude, longitude float64, radiusInMeters float64) ([]models.NearbyUser, error)
 }
 
 // userService is an implementation of the UserService interface.
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo     repository.UserRepository
+	nearbyConfig NearbyUsersConfig
 }
 
 // NewUserService creates a new instance of the userService.
-func NewUserService(userRepo repository.UserRepository) UserService {
+func NewUserService(userRepo repository.UserRepository, nearbyConfig NearbyUsersConfig) UserService {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:     userRepo,
+		nearbyConfig: nearbyConfig,
 	}
 }
 
@@ -32,27 +66,254 @@ func (s *userService) FindUserByID(id string) (*models.User, error) {
 	return s.userRepo.FindUserByID(id)
 }
 
-// FindUserByUsername retrieves a user by their username.
+// FindUserByUsername retrieves a user by their username, ignoring case.
 func (s *userService) FindUserByUsername(username string) (*models.User, error) {
-	return s.userRepo.FindUserByUsername(username)
+	return s.userRepo.FindUserByUsername(strings.ToLower(strings.TrimSpace(username)))
 }
 
 // InsertUser inserts a new user into the database.
+// A *ConflictError is returned when another user has the same username, email address or phone number.
 func (s *userService) InsertUser(user *models.User) error {
-	return s.userRepo.InsertUser(user)
+	crops, err := normalizeCrops(user.Crops)
+	if err != nil {
+		return err
+	}
+	user.Crops = crops
+
+	normalizeUserIdentity(user)
+	if err := userSchema.Validate(user); err != nil {
+		return err
+	}
+	if err := checkUserConflicts(s.userRepo, user); err != nil {
+		return err
+	}
+
+	// Roles are only granted through SetUserRole
+	user.Role = ""
+	user.DeletedAt = nil
+	return conflictError(s.userRepo.InsertUser(user))
 }
 
-// UpdateUser updates an existing user in the database.
+// UpdateUser updates an existing user in the database. Changing the email address returns the
+// account to pending verification and revokes every token issued to the user.
+// A *ConflictError is returned when another user has the same username or email address.
 func (s *userService) UpdateUser(user *models.User) error {
-	return s.userRepo.UpdateUser(user)
+	crops, err := normalizeCrops(user.Crops)
+	if err != nil {
+		return err
+	}
+	user.Crops = crops
+
+	existingUser, err := s.userRepo.FindUserByID(user.ID.Hex())
+	if err != nil {
+		return err
+	}
+	if existingUser == nil {
+		return ErrUserNotFound
+	}
+	if existingUser.IsClosed() {
+		return ErrAccountClosed
+	}
+
+	// Accounts are only activated through email verification, phone numbers only set once verified
+	// and roles only granted through SetUserRole
+	user.Status = existingUser.Status
+	user.VerifiedAt = existingUser.VerifiedAt
+	user.Phone = existingUser.Phone
+	user.Role = existingUser.Role
+
+	user.TokenVersion = existingUser.TokenVersion
+
+	normalizeUserIdentity(user)
+	if err := userSchema.Validate(user); err != nil {
+		return err
+	}
+	if err := checkUserConflicts(s.userRepo, user); err != nil {
+		return err
+	}
+
+	// A new email address is unverified until the user confirms it
+	emailChanged := user.Email != "" && user.Email != existingUser.Email
+	if emailChanged {
+		user.Status = models.UserStatusPending
+		user.VerifiedAt = nil
+	}
+
+	// Deletion is only managed through DeleteUser and RestoreUser
+	user.DeletedAt = nil
+	if err := conflictError(s.userRepo.UpdateUser(user)); err != nil {
+		return err
+	}
+
+	// Links already sent to the previous email address must not confirm the new one
+	if emailChanged {
+		if err := s.userRepo.RevokeUserTokens(user.ID); err != nil {
+			return err
+		}
+		user.TokenVersion++
+	}
+
+	return nil
 }
 
-// DeleteUser deletes a user by their ID.
+// DeleteUser soft-deletes a user by their ID.
+// The account can be restored until it is purged.
 func (s *userService) DeleteUser(id string) error {
+	user, err := s.userRepo.FindUserByID(id)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+
 	return s.userRepo.DeleteUser(id)
 }
 
-// FindNearbyUsers finds nearby users based on latitude and longitude within a specified radius.
-func (s *userService) FindNearbyUsers(latitude, longitude float64, radiusInMeters float64) ([]models.User, error) {
-	return s.userRepo.FindNearbyUsers(latitude, longitude, radiusInMeters)
+// RestoreUser undoes the soft deletion of a user.
+func (s *userService) RestoreUser(id string) (*models.User, error) {
+	user, err := s.userRepo.FindDeletedUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.userRepo.RestoreUser(id); err != nil {
+		return nil, err
+	}
+	user.DeletedAt = nil
+
+	return user, nil
+}
+
+// PurgeDeletedUsers permanently removes the users soft-deleted before the given time.
+// It returns the number of users removed.
+func (s *userService) PurgeDeletedUsers(deletedBefore time.Time) (int, error) {
+	userIDs, err := s.userRepo.PurgeDeletedUsers(deletedBefore)
+	return len(userIDs), err
+}
+
+// SetUserRole grants a role to a user, or removes their role when it is empty.
+func (s *userService) SetUserRole(id, role string) (*models.User, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role != "" && role != models.RoleAdmin && role != models.RoleFieldAgent {
+		return nil, ErrInvalidRole
+	}
+
+	user, err := s.userRepo.FindUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.userRepo.SetUserRole(user.ID, role); err != nil {
+		return nil, err
+	}
+
+	user.Role = role
+	return user, nil
+}
+
+// FindNearbyUsers finds the users within a radius of a point who opted in to being discovered, nearest first.
+// Only users with one of the allowed roles may search. Users are located by the centre of the geohash
+// cell containing them, and their distance is given as an approximate range.
+func (s *userService) FindNearbyUsers(requester *models.User, latitude, longitude float64, radiusInMeters float64) ([]models.NearbyUser, error) {
+	if !s.canFindNearbyUsers(requester) {
+		return nil, ErrNotAuthorized
+	}
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 ||
+		radiusInMeters <= 0 || radiusInMeters > s.nearbyConfig.MaxRadius {
+		return nil, ErrInvalidSearchArea
+	}
+
+	users, err := s.userRepo.FindDiscoverableUsersInBox(boxAround(latitude, longitude, radiusInMeters))
+	if err != nil {
+		return nil, err
+	}
+
+	// Distances are computed from the exact locations, but only their range is returned
+	type candidate struct {
+		user       models.User
+		distanceKm float64
+	}
+	var candidates []candidate
+	for _, user := range users {
+		distanceKm := haversineKm(latitude, longitude, user.Latitude, user.Longitude)
+		if distanceKm*1000 <= radiusInMeters && user.ID != requester.ID {
+			candidates = append(candidates, candidate{user: user, distanceKm: distanceKm})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].distanceKm < candidates[j].distanceKm })
+	if len(candidates) > s.nearbyConfig.MaxResults {
+		candidates = candidates[:s.nearbyConfig.MaxResults]
+	}
+
+	nearbyUsers := make([]models.NearbyUser, 0, len(candidates))
+	for _, c := range candidates {
+		hash, cellLat, cellLon := geohashCell(c.user.Latitude, c.user.Longitude, s.nearbyConfig.GeohashPrecision)
+		nearbyUsers = append(nearbyUsers, models.NearbyUser{
+			ID:        c.user.ID,
+			Username:  c.user.Username,
+			FirstName: c.user.FirstName,
+			Geohash:   hash,
+			Latitude:  cellLat,
+			Longitude: cellLon,
+			Distance:  distanceBucket(c.distanceKm),
+		})
+	}
+
+	return nearbyUsers, nil
+}
+
+// canFindNearbyUsers reports whether a user's role allows them to search for nearby users.
+func (s *userService) canFindNearbyUsers(requester *models.User) bool {
+	if requester == nil || requester.Role == "" {
+		return false
+	}
+	for _, role := range s.nearbyConfig.AllowedRoles {
+		if requester.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// boxAround returns a bounding box containing the circle of a radius around a point.
+func boxAround(latitude, longitude, radiusInMeters float64) models.BoundingBox {
+	deltaLat := radiusInMeters / 1000 / (earthRadiusKm * math.Pi / 180)
+	box := models.BoundingBox{
+		MinLatitude:  math.Max(latitude-deltaLat, -90),
+		MaxLatitude:  math.Min(latitude+deltaLat, 90),
+		MinLongitude: -180,
+		MaxLongitude: 180,
+	}
+
+	// Near the poles the circle spans every longitude
+	cosLat := math.Cos(latitude * math.Pi / 180)
+	if deltaLon := deltaLat / cosLat; cosLat > 0 && deltaLon < 180 {
+		box.MinLongitude = longitude - deltaLon
+		box.MaxLongitude = longitude + deltaLon
+		if box.MinLongitude < -180 {
+			box.MinLongitude += 360
+		}
+		if box.MaxLongitude > 180 {
+			box.MaxLongitude -= 360
+		}
+	}
+
+	return box
+}
+
+// distanceBucket returns the approximate range a distance falls in.
+func distanceBucket(distanceKm float64) string {
+	for _, bucket := range distanceBuckets {
+		if distanceKm < bucket.upToKm {
+			return bucket.label
+		}
+	}
+	return distanceBuckets[len(distanceBuckets)-1].label
 }
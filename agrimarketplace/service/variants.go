@@ -0,0 +1,112 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"agrimarketplace/units"
+	"fmt"
+	"strings"
+)
+
+// validateVariants checks the variants of a product and normalizes their units to canonical spelling.
+// Invalid variants are reported under the "variants[<index>]" field.
+func validateVariants(variants []models.ProductVariant) error {
+	fields := map[string]string{}
+	seen := map[string]bool{}
+
+	for i := range variants {
+		variant := &variants[i]
+		field := fmt.Sprintf("variants[%d]", i)
+
+		variant.SKU = strings.TrimSpace(variant.SKU)
+		unit, err := units.Parse(variant.Unit)
+		switch {
+		case variant.SKU == "":
+			fields[field] = "sku is required"
+		case seen[variant.SKU]:
+			fields[field] = "sku " + variant.SKU + " is used by another variant"
+		case variant.PackSize <= 0:
+			fields[field] = "pack size must be positive"
+		case err != nil:
+			fields[field] = "unit must be one of g, kg, quintal, ml, L or piece"
+		case variant.Price < 0:
+			fields[field] = "price must not be negative"
+		case variant.Barcode != "" && !validBarcode(variant.Barcode):
+			fields[field] = "barcode is not a valid EAN-8, UPC-A or EAN-13 code"
+		default:
+			variant.Unit = string(unit)
+		}
+		seen[variant.SKU] = true
+	}
+
+	if len(fields) > 0 {
+		return &AttributeValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// validBarcode verifies the length and GS1 check digit of an EAN-8, UPC-A or EAN-13 barcode.
+func validBarcode(barcode string) bool {
+	if len(barcode) != 8 && len(barcode) != 12 && len(barcode) != 13 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < len(barcode)-1; i++ {
+		digit := int(barcode[i] - '0')
+		if digit < 0 || digit > 9 {
+			return false
+		}
+		// Weights alternate 3 and 1 starting from the digit next to the check digit
+		if (len(barcode)-1-i)%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	check := int(barcode[len(barcode)-1] - '0')
+	return check >= 0 && check <= 9 && (10-sum%10)%10 == check
+}
+
+// variantUnitPrices normalizes the price of each variant to the target unit.
+// When target is empty, each variant is priced in the standard unit of its dimension (kg, L or piece).
+func variantUnitPrices(variants []models.ProductVariant, target string) ([]models.VariantUnitPrice, error) {
+	var targetUnit units.Unit
+	if target != "" {
+		unit, err := units.Parse(target)
+		if err != nil {
+			return nil, ErrInvalidUnit
+		}
+		targetUnit = unit
+	}
+
+	prices := make([]models.VariantUnitPrice, 0, len(variants))
+	for _, variant := range variants {
+		packUnit, err := units.Parse(variant.Unit)
+		if err != nil {
+			return nil, err
+		}
+
+		per := targetUnit
+		if per == "" {
+			if per, err = packUnit.Standard(); err != nil {
+				return nil, err
+			}
+		}
+
+		unitPrice, err := units.PricePer(variant.Price, variant.PackSize, packUnit, per)
+		if err != nil {
+			if err == units.ErrIncompatibleUnits {
+				return nil, ErrInvalidUnit
+			}
+			return nil, err
+		}
+
+		prices = append(prices, models.VariantUnitPrice{
+			ProductVariant: variant,
+			UnitPrice:      roundTo(unitPrice, 2),
+			PerUnit:        string(per),
+		})
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"agrimarketplace/models"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidBarcode(t *testing.T) {
+	tests := []struct {
+		barcode string
+		want    bool
+	}{
+		{"4006381333931", true}, // EAN-13
+		{"8901058851298", true}, // EAN-13 with the Indian GS1 prefix
+		{"036000291452", true},  // UPC-A
+		{"96385074", true},      // EAN-8
+		{"4006381333932", false},
+		{"036000291453", false},
+		{"96385075", false},
+		{"40063813339a1", false},
+		{"400638133393a", false},
+		{"4006381333", false},
+		{"40063813339310", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := validBarcode(tt.barcode); got != tt.want {
+			t.Errorf("validBarcode(%q) = %v, want %v", tt.barcode, got, tt.want)
+		}
+	}
+}
+
+func TestVariantUnitPrices(t *testing.T) {
+	variants := []models.ProductVariant{
+		{SKU: "UREA-45", PackSize: 45, Unit: "kg", Price: 270},
+		{SKU: "UREA-500G", PackSize: 500, Unit: "g", Price: 10},
+	}
+
+	tests := []struct {
+		name    string
+		target  string
+		want    []float64
+		perUnit string
+		wantErr error
+	}{
+		{"standard unit", "", []float64{6, 20}, "kg", nil},
+		{"requested unit", "qtl", []float64{600, 2000}, "quintal", nil},
+		{"rounded to the paisa", "g", []float64{0.01, 0.02}, "g", nil},
+		{"other dimension", "L", nil, "", ErrInvalidUnit},
+		{"unknown unit", "bag", nil, "", ErrInvalidUnit},
+	}
+
+	for _, tt := range tests {
+		prices, err := variantUnitPrices(variants, tt.target)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: variantUnitPrices() error = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		var got []float64
+		for _, price := range prices {
+			got = append(got, price.UnitPrice)
+			if price.PerUnit != tt.perUnit {
+				t.Errorf("%s: variantUnitPrices() priced %s per %q, want per %q", tt.name, price.SKU, price.PerUnit, tt.perUnit)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: variantUnitPrices() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVariantUnitPricesInStandardUnitOfEachDimension(t *testing.T) {
+	prices, err := variantUnitPrices([]models.ProductVariant{
+		{SKU: "NEEM-250", PackSize: 250, Unit: "ml", Price: 180},
+		{SKU: "TRAP-6", PackSize: 6, Unit: "piece", Price: 300},
+	}, "")
+	if err != nil {
+		t.Fatalf("variantUnitPrices() error = %v", err)
+	}
+	if prices[0].UnitPrice != 720 || prices[0].PerUnit != "L" || prices[1].UnitPrice != 50 || prices[1].PerUnit != "piece" {
+		t.Errorf("variantUnitPrices() = %+v, want 720 per L and 50 per piece", prices)
+	}
+}
+
+func TestValidateVariants(t *testing.T) {
+	variants := []models.ProductVariant{
+		{SKU: " UREA-45 ", PackSize: 45, Unit: "KGS", Price: 270, Barcode: "8901058851298"},
+		{SKU: "UREA-45", PackSize: 10, Unit: "kg", Price: 70},
+		{SKU: "", PackSize: 10, Unit: "kg", Price: 70},
+		{SKU: "UREA-0", PackSize: 0, Unit: "kg", Price: 70},
+		{SKU: "UREA-BAG", PackSize: 1, Unit: "bag", Price: 70},
+		{SKU: "UREA-FREE", PackSize: 1, Unit: "kg", Price: -1},
+		{SKU: "UREA-EAN", PackSize: 1, Unit: "kg", Price: 70, Barcode: "8901058851299"},
+	}
+
+	err := validateVariants(variants)
+	var validationErr *AttributeValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("validateVariants() error = %v, want an AttributeValidationError", err)
+	}
+
+	want := map[string]string{
+		"variants[1]": "sku UREA-45 is used by another variant",
+		"variants[2]": "sku is required",
+		"variants[3]": "pack size must be positive",
+		"variants[4]": "unit must be one of g, kg, quintal, ml, L or piece",
+		"variants[5]": "price must not be negative",
+		"variants[6]": "barcode is not a valid EAN-8, UPC-A or EAN-13 code",
+	}
+	if !reflect.DeepEqual(validationErr.Fields, want) {
+		t.Errorf("validateVariants() fields = %v, want %v", validationErr.Fields, want)
+	}
+	if variants[0].SKU != "UREA-45" || variants[0].Unit != "kg" {
+		t.Errorf("validateVariants() left %+v, want its SKU trimmed and unit in canonical spelling", variants[0])
+	}
+}
@@ -0,0 +1,24 @@
+package sms
+
+import (
+	"log"
+)
+
+// logSender is an implementation of the SMSSender interface that writes messages to a log
+// instead of sending them, for local runs and offline testing.
+type logSender struct {
+	logger *log.Logger
+}
+
+// NewLogSender creates an SMSSender that writes each message to the logger.
+func NewLogSender(logger *log.Logger) SMSSender {
+	return &logSender{
+		logger: logger,
+	}
+}
+
+// Send writes a message to the log.
+func (s *logSender) Send(phone, text string) error {
+	s.logger.Printf("SMS to %s: %s", phone, text)
+	return nil
+}
@@ -0,0 +1,7 @@
+// Package sms provides the delivery of text messages to users' phones.
+package sms
+
+// SMSSender defines the interface for sending text messages to phone numbers in E.164 format.
+type SMSSender interface {
+	Send(phone, text string) error
+}
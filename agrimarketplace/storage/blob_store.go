@@ -0,0 +1,18 @@
+// Package storage provides blob storage for uploaded media.
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrBlobNotFound is returned when a blob does not exist.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore defines the interface for storing binary objects by key.
+// Keys are slash-separated paths such as "products/<id>/<image>.jpg".
+type BlobStore interface {
+	Put(key string, r io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
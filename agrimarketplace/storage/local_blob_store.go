@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// localBlobStore is an implementation of the BlobStore interface backed by a directory.
+type localBlobStore struct {
+	root string
+}
+
+// NewLocalBlobStore creates a BlobStore that keeps blobs as files below the root directory.
+func NewLocalBlobStore(root string) (BlobStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &localBlobStore{
+		root: root,
+	}, nil
+}
+
+// Put writes a blob, replacing any existing blob with the same key.
+// The blob is written to a temporary file first so readers never see partial content.
+func (s *localBlobStore) Put(key string, r io.Reader) error {
+	filename, err := s.filename(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// Get opens a blob for reading.
+func (s *localBlobStore) Get(key string) (io.ReadCloser, error) {
+	filename, err := s.filename(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Delete removes a blob. Deleting a missing blob is not an error.
+func (s *localBlobStore) Delete(key string) error {
+	filename, err := s.filename(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// filename maps a key to a file below the root, rejecting keys that would escape it.
+func (s *localBlobStore) filename(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned == "/" || strings.Contains(key, "..") {
+		return "", errors.New("invalid blob key")
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,128 @@
+// Package units converts quantities between the units of measure used for agri inputs.
+package units
+
+import (
+	"errors"
+	"strings"
+)
+
+// Unit is a unit of measure in its canonical spelling.
+type Unit string
+
+// Supported units of measure.
+const (
+	Gram       Unit = "g"
+	Kilogram   Unit = "kg"
+	Quintal    Unit = "quintal"
+	Millilitre Unit = "ml"
+	Litre      Unit = "L"
+	Piece      Unit = "piece"
+)
+
+// Dimension is the physical quantity a unit measures.
+type Dimension string
+
+// Dimensions of the supported units.
+const (
+	Mass   Dimension = "mass"
+	Volume Dimension = "volume"
+	Count  Dimension = "count"
+)
+
+var (
+	// ErrUnknownUnit is returned when a unit of measure is not supported.
+	ErrUnknownUnit = errors.New("unknown unit of measure")
+
+	// ErrIncompatibleUnits is returned when converting between units of different dimensions.
+	ErrIncompatibleUnits = errors.New("incompatible units of measure")
+)
+
+// unitInfo describes a unit by its dimension and its size in the dimension's smallest unit.
+type unitInfo struct {
+	dimension Dimension
+	factor    float64
+}
+
+var unitInfos = map[Unit]unitInfo{
+	Gram:       {Mass, 1},
+	Kilogram:   {Mass, 1000},
+	Quintal:    {Mass, 100000},
+	Millilitre: {Volume, 1},
+	Litre:      {Volume, 1000},
+	Piece:      {Count, 1},
+}
+
+// aliases maps the lower-case spellings accepted by Parse to their unit.
+var aliases = map[string]Unit{
+	"g": Gram, "gm": Gram, "gms": Gram, "gram": Gram, "grams": Gram,
+	"kg": Kilogram, "kgs": Kilogram, "kilogram": Kilogram, "kilograms": Kilogram,
+	"q": Quintal, "qtl": Quintal, "quintal": Quintal, "quintals": Quintal,
+	"ml": Millilitre, "millilitre": Millilitre, "milliliter": Millilitre,
+	"l": Litre, "ltr": Litre, "litre": Litre, "liter": Litre, "litres": Litre, "liters": Litre,
+	"piece": Piece, "pieces": Piece, "pc": Piece, "pcs": Piece, "nos": Piece,
+}
+
+// standardUnits is the unit in which prices of each dimension are compared by default.
+var standardUnits = map[Dimension]Unit{
+	Mass:   Kilogram,
+	Volume: Litre,
+	Count:  Piece,
+}
+
+// Parse returns the unit matching a spelling such as "Kg", "ltr" or "qtl".
+func Parse(value string) (Unit, error) {
+	unit, ok := aliases[strings.ToLower(strings.TrimSpace(value))]
+	if !ok {
+		return "", ErrUnknownUnit
+	}
+	return unit, nil
+}
+
+// Dimension returns the dimension measured by the unit.
+func (u Unit) Dimension() (Dimension, error) {
+	info, ok := unitInfos[u]
+	if !ok {
+		return "", ErrUnknownUnit
+	}
+	return info.dimension, nil
+}
+
+// Standard returns the unit in which prices of the given unit's dimension are usually quoted.
+func (u Unit) Standard() (Unit, error) {
+	dimension, err := u.Dimension()
+	if err != nil {
+		return "", err
+	}
+	return standardUnits[dimension], nil
+}
+
+// Convert converts a quantity from one unit to another of the same dimension.
+func Convert(quantity float64, from, to Unit) (float64, error) {
+	fromInfo, ok := unitInfos[from]
+	if !ok {
+		return 0, ErrUnknownUnit
+	}
+	toInfo, ok := unitInfos[to]
+	if !ok {
+		return 0, ErrUnknownUnit
+	}
+	if fromInfo.dimension != toInfo.dimension {
+		return 0, ErrIncompatibleUnits
+	}
+
+	return quantity * fromInfo.factor / toInfo.factor, nil
+}
+
+// PricePer returns the price of one target unit for a pack of the given size and unit.
+// For example a 45 kg bag at 270 costs 6 per kg.
+func PricePer(price, packSize float64, packUnit, target Unit) (float64, error) {
+	if packSize <= 0 {
+		return 0, errors.New("pack size must be positive")
+	}
+
+	quantity, err := Convert(packSize, packUnit, target)
+	if err != nil {
+		return 0, err
+	}
+	return price / quantity, nil
+}
@@ -0,0 +1,114 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Unit
+		wantErr error
+	}{
+		{"kg", Kilogram, nil},
+		{" Kg ", Kilogram, nil},
+		{"KGS", Kilogram, nil},
+		{"gm", Gram, nil},
+		{"qtl", Quintal, nil},
+		{"Q", Quintal, nil},
+		{"ml", Millilitre, nil},
+		{"L", Litre, nil},
+		{"ltr", Litre, nil},
+		{"liters", Litre, nil},
+		{"nos", Piece, nil},
+		{"", "", ErrUnknownUnit},
+		{"tonne", "", ErrUnknownUnit},
+		{"k g", "", ErrUnknownUnit},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.value)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("Parse(%q) = %q, %v, want %q, %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestStandard(t *testing.T) {
+	tests := []struct {
+		unit    Unit
+		want    Unit
+		wantErr error
+	}{
+		{Gram, Kilogram, nil},
+		{Quintal, Kilogram, nil},
+		{Millilitre, Litre, nil},
+		{Piece, Piece, nil},
+		{Unit("kg "), "", ErrUnknownUnit}, // Units must be parsed to their canonical spelling first
+	}
+
+	for _, tt := range tests {
+		got, err := tt.unit.Standard()
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("%q.Standard() = %q, %v, want %q, %v", tt.unit, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		quantity float64
+		from, to Unit
+		want     float64
+		wantErr  error
+	}{
+		{1, Kilogram, Gram, 1000, nil},
+		{250, Gram, Kilogram, 0.25, nil},
+		{2.5, Quintal, Kilogram, 250, nil},
+		{500, Kilogram, Quintal, 5, nil},
+		{750, Millilitre, Litre, 0.75, nil},
+		{12, Piece, Piece, 12, nil},
+		{0, Litre, Millilitre, 0, nil},
+		{1, Kilogram, Litre, 0, ErrIncompatibleUnits},
+		{1, Piece, Gram, 0, ErrIncompatibleUnits},
+		{1, Unit("tonne"), Kilogram, 0, ErrUnknownUnit},
+		{1, Kilogram, Unit("tonne"), 0, ErrUnknownUnit},
+	}
+
+	for _, tt := range tests {
+		got, err := Convert(tt.quantity, tt.from, tt.to)
+		if !errors.Is(err, tt.wantErr) || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Convert(%v, %q, %q) = %v, %v, want %v, %v", tt.quantity, tt.from, tt.to, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestPricePer(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    float64
+		packSize float64
+		packUnit Unit
+		target   Unit
+		want     float64
+		wantErr  bool
+	}{
+		{"45 kg bag per kg", 270, 45, Kilogram, Kilogram, 6, false},
+		{"45 kg bag per quintal", 270, 45, Kilogram, Quintal, 600, false},
+		{"500 g pack per kg", 60, 500, Gram, Kilogram, 120, false},
+		{"250 ml bottle per litre", 180, 250, Millilitre, Litre, 720, false},
+		{"free sample", 0, 100, Gram, Kilogram, 0, false},
+		{"mass priced per litre", 270, 45, Kilogram, Litre, 0, true},
+		{"empty pack", 270, 0, Kilogram, Kilogram, 0, true},
+		{"negative pack", 270, -1, Kilogram, Kilogram, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := PricePer(tt.price, tt.packSize, tt.packUnit, tt.target)
+		if (err != nil) != tt.wantErr || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: PricePer() = %v, %v, want %v, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// number is the set of field types the numeric rules apply to.
+type number interface {
+	~int | ~int64 | ~float64
+}
+
+// Required rejects empty and blank strings.
+func Required() Rule[string] {
+	return func(value string) string {
+		if strings.TrimSpace(value) == "" {
+			return "is required"
+		}
+		return ""
+	}
+}
+
+// NotZero rejects the zero value of a field, such as a missing ID.
+func NotZero[V comparable]() Rule[V] {
+	return func(value V) string {
+		var zero V
+		if value == zero {
+			return "is required"
+		}
+		return ""
+	}
+}
+
+// MaxLength rejects strings longer than n characters.
+func MaxLength(n int) Rule[string] {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Sprintf("must be at most %d characters", n)
+		}
+		return ""
+	}
+}
+
+// Pattern rejects non-empty strings that do not match a regular expression.
+func Pattern(pattern *regexp.Regexp, message string) Rule[string] {
+	return func(value string) string {
+		if value != "" && !pattern.MatchString(value) {
+			return message
+		}
+		return ""
+	}
+}
+
+// Email rejects non-empty strings that are not a bare email address.
+func Email() Rule[string] {
+	return func(value string) string {
+		if value == "" {
+			return ""
+		}
+		address, err := mail.ParseAddress(value)
+		if err != nil || address.Address != value || address.Name != "" {
+			return "must be a valid email address"
+		}
+		return ""
+	}
+}
+
+// OneOf rejects non-empty strings other than the allowed values.
+func OneOf(values ...string) Rule[string] {
+	return func(value string) string {
+		if value == "" {
+			return ""
+		}
+		for _, allowed := range values {
+			if value == allowed {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(values, ", ")
+	}
+}
+
+// Min rejects numbers below min.
+func Min[N number](min N) Rule[N] {
+	return func(value N) string {
+		if value < min {
+			return fmt.Sprintf("must be at least %v", min)
+		}
+		return ""
+	}
+}
+
+// Range rejects numbers outside [min, max].
+func Range[N number](min, max N) Rule[N] {
+	return func(value N) string {
+		if value < min || value > max {
+			return fmt.Sprintf("must be between %v and %v", min, max)
+		}
+		return ""
+	}
+}
@@ -0,0 +1,102 @@
+// Package validation checks entities against field rules declared once per entity type.
+//
+// A Schema lists the fields of an entity with the rules each must satisfy:
+//
+//	var shopSchema = validation.New(
+//		validation.Field("shop_name", func(s *models.Shop) string { return s.ShopName }, validation.Required(), validation.MaxLength(120)),
+//		validation.Field("latitude", func(s *models.Shop) float64 { return s.Latitude }, validation.Range(-90, 90)),
+//	)
+//
+// Validating an entity checks every field and reports all the failing fields together.
+package validation
+
+import (
+	"sort"
+	"strings"
+)
+
+// Error is returned when an entity has invalid fields. Fields maps each offending field
+// to the reason it was rejected.
+type Error struct {
+	Fields map[string]string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		messages = append(messages, name+": "+e.Fields[name])
+	}
+	return "invalid fields: " + strings.Join(messages, "; ")
+}
+
+// Errors collects the field errors found while validating an entity.
+type Errors map[string]string
+
+// Add records why a field is invalid, keeping the first reason given for each field.
+func (e Errors) Add(field, message string) {
+	if _, ok := e[field]; !ok {
+		e[field] = message
+	}
+}
+
+// Err returns the collected field errors as an *Error, or nil when there are none.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return &Error{Fields: e}
+}
+
+// Rule checks a field value and returns why it is invalid, or an empty string when it is valid.
+type Rule[V any] func(value V) string
+
+// FieldRules checks one field of an entity and records its errors.
+type FieldRules[T any] func(entity *T, errs Errors)
+
+// Field declares a field of an entity, read by get, and the rules its value must satisfy.
+// Rules are applied in order and only the first failing rule is reported.
+func Field[T, V any](name string, get func(entity *T) V, rules ...Rule[V]) FieldRules[T] {
+	return func(entity *T, errs Errors) {
+		value := get(entity)
+		for _, rule := range rules {
+			if message := rule(value); message != "" {
+				errs.Add(name, message)
+				return
+			}
+		}
+	}
+}
+
+// Schema holds the field rules of an entity type.
+type Schema[T any] struct {
+	fields []FieldRules[T]
+}
+
+// New creates a schema from the rules of each field.
+func New[T any](fields ...FieldRules[T]) *Schema[T] {
+	return &Schema[T]{
+		fields: fields,
+	}
+}
+
+// Check validates every field of an entity, adding the failures to errs.
+// It lets callers combine the schema with checks of their own before reporting.
+func (s *Schema[T]) Check(entity *T, errs Errors) {
+	for _, field := range s.fields {
+		field(entity, errs)
+	}
+}
+
+// Validate validates every field of an entity and returns an *Error listing all the invalid fields.
+func (s *Schema[T]) Validate(entity *T) error {
+	errs := Errors{}
+	s.Check(entity, errs)
+	return errs.Err()
+}
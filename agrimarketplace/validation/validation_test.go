@@ -0,0 +1,141 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	pinCode := Pattern(regexp.MustCompile(`^[1-9][0-9]{5}$`), "must be a six-digit PIN code")
+
+	tests := []struct {
+		name  string
+		check func() string
+		want  string
+	}{
+		{"required value", func() string { return Required()("urea") }, ""},
+		{"required empty", func() string { return Required()("") }, "is required"},
+		{"required blank", func() string { return Required()(" \t") }, "is required"},
+		{"not zero value", func() string { return NotZero[int]()(7) }, ""},
+		{"not zero zero", func() string { return NotZero[int]()(0) }, "is required"},
+		{"max length at limit", func() string { return MaxLength(5)("abcde") }, ""},
+		{"max length over limit", func() string { return MaxLength(5)("abcdef") }, "must be at most 5 characters"},
+		// Lengths are counted in characters, not bytes
+		{"max length of Devanagari", func() string { return MaxLength(5)("गेहूं") }, ""},
+		{"pattern match", func() string { return pinCode("560001") }, ""},
+		{"pattern mismatch", func() string { return pinCode("056000") }, "must be a six-digit PIN code"},
+		{"pattern empty", func() string { return pinCode("") }, ""},
+		{"email", func() string { return Email()("ravi@example.com") }, ""},
+		{"email empty", func() string { return Email()("") }, ""},
+		{"email invalid", func() string { return Email()("ravi@") }, "must be a valid email address"},
+		{"email with name", func() string { return Email()("Ravi <ravi@example.com>") }, "must be a valid email address"},
+		{"email with spaces", func() string { return Email()(" ravi@example.com") }, "must be a valid email address"},
+		{"one of allowed", func() string { return OneOf("red", "black")("black") }, ""},
+		{"one of empty", func() string { return OneOf("red", "black")("") }, ""},
+		{"one of other", func() string { return OneOf("red", "black")("Black") }, "must be one of red, black"},
+		{"min at limit", func() string { return Min(0.0)(0) }, ""},
+		{"min below", func() string { return Min(0)(-1) }, "must be at least 0"},
+		{"range inside", func() string { return Range(-90.0, 90.0)(12.97) }, ""},
+		{"range at bound", func() string { return Range(-90.0, 90.0)(-90) }, ""},
+		{"range outside", func() string { return Range(-90.0, 90.0)(90.5) }, "must be between -90 and 90"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.check(); got != tt.want {
+			t.Errorf("%s: rule returned %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+type testShop struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+}
+
+var testShopSchema = New(
+	Field("name", func(s *testShop) string { return s.Name }, Required(), MaxLength(10)),
+	Field("latitude", func(s *testShop) float64 { return s.Latitude }, Range(-90.0, 90.0)),
+	Field("longitude", func(s *testShop) float64 { return s.Longitude }, Range(-180.0, 180.0)),
+)
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		shop testShop
+		want map[string]string
+	}{
+		{"valid", testShop{Name: "Krishi", Latitude: 12.97, Longitude: 77.59}, nil},
+		// Only the first failing rule of a field is reported
+		{"blank name", testShop{Name: " ", Latitude: 12.97, Longitude: 77.59}, map[string]string{"name": "is required"}},
+		{
+			name: "every invalid field",
+			shop: testShop{Name: "Krishi Kendra", Latitude: 100, Longitude: -200},
+			want: map[string]string{
+				"name":      "must be at most 10 characters",
+				"latitude":  "must be between -90 and 90",
+				"longitude": "must be between -180 and 180",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		err := testShopSchema.Validate(&tt.shop)
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("%s: Validate() error = %v, want nil", tt.name, err)
+			}
+			continue
+		}
+		var fieldErr *Error
+		if !errors.As(err, &fieldErr) {
+			t.Errorf("%s: Validate() error = %v, want an *Error", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(fieldErr.Fields, tt.want) {
+			t.Errorf("%s: Validate() fields = %v, want %v", tt.name, fieldErr.Fields, tt.want)
+		}
+	}
+}
+
+func TestSchemaCheckKeepsCallerErrors(t *testing.T) {
+	errs := Errors{}
+	errs.Add("name", "is already taken")
+	testShopSchema.Check(&testShop{Name: "", Latitude: 100}, errs)
+
+	want := Errors{"name": "is already taken", "latitude": "must be between -90 and 90"}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("Check() errors = %v, want %v", errs, want)
+	}
+	if err := (Errors{}).Err(); err != nil {
+		t.Errorf("Err() without errors = %v, want nil", err)
+	}
+}
+
+func TestErrorMessageAndFieldMap(t *testing.T) {
+	err := &Error{Fields: map[string]string{"longitude": "must be between -180 and 180", "latitude": "must be between -90 and 90"}}
+
+	// Fields are listed in a stable order
+	want := "invalid fields: latitude: must be between -90 and 90; longitude: must be between -180 and 180"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	// The error is the body of 422 responses, mapping each field to its reason
+	data, jsonErr := json.Marshal(err)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	var body struct {
+		Fields map[string]string
+	}
+	if jsonErr := json.Unmarshal(data, &body); jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if !reflect.DeepEqual(body.Fields, err.Fields) {
+		t.Errorf("json.Marshal() = %s, want the fields under \"Fields\"", data)
+	}
+}